@@ -0,0 +1,99 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"io"
+)
+
+// NewAppendWriter 读取rws中的现有存档直到存档结束标记，并返回一个从该标记处开始写入的Writer，
+// 类似于"tar -r"。调用Writer.Close会写入新的存档结束标记。
+//
+// 仅支持未压缩的存档。rws的当前偏移量被忽略；存档必须从偏移量零开始。
+// 如果rws为空，则返回的Writer写入一个新存档。
+// 请注意，存档中任何全局PAX标头（TypeXGlobalHeader）的记录在读取时也会应用于追加的条目。
+func NewAppendWriter(rws io.ReadWriteSeeker) (*Writer, error) { // 注：#
+	if _, err := rws.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var end int64 // 最后一个条目（包括填充）之后的偏移量
+	tr := NewReader(rws)
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Reader不进行缓冲，因此当前偏移量就是条目数据的开头。
+		pos, err := rws.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		end = pos + tr.curr.PhysicalRemaining() + tr.pad
+	}
+	if _, err := rws.Seek(end, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return NewWriter(rws), nil
+}
+
+// Rewrite 将r中的存档复制到w，省略drop返回true的条目，最后写入存档结束标记。
+//
+// 保留的条目按原样复制：它们的标头块（包括PAX和GNU元标头）和数据块（包括稀疏映射和填充）
+// 都不会被重新编码，因此输出中的条目与输入中的逐字节相同。
+// 全局PAX标头（TypeXGlobalHeader）也会传递给drop。
+// 传递给drop的Header不得被修改以期影响输出。
+func Rewrite(w io.Writer, r io.Reader, drop func(*Header) bool) error { // 注：#
+	rec := &recordReader{r: r}
+	tr := NewReader(rec)
+	for {
+		rec.buf = rec.buf[:0]
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// 绕过tr直接处理剩余的原始数据，使下一次调用Next时无需丢弃任何内容。
+		n := tr.curr.PhysicalRemaining() + tr.pad
+		tr.curr, tr.pad = &regFileReader{r: rec, nb: 0}, 0
+		if drop(hdr) {
+			if err := discard(r, n); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := w.Write(rec.buf); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, n); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	_, err := w.Write(zeroBlock[:])
+	if err == nil {
+		_, err = w.Write(zeroBlock[:])
+	}
+	return err
+}
+
+// recordReader 是记录所有读取的字节的io.Reader。
+type recordReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (rr *recordReader) Read(b []byte) (int, error) { // 注：#
+	n, err := rr.r.Read(b)
+	rr.buf = append(rr.buf, b[:n]...)
+	return n, err
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readNames returns the names and contents of all entries in the archive.
+func readNames(t *testing.T, r io.Reader) (names, data []string) {
+	t.Helper()
+	tr := NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names, data
+		}
+		if err != nil {
+			t.Fatalf("Next(): unexpected error: %v", err)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Read(): unexpected error: %v", err)
+		}
+		names = append(names, hdr.Name)
+		data = append(data, string(b))
+	}
+}
+
+func TestNewAppendWriter(t *testing.T) {
+	f, err := ioutil.TempFile("", "tar-append")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	write := func(tw *Writer, name, data string) {
+		if err := tw.WriteHeader(&Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Appending to an empty file creates a new archive.
+	tw, err := NewAppendWriter(f)
+	if err != nil {
+		t.Fatalf("NewAppendWriter(): unexpected error: %v", err)
+	}
+	write(tw, "a.txt", "alpha")
+	write(tw, strings.Repeat("long/", 30)+"b.txt", "bravo")
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Pad to a full record like tar(1) does.
+	if _, err := f.Write(make([]byte, 10*blockSize)); err != nil {
+		t.Fatal(err)
+	}
+
+	tw, err = NewAppendWriter(f)
+	if err != nil {
+		t.Fatalf("NewAppendWriter(): unexpected error: %v", err)
+	}
+	write(tw, "c.txt", "charlie")
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	names, data := readNames(t, f)
+	wantNames := []string{"a.txt", strings.Repeat("long/", 30) + "b.txt", "c.txt"}
+	wantData := []string{"alpha", "bravo", "charlie"}
+	if strings.Join(names, ",") != strings.Join(wantNames, ",") || strings.Join(data, ",") != strings.Join(wantData, ",") {
+		t.Errorf("after append: got %q %q, want %q %q", names, data, wantNames, wantData)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	for _, file := range []string{"testdata/pax-global.tar", "testdata/gnu-long.tar", "testdata/gnu-sparse.tar", "testdata/pax-sparse-1.0.tar"} {
+		t.Run(file, func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantNames, wantData := readNames(t, bytes.NewReader(data))
+
+			// Dropping nothing reproduces every entry byte for byte.
+			var buf bytes.Buffer
+			if err := Rewrite(&buf, bytes.NewReader(data), func(*Header) bool { return false }); err != nil {
+				t.Fatalf("Rewrite(): unexpected error: %v", err)
+			}
+			if n := buf.Len() - 2*blockSize; n > len(data) || !bytes.Equal(buf.Bytes()[:n], data[:n]) {
+				t.Errorf("Rewrite() without drops changed the archive")
+			}
+
+			// Dropping the first entry leaves the others intact.
+			buf.Reset()
+			first := true
+			drop := func(*Header) bool { d := first; first = false; return d }
+			if err := Rewrite(&buf, bytes.NewReader(data), drop); err != nil {
+				t.Fatalf("Rewrite(): unexpected error: %v", err)
+			}
+			names, got := readNames(t, &buf)
+			if strings.Join(names, ",") != strings.Join(wantNames[1:], ",") || strings.Join(got, ",") != strings.Join(wantData[1:], ",") {
+				t.Errorf("Rewrite() dropping first: got %q, want %q", names, wantNames[1:])
+			}
+		})
+	}
+}
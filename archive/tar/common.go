@@ -11,6 +11,7 @@ package tar
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path"
@@ -31,12 +32,31 @@ var (
 	errWriteHole       = errors.New("archive/tar: write non-NUL byte in sparse hole")        // 错误："在稀疏孔中写入非NUL字节"
 )
 
-type headerError []string
+// headerError 记录编码或解码标头时发现的一个或多个问题。
+// Writer使用encodeHeaderError报告无法编码的Header；
+// Reader使用decodeHeaderError报告格式错误的输入，此时它满足errors.Is(err, ErrHeader)。
+type headerError struct {
+	decode   bool     // 问题是否在解码（读取）时发现
+	problems []string // 问题描述，空字符串被忽略
+}
+
+// encodeHeaderError 返回描述无法编码的Header的headerError。
+func encodeHeaderError(problems ...string) headerError {
+	return headerError{problems: problems}
+}
+
+// decodeHeaderError 返回描述格式错误的输入的headerError。
+func decodeHeaderError(problem string) headerError {
+	return headerError{decode: true, problems: []string{problem}}
+}
 
 func (he headerError) Error() string { // 注：输出错误he
-	const prefix = "archive/tar: cannot encode header" // 注：无法编码标头
+	prefix := "archive/tar: cannot encode header" // 注：无法编码标头
+	if he.decode {
+		prefix = ErrHeader.Error() // 注："archive/tar: invalid tar header"
+	}
 	var ss []string
-	for _, s := range he { // 注：遍历he，将he转为[]string
+	for _, s := range he.problems { // 注：遍历he.problems，忽略空字符串
 		if s != "" {
 			ss = append(ss, s)
 		}
@@ -47,6 +67,9 @@ func (he headerError) Error() string { // 注：输出错误he
 	return fmt.Sprintf("%s: %v", prefix, strings.Join(ss, "; and ")) // 注：输出"archive/tar: cannot encode header: error1; and error2"
 }
 
+// Is 报告target是否为ErrHeader，只有解码时产生的headerError满足。
+func (he headerError) Is(target error) bool { return he.decode && target == ErrHeader }
+
 // Header.Typeflag的类型标志。
 const (
 	// 类型'0'表示常规文件。
@@ -69,7 +92,7 @@ const (
 	TypeXHeader = 'x'
 
 	// PAX格式使用类型'g'来存储与所有后续文件相关的键值记录。
-	// 此包仅支持解析和组合此类标头，但当前不支持跨文件持久化全局状态。
+	// Reader会返回此类标头，并将其记录合并到之后的每个文件中（文件自身的记录优先）。
 	TypeXGlobalHeader = 'g'
 
 	// 类型'S'表示GNU格式的稀疏文件。
@@ -151,6 +174,16 @@ type Header struct {
 	Devmajor int64 // 主设备号（对TypeChar或TypeBlock有效）
 	Devminor int64 // 次设备号（对TypeChar或TypeBlock有效）
 
+	// SparseHoles 表示稀疏文件中的孔序列。
+	//
+	// 如果len(SparseHoles) > 0或Typeflag为TypeGNUSparse，则该文件是稀疏的。
+	// 孔必须按升序排列，不能重叠，并且任何孔的结束偏移量都不能超过Header.Size。
+	// 稀疏文件需要PAX（写为GNU PAX 1.0稀疏格式）或GNU（仅限TypeGNUSparse）格式。
+	//
+	// Reader.Next根据存档中的稀疏映射设置此字段。
+	// 打包磁盘上的文件时，可以使用Header.DetectSparseHoles填充它。
+	SparseHoles []SparseEntry
+
 	// Xattrs 将扩展属性作为PAX记录存储在命名空间"SCHILY.xattr"下。
 	//
 	// 以下在语义上是等效的：
@@ -181,10 +214,13 @@ type Header struct {
 	Format Format
 }
 
-// sparseEntry 表示文件中Offset处的Length长度的片段。
-type sparseEntry struct{ Offset, Length int64 }
+// SparseEntry 表示文件中Offset处的Length长度的片段。
+type SparseEntry struct{ Offset, Length int64 }
+
+func (s SparseEntry) endOffset() int64 { return s.Offset + s.Length } // 注：获取s偏移结束的位置
 
-func (s sparseEntry) endOffset() int64 { return s.Offset + s.Length } // 注：获取s偏移结束的位置
+// sparseEntry 是SparseEntry在包内的别名，下面的稀疏映射逻辑都使用这个名称。
+type sparseEntry = SparseEntry
 
 // 稀疏文件可以表示为sparseDatas或sparseHoles。
 // 只要知道总大小，它们就相等，并且可以将其转换为另一种形式并返回。
@@ -415,20 +451,20 @@ func (h Header) allowedFormats() (format Format, paxHdrs map[string]string, err
 	case TypeReg, TypeChar, TypeBlock, TypeFifo, TypeGNUSparse:
 		// Exclude TypeLink and TypeSymlink, since they may reference directories.
 		if strings.HasSuffix(h.Name, "/") {
-			return FormatUnknown, nil, headerError{"filename may not have trailing slash"}
+			return FormatUnknown, nil, encodeHeaderError("filename may not have trailing slash")
 		}
 	case TypeXHeader, TypeGNULongName, TypeGNULongLink:
-		return FormatUnknown, nil, headerError{"cannot manually encode TypeXHeader, TypeGNULongName, or TypeGNULongLink headers"}
+		return FormatUnknown, nil, encodeHeaderError("cannot manually encode TypeXHeader, TypeGNULongName, or TypeGNULongLink headers")
 	case TypeXGlobalHeader:
 		h2 := Header{Name: h.Name, Typeflag: h.Typeflag, Xattrs: h.Xattrs, PAXRecords: h.PAXRecords, Format: h.Format}
 		if !reflect.DeepEqual(h, h2) {
-			return FormatUnknown, nil, headerError{"only PAXRecords should be set for TypeXGlobalHeader"}
+			return FormatUnknown, nil, encodeHeaderError("only PAXRecords should be set for TypeXGlobalHeader")
 		}
 		whyOnlyPAX = "only PAX supports TypeXGlobalHeader"
 		format.mayOnlyBe(FormatPAX)
 	}
 	if !isHeaderOnlyType(h.Typeflag) && h.Size < 0 {
-		return FormatUnknown, nil, headerError{"negative size on header-only type"}
+		return FormatUnknown, nil, encodeHeaderError("negative size on header-only type")
 	}
 
 	// Check PAX records.
@@ -455,32 +491,28 @@ func (h Header) allowedFormats() (format Format, paxHdrs map[string]string, err
 	}
 	for k, v := range paxHdrs {
 		if !validPAXRecord(k, v) {
-			return FormatUnknown, nil, headerError{fmt.Sprintf("invalid PAX record: %q", k+" = "+v)}
+			return FormatUnknown, nil, encodeHeaderError(fmt.Sprintf("invalid PAX record: %q", k+" = "+v))
 		}
 	}
 
-	// TODO(dsnet): Re-enable this when adding sparse support.
-	// See https://golang.org/issue/22735
-	/*
-		// Check sparse files.
-		if len(h.SparseHoles) > 0 || h.Typeflag == TypeGNUSparse {
-			if isHeaderOnlyType(h.Typeflag) {
-				return FormatUnknown, nil, headerError{"header-only type cannot be sparse"}
-			}
-			if !validateSparseEntries(h.SparseHoles, h.Size) {
-				return FormatUnknown, nil, headerError{"invalid sparse holes"}
-			}
-			if h.Typeflag == TypeGNUSparse {
-				whyOnlyGNU = "only GNU supports TypeGNUSparse"
-				format.mayOnlyBe(FormatGNU)
-			} else {
-				whyNoGNU = "GNU supports sparse files only with TypeGNUSparse"
-				format.mustNotBe(FormatGNU)
-			}
-			whyNoUSTAR = "USTAR does not support sparse files"
-			format.mustNotBe(FormatUSTAR)
+	// Check sparse files.
+	if len(h.SparseHoles) > 0 || h.Typeflag == TypeGNUSparse {
+		if isHeaderOnlyType(h.Typeflag) {
+			return FormatUnknown, nil, encodeHeaderError("header-only type cannot be sparse")
 		}
-	*/
+		if !validateSparseEntries(h.SparseHoles, h.Size) {
+			return FormatUnknown, nil, encodeHeaderError("invalid sparse holes")
+		}
+		if h.Typeflag == TypeGNUSparse {
+			whyOnlyGNU = "only GNU supports TypeGNUSparse"
+			format.mayOnlyBe(FormatGNU)
+		} else {
+			whyNoGNU = "GNU supports sparse files only with TypeGNUSparse"
+			format.mustNotBe(FormatGNU)
+		}
+		whyNoUSTAR = "USTAR does not support sparse files"
+		format.mustNotBe(FormatUSTAR)
+	}
 
 	// Check desired format.
 	if wantFormat := h.Format; wantFormat != FormatUnknown {
@@ -492,13 +524,13 @@ func (h Header) allowedFormats() (format Format, paxHdrs map[string]string, err
 	if format == FormatUnknown {
 		switch h.Format {
 		case FormatUSTAR:
-			err = headerError{"Format specifies USTAR", whyNoUSTAR, whyOnlyPAX, whyOnlyGNU}
+			err = encodeHeaderError("Format specifies USTAR", whyNoUSTAR, whyOnlyPAX, whyOnlyGNU)
 		case FormatPAX:
-			err = headerError{"Format specifies PAX", whyNoPAX, whyOnlyGNU}
+			err = encodeHeaderError("Format specifies PAX", whyNoPAX, whyOnlyGNU)
 		case FormatGNU:
-			err = headerError{"Format specifies GNU", whyNoGNU, whyOnlyPAX}
+			err = encodeHeaderError("Format specifies GNU", whyNoGNU, whyOnlyPAX)
 		default:
-			err = headerError{whyNoUSTAR, whyNoPAX, whyNoGNU, whyOnlyPAX, whyOnlyGNU}
+			err = encodeHeaderError(whyNoUSTAR, whyNoPAX, whyNoGNU, whyOnlyPAX, whyOnlyGNU)
 		}
 	}
 	return format, paxHdrs, err
@@ -580,6 +612,70 @@ func (fi headerFileInfo) Mode() (mode os.FileMode) {
 // sysStat, if non-nil, populates h from system-dependent fields of fi.
 var sysStat func(fi os.FileInfo, h *Header) error
 
+// sysSparseDetect 如果不为nil，则在f中查找孔并以sparseHoles形式返回它们。
+// 如果操作系统或文件系统不支持查找孔，它返回(nil, nil)。
+var sysSparseDetect func(f *os.File) (sparseHoles, error)
+
+// DetectSparseHoles 在f中搜索孔以填充SparseHoles（在受支持的操作系统和文件系统上）。
+// 文件偏移量将被重置为零。
+//
+// 打包稀疏文件时，应在使用Writer.WriteHeader写入标头之前调用DetectSparseHoles，
+// 然后使用Writer.ReadFrom复制文件内容，以便跳过孔而不是读取它们。
+func (h *Header) DetectSparseHoles(f *os.File) (err error) { // 注：#
+	defer func() {
+		if _, serr := f.Seek(0, io.SeekStart); err == nil {
+			err = serr
+		}
+	}()
+
+	h.SparseHoles = nil
+	switch h.Typeflag {
+	case TypeReg, TypeGNUSparse:
+		// 只有常规文件可以是稀疏的。
+		// 如果文件为空，则完全跳过。
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if fi.Size() == 0 {
+			return nil
+		}
+		if sysSparseDetect != nil {
+			sph, err := sysSparseDetect(f)
+			h.SparseHoles = sph
+			return err
+		}
+	}
+	return nil
+}
+
+// PunchSparseHoles 销毁f的内容，并根据SparseHoles准备一个带有孔的稀疏文件（在受支持的操作系统和文件系统上）。
+// 文件偏移量将被重置为零。
+//
+// 提取稀疏文件时，应在使用Reader.WriteTo填充文件内容之前调用PunchSparseHoles，
+// 这样孔会保留在磁盘上，而不是被写入零。
+func (h *Header) PunchSparseHoles(f *os.File) (err error) { // 注：#
+	defer func() {
+		if _, serr := f.Seek(0, io.SeekStart); err == nil {
+			err = serr
+		}
+	}()
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if len(h.SparseHoles) == 0 {
+		return nil // 对于非稀疏文件，除了截断之外什么也不做
+	}
+	if !validateSparseEntries(h.SparseHoles, h.Size) {
+		return errors.New("archive/tar: invalid sparse holes")
+	}
+
+	// 在支持稀疏文件的文件系统上，扩展文件大小会留下一个覆盖整个文件的孔；
+	// 之后Reader.WriteTo只写入数据片段。
+	return f.Truncate(h.Size)
+}
+
 const (
 	// Mode constants from the USTAR spec:
 	// See http://pubs.opengroup.org/onlinepubs/9699919799/utilities/pax.html#tag_20_92_13_06
@@ -670,6 +766,9 @@ func FileInfoHeader(fi os.FileInfo, link string) (*Header, error) {
 			h.Size = 0
 			h.Linkname = sys.Linkname
 		}
+		if sys.SparseHoles != nil {
+			h.SparseHoles = append([]SparseEntry{}, sys.SparseHoles...)
+		}
 		if sys.PAXRecords != nil {
 			h.PAXRecords = make(map[string]string)
 			for k, v := range sys.PAXRecords {
@@ -0,0 +1,555 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInsecurePath 表示存档中的条目会在提取目录之外创建或修改文件，
+// 例如名称包含".."、是绝对路径，或符号链接指向提取目录之外。
+var ErrInsecurePath = errors.New("archive/tar: insecure file path") // 错误："不安全的文件路径"
+
+// ExtractOptions 控制ExtractTo如何在磁盘上重建文件的元数据。
+// 零值还原文件内容、扩展属性和Header.Mode中的权限位（受umask约束）。
+type ExtractOptions struct {
+	// PreserveOwner 将文件的所有者和组设置为Header.Uid和Header.Gid。
+	// 这通常需要足够的权限（例如root）。
+	PreserveOwner bool
+
+	// PreserveMode 精确地应用Header.Mode，包括setuid、setgid和sticky位，且不受umask约束。
+	// 否则文件使用Header.Mode的权限位创建，并受umask约束。
+	PreserveMode bool
+
+	// PreserveTimes 将文件的修改时间和访问时间设置为Header.ModTime和Header.AccessTime。
+	// 如果Header.AccessTime为零，则使用Header.ModTime。
+	// 符号链接本身的时间不会被修改。
+	PreserveTimes bool
+
+	// SkipXattrs 不还原扩展属性（包括文件功能和POSIX ACL）。
+	// 默认情况下，它们由Header.RestoreXattrs还原，这在不支持它们的系统上会导致错误。
+	SkipXattrs bool
+}
+
+// ExtractTo 将tr中剩余的所有条目提取到目录dir中，dir必须已存在。
+//
+// 任何条目都不会在dir之外创建或写入文件：名称为绝对路径或包含逃逸dir的".."的条目、
+// 目标（在解析已存在的符号链接后）位于dir之外的符号链接和硬链接、源为符号链接的硬链接，
+// 以及父目录通过符号链接解析到dir之外的条目，都会导致返回包装ErrInsecurePath的*os.PathError，
+// 且不修改dir之外的任何内容。元数据的应用不会跟随符号链接。
+// 由于后续条目可能改变先前符号链接的解析结果，所有条目提取完毕后会再次检查提取的符号链接，
+// 并删除指向dir之外的符号链接。
+//
+// 常规文件和稀疏文件、目录、符号链接、硬链接以及（在受支持的系统上）设备文件和FIFO都会被提取；
+// 全局PAX标头被忽略，其他类型的条目会导致错误。
+// 已存在的非目录文件会被替换而不是被写穿。
+// 目录的元数据在所有条目提取完毕后才应用，以免后续条目改变其修改时间。
+func ExtractTo(dir string, tr *Reader, opts *ExtractOptions) error { // 注：#
+	root, err := filepath.Abs(dir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return err
+	}
+	x := &extractor{root: root}
+	if opts != nil {
+		x.opts = *opts
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := x.extract(hdr, tr); err != nil {
+			return err
+		}
+	}
+	if err := x.finishDirs(); err != nil {
+		return err
+	}
+	return x.checkLinks()
+}
+
+// extractor 保存ExtractTo在条目之间共享的状态。
+type extractor struct {
+	root  string // 提取目录，已解析符号链接的绝对路径
+	opts  ExtractOptions
+	dirs  []extractedEntry // 推迟应用元数据的目录
+	links []extractedEntry // 已创建的符号链接，提取结束时再次检查
+}
+
+// extractedEntry 记录一个已提取条目在磁盘上的路径及其标头。
+type extractedEntry struct {
+	path string
+	hdr  *Header
+}
+
+func (x *extractor) extract(hdr *Header, r io.Reader) error { // 注：提取单个条目hdr，r为其内容
+	if hdr.Typeflag == TypeXGlobalHeader {
+		return nil // 全局记录已由Reader合并到后续条目中
+	}
+	rel, err := sanitizeName(hdr.Name)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		// 提取目录本身，仅应用元数据。
+		if hdr.Typeflag == TypeDir {
+			x.dirs = append(x.dirs, extractedEntry{x.root, hdr})
+		}
+		return nil
+	}
+	target, err := x.resolve(rel, hdr.Name)
+	if err != nil {
+		return err
+	}
+	perm := hdr.FileInfo().Mode().Perm()
+
+	switch hdr.Typeflag {
+	case TypeDir:
+		fi, err := os.Lstat(target)
+		if err != nil || !fi.IsDir() {
+			if err := removeExisting(target); err != nil {
+				return err
+			}
+			// 所有者始终可以写入，以便提取其中的条目；最终模式稍后应用。
+			if err := os.Mkdir(target, perm|0700); err != nil {
+				return err
+			}
+		}
+		x.dirs = append(x.dirs, extractedEntry{target, hdr})
+		return nil
+	case TypeReg, TypeRegA, TypeGNUSparse:
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if err != nil {
+			return err
+		}
+		if len(hdr.SparseHoles) > 0 {
+			if err := hdr.PunchSparseHoles(f); err != nil {
+				f.Close()
+				return err
+			}
+		}
+		_, err = io.Copy(f, r) // 对于*Reader，这会调用Reader.WriteTo并跳过孔
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	case TypeSymlink:
+		if err := x.checkLinkTarget(filepath.Dir(target), hdr); err != nil {
+			return err
+		}
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		if err := os.Symlink(hdr.Linkname, target); err != nil {
+			return err
+		}
+		x.links = append(x.links, extractedEntry{target, hdr})
+	case TypeLink:
+		lrel, err := sanitizeName(hdr.Linkname)
+		if err != nil || lrel == "." {
+			return &os.PathError{Op: "extract", Path: hdr.Name, Err: ErrInsecurePath}
+		}
+		old, err := x.resolve(lrel, hdr.Name)
+		if err != nil {
+			return err
+		}
+		// 指向符号链接的硬链接本身也是符号链接，之后对它应用元数据可能会修改链接的目标。
+		if fi, err := os.Lstat(old); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			return &os.PathError{Op: "extract", Path: hdr.Name, Err: ErrInsecurePath}
+		}
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		if err := os.Link(old, target); err != nil {
+			return err
+		}
+	case TypeChar, TypeBlock, TypeFifo:
+		if sysMknod == nil {
+			return &os.PathError{Op: "extract", Path: hdr.Name, Err: errors.New("device files not supported")}
+		}
+		if err := removeExisting(target); err != nil {
+			return err
+		}
+		if err := sysMknod(target, hdr); err != nil {
+			return err
+		}
+	default:
+		return &os.PathError{Op: "extract", Path: hdr.Name, Err: fmt.Errorf("unsupported type flag %q", hdr.Typeflag)}
+	}
+	return x.applyMeta(target, hdr)
+}
+
+// finishDirs 应用推迟的目录元数据，子目录先于父目录。
+func (x *extractor) finishDirs() error { // 注：#
+	sort.SliceStable(x.dirs, func(i, j int) bool { return len(x.dirs[i].path) > len(x.dirs[j].path) })
+	for _, d := range x.dirs {
+		if err := x.applyMeta(d.path, d.hdr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLinks 再次检查所有提取的符号链接，删除（在后续条目改变了路径中的符号链接之后）指向x.root之外的符号链接。
+func (x *extractor) checkLinks() error { // 注：#
+	var err error
+	for _, l := range x.links {
+		fi, lerr := os.Lstat(l.path)
+		if lerr != nil || fi.Mode()&os.ModeSymlink == 0 {
+			continue // 已被后续条目替换
+		}
+		if x.checkLinkTarget(filepath.Dir(l.path), l.hdr) != nil {
+			os.Remove(l.path)
+			if err == nil {
+				err = &os.PathError{Op: "extract", Path: l.hdr.Name, Err: ErrInsecurePath}
+			}
+		}
+	}
+	return err
+}
+
+// applyMeta 根据x.opts将hdr的所有者、模式和时间应用于target，不跟随target处的符号链接。
+func (x *extractor) applyMeta(target string, hdr *Header) error { // 注：#
+	if x.opts.PreserveOwner {
+		if err := os.Lchown(target, hdr.Uid, hdr.Gid); err != nil {
+			return err
+		}
+	}
+	if hdr.Typeflag == TypeSymlink {
+		return nil // 大多数系统无法修改符号链接本身的模式、时间和扩展属性
+	}
+	if !x.opts.SkipXattrs {
+		// 在Lchown和写入内容之后执行，因为两者都会清除security.capability。
+		if err := hdr.RestoreXattrs(target); err != nil {
+			return err
+		}
+	}
+	if x.opts.PreserveMode {
+		// 在Lchown之后执行，因为更改所有者会清除setuid和setgid位。
+		mode := hdr.FileInfo().Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+		if err := lchmod(target, mode); err != nil {
+			return err
+		}
+	} else if hdr.Typeflag == TypeDir {
+		// 撤销创建时强制添加的所有者权限。
+		if mode := hdr.FileInfo().Mode().Perm(); mode&0700 != 0700 {
+			if err := lchmod(target, mode); err != nil {
+				return err
+			}
+		}
+	}
+	if x.opts.PreserveTimes {
+		atime := hdr.AccessTime
+		if atime.IsZero() {
+			atime = hdr.ModTime
+		}
+		if err := lchtimes(target, atime, hdr.ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lchmod 修改path的模式。如果path是符号链接，则返回包装ErrInsecurePath的错误而不修改其目标。
+func lchmod(path string, mode os.FileMode) error { // 注：#
+	if sysLchmod != nil {
+		return sysLchmod(path, mode)
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return &os.PathError{Op: "chmod", Path: path, Err: ErrInsecurePath}
+	}
+	return os.Chmod(path, mode)
+}
+
+// lchtimes 修改path的访问时间和修改时间。如果path是符号链接，则不修改其目标。
+func lchtimes(path string, atime, mtime time.Time) error { // 注：#
+	if sysLchtimes != nil {
+		return sysLchtimes(path, atime, mtime)
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		return &os.PathError{Op: "chtimes", Path: path, Err: ErrInsecurePath}
+	}
+	return os.Chtimes(path, atime, mtime)
+}
+
+// resolve 返回rel在磁盘上的路径，必要时创建其父目录。
+// 如果父目录（在解析所有符号链接后）位于x.root之外，则返回ErrInsecurePath。
+// 返回路径的最后一个元素不会被解析，因此调用者不会跟随其中已存在的符号链接。
+func (x *extractor) resolve(rel, name string) (string, error) { // 注：#
+	parent := filepath.Dir(rel)
+	real := x.root
+	if parent != "." {
+		var err error
+		if real, err = x.mkdirAll(parent, name); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(real, filepath.Base(rel)), nil
+}
+
+// mkdirAll 逐个元素地创建x.root下的目录rel，每一步都解析符号链接并确认结果仍位于x.root之内。
+// 它返回rel解析后的路径。
+func (x *extractor) mkdirAll(rel, name string) (string, error) { // 注：#
+	real := x.root
+	for _, elem := range strings.Split(rel, string(filepath.Separator)) {
+		next := filepath.Join(real, elem)
+		fi, err := os.Lstat(next)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.Mkdir(next, 0755); err != nil && !os.IsExist(err) {
+				return "", err
+			}
+		case err != nil:
+			return "", err
+		case fi.Mode()&os.ModeSymlink != 0:
+			if next, err = filepath.EvalSymlinks(next); err != nil {
+				return "", err
+			}
+		}
+		if !withinDir(x.root, next) {
+			return "", &os.PathError{Op: "extract", Path: name, Err: ErrInsecurePath}
+		}
+		real = next
+	}
+	return real, nil
+}
+
+// checkLinkTarget 报告位于目录parent（已解析）中的符号链接hdr的目标是否位于x.root之内。
+func (x *extractor) checkLinkTarget(parent string, hdr *Header) error { // 注：#
+	link := filepath.FromSlash(hdr.Linkname)
+	if hdr.Linkname == "" || filepath.IsAbs(link) || strings.HasPrefix(hdr.Linkname, "/") ||
+		!x.withinRoot(parent, hdr.Linkname) {
+		return &os.PathError{Op: "extract", Path: hdr.Name, Err: ErrInsecurePath}
+	}
+	return nil
+}
+
+// withinRoot 报告从目录dir（已解析）开始解析以'/'分隔的相对路径rel的每一步是否都位于x.root之内。
+// 与内核一样，它逐个元素地跟随已存在的符号链接，并在跟随之后才处理".."，
+// 因此当y是指向"."的符号链接时，"y/.."解析为dir的父目录而不是dir本身。
+// 不存在的元素按字面处理。
+func (x *extractor) withinRoot(dir, rel string) bool { // 注：#
+	cur := dir
+	parts := strings.Split(rel, "/")
+	for links := 0; len(parts) > 0; {
+		elem := parts[0]
+		parts = parts[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			cur = filepath.Dir(cur)
+		default:
+			next := filepath.Join(cur, elem)
+			fi, err := os.Lstat(next)
+			switch {
+			case os.IsNotExist(err):
+			case err != nil:
+				return false
+			case fi.Mode()&os.ModeSymlink != 0:
+				if links++; links > 255 {
+					return false // 与ELOOP相同的限制
+				}
+				target, err := os.Readlink(next)
+				if err != nil || filepath.IsAbs(target) || strings.HasPrefix(target, "/") {
+					return false
+				}
+				// 符号链接的目标相对于它所在的目录cur解析。
+				parts = append(strings.Split(filepath.ToSlash(target), "/"), parts...)
+				continue
+			}
+			cur = next
+		}
+		if !withinDir(x.root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeName 将存档中的名称转换为相对于提取目录的本地路径。
+// 如果名称为空、是绝对路径或逃逸提取目录，则返回ErrInsecurePath。
+func sanitizeName(name string) (string, error) { // 注：#
+	clean := path.Clean(strings.TrimRight(name, "/"))
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(filepath.FromSlash(name)) ||
+		filepath.VolumeName(filepath.FromSlash(name)) != "" ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", &os.PathError{Op: "extract", Path: name, Err: ErrInsecurePath}
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// withinDir 报告p是否为dir或位于dir之下（两者都必须是干净的绝对路径）。
+func withinDir(dir, p string) bool { // 注：#
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// removeExisting 删除target处已存在的非目录文件或空目录，以便以新的类型重新创建它。
+func removeExisting(target string) error { // 注：#
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// AddOptions 控制AddDir如何将文件添加到存档。
+type AddOptions struct {
+	// Prefix 使用'/'连接在每个条目的名称之前。
+	Prefix string
+
+	// Format 如果不为FormatUnknown，则用作每个Header的Format。
+	Format Format
+
+	// SkipOwner 清除Uid、Gid、Uname和Gname，使存档不依赖于当前系统的用户。
+	SkipOwner bool
+
+	// DetectSparse 对每个常规文件调用Header.DetectSparseHoles，将稀疏文件写为稀疏条目。
+	DetectSparse bool
+
+	// Xattrs 使用Header.CaptureXattrs记录每个文件的扩展属性（包括文件功能和POSIX ACL）。
+	// 具有扩展属性的文件被写为PAX格式。
+	Xattrs bool
+
+	// Filter 如果不为nil，则对每个文件调用，其中path为文件相对于root的以'/'分隔的路径。
+	// 返回false将跳过该文件；对于目录，将跳过整个子树。
+	Filter func(path string, fi os.FileInfo) bool
+}
+
+// AddDir 将以root为根的文件树（不包括root本身）按词法顺序写入tw。
+// 条目的名称相对于root，目录以'/'结尾。
+// 符号链接以符号链接形式存储而不被跟随；在受支持的系统上，
+// 指向已写入文件的其他硬链接被存储为TypeLink条目。套接字被跳过。
+func AddDir(tw *Writer, root string, opts *AddOptions) error { // 注：#
+	var o AddOptions
+	if opts != nil {
+		o = *opts
+	}
+	links := make(map[interface{}]string) // 硬链接的文件标识与其在存档中的名称
+	return filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if o.Filter != nil && !o.Filter(rel, fi) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fi.Mode()&os.ModeSocket != 0 {
+			return nil
+		}
+
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if o.Prefix != "" {
+			hdr.Name = path.Join(o.Prefix, rel)
+		}
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		if o.Format != FormatUnknown {
+			hdr.Format = o.Format
+		}
+		if o.SkipOwner {
+			hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		}
+		if hdr.Typeflag == TypeReg && sysHardLinkKey != nil {
+			if key, ok := sysHardLinkKey(fi); ok {
+				if first, ok := links[key]; ok {
+					hdr.Typeflag, hdr.Linkname, hdr.Size = TypeLink, first, 0
+				} else {
+					links[key] = hdr.Name
+				}
+			}
+		}
+		if o.Xattrs && hdr.Typeflag != TypeLink {
+			if err := hdr.CaptureXattrs(p); err != nil {
+				return err
+			}
+		}
+		if hdr.Typeflag != TypeReg {
+			return tw.WriteHeader(hdr)
+		}
+		return addFile(tw, p, hdr, o.DetectSparse)
+	})
+}
+
+// addFile 将常规文件p的标头hdr及其内容写入tw。
+func addFile(tw *Writer, p string, hdr *Header, sparse bool) error { // 注：#
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if sparse {
+		if err := hdr.DetectSparseHoles(f); err != nil {
+			return err
+		}
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.ReadFrom(f) // 对于稀疏文件，这会跳过孔
+	return err
+}
+
+// sysMknod 如果不为nil，则在path处创建由hdr描述的字符设备、块设备或FIFO。
+var sysMknod func(path string, hdr *Header) error
+
+// sysHardLinkKey 如果不为nil，则为具有多个硬链接的文件返回唯一标识该文件的可比较键。
+var sysHardLinkKey func(fi os.FileInfo) (interface{}, bool)
+
+// sysLchmod 如果不为nil，则在不跟随符号链接的情况下修改path的模式，
+// 检查与修改之间不存在path被替换为符号链接的窗口。
+var sysLchmod func(path string, mode os.FileMode) error
+
+// sysLchtimes 如果不为nil，则在不跟随符号链接的情况下修改path的访问时间和修改时间。
+var sysLchtimes func(path string, atime, mtime time.Time) error
@@ -0,0 +1,120 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	sysMknod = mknodUnix
+	sysHardLinkKey = hardLinkKeyUnix
+	sysLchmod = lchmodLinux
+	sysLchtimes = lchtimesLinux
+}
+
+const (
+	_AT_FDCWD            = -0x64
+	_AT_SYMLINK_NOFOLLOW = 0x100
+	_O_PATH              = 0x200000
+)
+
+// lchmodLinux 修改path的模式而不跟随符号链接。
+// 与glibc的fchmodat(AT_SYMLINK_NOFOLLOW)相同，它以O_PATH|O_NOFOLLOW打开path，
+// 确认得到的不是符号链接，再通过/proc/self/fd修改同一个inode，
+// 因为fchmod(2)不接受O_PATH文件描述符，而fchmodat(2)在Linux 6.6之前忽略flags。
+func lchmodLinux(path string, mode os.FileMode) error { // 注：#
+	fd, err := syscall.Open(path, _O_PATH|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+	defer syscall.Close(fd)
+	var st syscall.Stat_t
+	if err := syscall.Fstat(fd, &st); err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+	if st.Mode&syscall.S_IFMT == syscall.S_IFLNK {
+		return &os.PathError{Op: "chmod", Path: path, Err: ErrInsecurePath}
+	}
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= syscall.S_ISUID
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= syscall.S_ISGID
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= syscall.S_ISVTX
+	}
+	if err := syscall.Chmod("/proc/self/fd/"+strconv.Itoa(fd), m); err != nil {
+		return &os.PathError{Op: "chmod", Path: path, Err: err}
+	}
+	return nil
+}
+
+// lchtimesLinux 使用utimensat(2)和AT_SYMLINK_NOFOLLOW修改path的访问时间和修改时间，
+// 如果path是符号链接，则修改的是符号链接本身。
+func lchtimesLinux(path string, atime, mtime time.Time) error { // 注：#
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return &os.PathError{Op: "chtimes", Path: path, Err: err}
+	}
+	ts := [2]syscall.Timespec{
+		syscall.NsecToTimespec(atime.UnixNano()),
+		syscall.NsecToTimespec(mtime.UnixNano()),
+	}
+	dirfd := _AT_FDCWD
+	_, _, e := syscall.Syscall6(syscall.SYS_UTIMENSAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&ts)), _AT_SYMLINK_NOFOLLOW, 0, 0)
+	if e != 0 {
+		return &os.PathError{Op: "chtimes", Path: path, Err: e}
+	}
+	return nil
+}
+
+// mknodUnix 使用mknod(2)在path处创建hdr描述的设备文件或FIFO。
+func mknodUnix(path string, hdr *Header) error { // 注：#
+	mode := uint32(hdr.Mode & 07777)
+	switch hdr.Typeflag {
+	case TypeChar:
+		mode |= syscall.S_IFCHR
+	case TypeBlock:
+		mode |= syscall.S_IFBLK
+	case TypeFifo:
+		mode |= syscall.S_IFIFO
+	}
+	dev := mkdev(hdr.Devmajor, hdr.Devminor)
+	if err := syscall.Mknod(path, mode, dev); err != nil {
+		return &os.PathError{Op: "mknod", Path: path, Err: err}
+	}
+	return nil
+}
+
+// mkdev 将主设备号和次设备号编码为Linux的dev_t（与statUnix中的解码相反）。
+func mkdev(major, minor int64) int { // 注：#
+	dev := (uint64(major) & 0x00000fff) << 8
+	dev |= (uint64(major) & 0xfffff000) << 32
+	dev |= (uint64(minor) & 0x000000ff) << 0
+	dev |= (uint64(minor) & 0xffffff00) << 12
+	return int(dev)
+}
+
+// devIno 唯一标识一个文件系统上的文件。
+type devIno struct {
+	dev, ino uint64
+}
+
+// hardLinkKeyUnix 为链接计数大于1的常规文件返回其设备号和inode号。
+func hardLinkKeyUnix(fi os.FileInfo) (interface{}, bool) { // 注：#
+	sys, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || sys.Nlink <= 1 {
+		return nil, false
+	}
+	return devIno{uint64(sys.Dev), uint64(sys.Ino)}, true
+}
@@ -0,0 +1,307 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAddDirExtractTo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and permissions not portable")
+	}
+	src, err := ioutil.TempDir("", "tar-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+
+	mtime := time.Unix(1500000000, 0)
+	mustWrite := func(name, data string, mode os.FileMode) {
+		p := filepath.Join(src, name)
+		if err := ioutil.WriteFile(p, []byte(data), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(src, "dir", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite("file.txt", "hello", 0640)
+	mustWrite("dir/exec", "#!/bin/sh\n", 0755)
+	mustWrite("dir/sub/skip.tmp", "ignored", 0644)
+	if err := os.Symlink("../file.txt", filepath.Join(src, "dir", "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := NewWriter(&buf)
+	opts := &AddOptions{
+		SkipOwner: true,
+		Filter:    func(p string, fi os.FileInfo) bool { return filepath.Ext(p) != ".tmp" },
+	}
+	if err := AddDir(tw, src, opts); err != nil {
+		t.Fatalf("AddDir(): unexpected error: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := ioutil.TempDir("", "tar-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	eopts := &ExtractOptions{PreserveMode: true, PreserveTimes: true}
+	if err := ExtractTo(dst, NewReader(bytes.NewReader(buf.Bytes())), eopts); err != nil {
+		t.Fatalf("ExtractTo(): unexpected error: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dst, "dir", "link"))
+	if err != nil || string(b) != "hello" {
+		t.Errorf("read through symlink: got (%q, %v), want %q", b, err, "hello")
+	}
+	fi, err := os.Stat(filepath.Join(dst, "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 || !fi.ModTime().Equal(mtime) {
+		t.Errorf("file.txt: got mode %v, mtime %v; want %v, %v", fi.Mode().Perm(), fi.ModTime(), os.FileMode(0640), mtime)
+	}
+	if fi, err := os.Stat(filepath.Join(dst, "dir", "exec")); err != nil || fi.Mode().Perm() != 0755 {
+		t.Errorf("dir/exec: got (%v, %v), want mode %v", fi, err, os.FileMode(0755))
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "dir", "sub", "skip.tmp")); !os.IsNotExist(err) {
+		t.Errorf("filtered file was extracted: %v", err)
+	}
+}
+
+func TestExtractToInsecure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks not portable")
+	}
+	outside, err := ioutil.TempDir("", "tar-outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+
+	vectors := []struct {
+		label string
+		hdrs  []*Header
+	}{{
+		label: "dot-dot",
+		hdrs:  []*Header{{Name: "a/../../evil", Typeflag: TypeReg}},
+	}, {
+		label: "absolute",
+		hdrs:  []*Header{{Name: filepath.Join(outside, "evil"), Typeflag: TypeReg}},
+	}, {
+		label: "symlink-out",
+		hdrs:  []*Header{{Name: "link", Linkname: "../..", Typeflag: TypeSymlink}},
+	}, {
+		label: "symlink-absolute",
+		hdrs:  []*Header{{Name: "link", Linkname: outside, Typeflag: TypeSymlink}},
+	}, {
+		label: "hardlink-out",
+		hdrs:  []*Header{{Name: "hard", Linkname: "../evil", Typeflag: TypeLink}},
+	}, {
+		label: "existing-symlink",
+		hdrs:  []*Header{{Name: "escape/evil", Typeflag: TypeReg}},
+	}, {
+		label: "hardlink-to-symlink",
+		hdrs: []*Header{
+			{Name: "s", Linkname: ".", Typeflag: TypeSymlink},
+			{Name: "h", Linkname: "s", Typeflag: TypeLink},
+		},
+	}, {
+		label: "symlink-retargeted",
+		hdrs: []*Header{
+			{Name: "x", Linkname: "y/..", Typeflag: TypeSymlink}, // y does not exist yet
+			{Name: "y", Linkname: ".", Typeflag: TypeSymlink},
+		},
+	}}
+
+	for _, v := range vectors {
+		t.Run(v.label, func(t *testing.T) {
+			dst, err := ioutil.TempDir("", "tar-dst")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dst)
+			if err := os.Symlink(outside, filepath.Join(dst, "escape")); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			tw := NewWriter(&buf)
+			for _, hdr := range v.hdrs {
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			err = ExtractTo(dst, NewReader(&buf), nil)
+			if !errors.Is(err, ErrInsecurePath) {
+				t.Errorf("ExtractTo(): got %v, want ErrInsecurePath", err)
+			}
+			if fis, _ := ioutil.ReadDir(outside); len(fis) != 0 {
+				t.Errorf("ExtractTo() wrote outside the target directory: %v", fis[0].Name())
+			}
+		})
+	}
+
+	// The chain y -> ".", x -> "y/.." stays inside the target directory textually,
+	// but x resolves to its parent. A hard link to x must not carry metadata there.
+	t.Run("chained-symlink", func(t *testing.T) {
+		parent, err := ioutil.TempDir("", "tar-parent")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(parent)
+		dst := filepath.Join(parent, "dst")
+		if err := os.Mkdir(dst, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(parent, 0700); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Unix(1e9, 0)
+		if err := os.Chtimes(parent, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		tw := NewWriter(&buf)
+		for _, hdr := range []*Header{
+			{Name: "y", Linkname: ".", Typeflag: TypeSymlink},
+			{Name: "x", Linkname: "y/..", Typeflag: TypeSymlink},
+			{Name: "h", Linkname: "x", Typeflag: TypeLink, Mode: 0777, ModTime: time.Unix(12345, 0)},
+		} {
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		opts := &ExtractOptions{PreserveMode: true, PreserveTimes: true}
+		if err := ExtractTo(dst, NewReader(&buf), opts); !errors.Is(err, ErrInsecurePath) {
+			t.Errorf("ExtractTo(): got %v, want ErrInsecurePath", err)
+		}
+		fi, err := os.Stat(parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0700 || !fi.ModTime().Equal(mtime) {
+			t.Errorf("parent directory changed: mode %v, mtime %v", fi.Mode().Perm(), fi.ModTime())
+		}
+	})
+}
+
+func TestXattrsRoundTrip(t *testing.T) {
+	if sysListXattrs == nil {
+		t.Skip("extended attributes not supported")
+	}
+	src, err := ioutil.TempDir("", "tar-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	p := filepath.Join(src, "file")
+	if err := ioutil.WriteFile(p, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sysSetXattr(p, "user.tar.test", "v\x00al"); err != nil {
+		t.Skipf("setxattr: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tw := NewWriter(&buf)
+	if err := AddDir(tw, src, &AddOptions{Xattrs: true}); err != nil {
+		t.Fatalf("AddDir(): unexpected error: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	hdr, err := NewReader(bytes.NewReader(buf.Bytes())).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hdr.PAXRecords["SCHILY.xattr.user.tar.test"]; got != "v\x00al" || hdr.Format != FormatPAX {
+		t.Fatalf("header: got record %q in format %v, want %q in PAX", got, hdr.Format, "v\x00al")
+	}
+
+	for _, skip := range []bool{false, true} {
+		dst, err := ioutil.TempDir("", "tar-dst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dst)
+		opts := &ExtractOptions{SkipXattrs: skip}
+		if err := ExtractTo(dst, NewReader(bytes.NewReader(buf.Bytes())), opts); err != nil {
+			t.Fatalf("ExtractTo(SkipXattrs: %v): unexpected error: %v", skip, err)
+		}
+		xattrs, err := sysListXattrs(filepath.Join(dst, "file"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := xattrs["user.tar.test"]; ok == skip || (!skip && got != "v\x00al") {
+			t.Errorf("ExtractTo(SkipXattrs: %v): got xattrs %q", skip, xattrs)
+		}
+	}
+}
+
+func TestRestoreXattrsSymlink(t *testing.T) {
+	if sysListXattrs == nil {
+		t.Skip("extended attributes not supported")
+	}
+	dir, err := ioutil.TempDir("", "tar-xattr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sysSetXattr(file, "user.tar.probe", "x"); err != nil {
+		t.Skipf("setxattr: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("file", link); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header claims a regular file, but the path on disk is a symlink:
+	// the attribute must not be written through it.
+	hdr := &Header{Typeflag: TypeReg, Xattrs: map[string]string{"user.tar.test": "v"}}
+	hdr.RestoreXattrs(link)
+	xattrs, err := sysListXattrs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := xattrs["user.tar.test"]; ok {
+		t.Errorf("RestoreXattrs(%q) followed the symlink: got xattrs %q on its target", link, xattrs)
+	}
+	if xattrs, err := sysListXattrs(link); err != nil || len(xattrs) != 0 {
+		t.Errorf("sysListXattrs(%q) = %q, %v; want attributes of the link itself", link, xattrs, err)
+	}
+}
@@ -0,0 +1,285 @@
+// 版权所有2016 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import "strings"
+
+// Format 代表tar存档格式。
+//
+// 原始的tar格式是在Unix V7中引入的。
+// 从那时起，出现了多种竞争格式，试图标准化或扩展V7格式以克服其局限性。
+// 最常见的格式是USTAR，PAX和GNU格式，每种格式都有自己的优点和局限性。
+//
+// 下表列出了每种格式的功能：
+//
+//	                  |  USTAR |       PAX |       GNU
+//	------------------+--------+-----------+----------
+//	Name              |   256B | unlimited | unlimited
+//	Linkname          |   100B | unlimited | unlimited
+//	Size              | uint33 | unlimited |    uint89
+//	Mode              | uint21 |    uint21 |    uint57
+//	Uid/Gid           | uint21 | unlimited |    uint57
+//	Uname/Gname       |    32B | unlimited |       32B
+//	ModTime           | uint33 | unlimited |     int89
+//	AccessTime        |    n/a | unlimited |     int89
+//	ChangeTime        |    n/a | unlimited |     int89
+//	Devmajor/Devminor | uint21 |    uint21 |    uint57
+//	------------------+--------+-----------+----------
+//	string encoding   |  ASCII |     UTF-8 |    binary
+//	sub-second times  |     no |       yes |        no
+//	sparse files      |     no |       yes |       yes
+//
+// 该表的上部显示Header字段，其中每种格式报告每个字符串字段允许的最大字节数和用于存储每个数字字段的整数类型（时间戳存储为自Unix纪元以来的秒数）。
+//
+// 该表的下部显示了每种格式的专用功能，例如支持的字符串编码，对亚秒级时间戳的支持或对稀疏文件的支持。
+//
+// Writer将稀疏文件写为PAX格式（GNU稀疏格式1.0），或在Typeflag为TypeGNUSparse时写为GNU格式。
+type Format int
+
+// 识别的各种tar格式的常量。
+const (
+	// 故意隐藏常量的含义，以使它们不被导出。
+	_ Format = (1 << iota) / 4 // Sequence of 0, 0, 1, 2, 4, 8, etc...
+
+	// FormatUnknown 表示格式未知。
+	FormatUnknown
+
+	// 原始的Unix V7 tar工具的格式（在标准化之前）。
+	formatV7
+
+	// FormatUSTAR 代表POSIX.1-1988中定义的USTAR标头格式。
+	//
+	// 尽管此格式与大多数tar阅读器兼容，但该格式有几个限制，使其不适合某些用途。
+	// 最值得注意的是，它不支持稀疏文件，文件大小大于8GiB，文件名大于256个字符以及非ASCII文件名。
+	//
+	// 参考：
+	//	http://pubs.opengroup.org/onlinepubs/9699919799/utilities/pax.html#tag_20_92_13_06
+	FormatUSTAR
+
+	// FormatPAX 表示POSIX.1-2001中定义的PAX标头格式。
+	//
+	// PAX通过在原始USTAR标头之前编写带有Typeflag TypeXHeader的特殊文件来扩展USTAR。
+	// 该文件包含一组键值记录，用于克服USTAR的缺点，此外还提供了诸如亚秒级时间分辨率之类的其他功能。
+	//
+	// 某些较新的格式通过定义自己的键并为关联值分配某些语义来添加自己的扩展。
+	// 例如，PAX中的稀疏文件支持是使用GNU手册（例如"GNU.sparse.map"）定义的键实现的。
+	//
+	// 参考：
+	//	http://pubs.opengroup.org/onlinepubs/009695399/utilities/pax.html
+	FormatPAX
+
+	// FormatGNU 表示GNU标头格式。
+	//
+	// GNU标头格式比USTAR和PAX标准更旧，并且在很大程度上与它们兼容。
+	// GNU格式支持任意文件大小，任意编码和长度的文件名，稀疏文件以及其他功能。
+	//
+	// 建议在GNU上选择PAX，除非目标应用程序只能解析GNU格式的存档。
+	//
+	// 参考：
+	//	https://www.gnu.org/software/tar/manual/html_node/Standard.html
+	FormatGNU
+
+	// Schily的tar格式，与USTAR不兼容。
+	// 这不包括star的扩展，这些扩展是直接受PAX支持的。
+	formatSTAR
+
+	formatMax
+)
+
+func (f Format) has(f2 Format) bool   { return f&f2 != 0 } // 注：获取f是否包含f2
+func (f *Format) mayBe(f2 Format)     { *f |= f2 }         // 注：将f2加入f
+func (f *Format) mayOnlyBe(f2 Format) { *f &= f2 }         // 注：f只保留f2中的格式
+func (f *Format) mustNotBe(f2 Format) { *f &^= f2 }        // 注：从f中删除f2
+
+var formatNames = map[Format]string{
+	formatV7: "V7", FormatUSTAR: "USTAR", FormatPAX: "PAX", FormatGNU: "GNU", formatSTAR: "STAR",
+}
+
+func (f Format) String() string { // 注：返回f中所有格式的名称，例："(USTAR | PAX)"
+	var ss []string
+	for f2 := Format(1); f2 < formatMax; f2 <<= 1 {
+		if f.has(f2) {
+			ss = append(ss, formatNames[f2])
+		}
+	}
+	switch len(ss) {
+	case 0:
+		return "<unknown>"
+	case 1:
+		return ss[0]
+	default:
+		return "(" + strings.Join(ss, " | ") + ")"
+	}
+}
+
+// 用于识别格式的魔术值。
+const (
+	magicGNU, versionGNU     = "ustar ", " \x00"
+	magicUSTAR, versionUSTAR = "ustar\x00", "00"
+	trailerSTAR              = "tar\x00"
+)
+
+// 与标头格式有关的大小常量。
+const (
+	blockSize  = 512 // tar存档中每个块的大小
+	nameSize   = 100 // USTAR格式中的最大名称长度
+	prefixSize = 155 // USTAR格式中的最大前缀长度
+)
+
+// blockPadding 计算填充到最近的块边缘所需的字节数，其中0 <= n < blockSize。
+func blockPadding(offset int64) (n int64) { // 注：获取offset距离下一个块边缘的字节数
+	return -offset & (blockSize - 1)
+}
+
+var zeroBlock block
+
+type block [blockSize]byte
+
+// 将块转换为多种可能的标头格式之一。
+func (b *block) V7() *headerV7       { return (*headerV7)(b) }
+func (b *block) GNU() *headerGNU     { return (*headerGNU)(b) }
+func (b *block) STAR() *headerSTAR   { return (*headerSTAR)(b) }
+func (b *block) USTAR() *headerUSTAR { return (*headerUSTAR)(b) }
+func (b *block) Sparse() sparseArray { return sparseArray(b[:]) }
+
+// GetFormat 检查块是否包含标头，并返回可能的格式。
+// 返回的值可以是多种可能格式的逻辑或。
+// 如果校验和失败，则返回FormatUnknown。
+//
+// 如果返回的格式是USTAR、PAX或GNU，则保证该块包含可以分析的有效标头。
+func (b *block) GetFormat() Format { // 注：#
+	// 验证校验和。
+	var p parser
+	value := p.parseOctal(b.V7().Chksum())
+	chksum1, chksum2 := b.ComputeChecksum()
+	if p.err != nil || (value != chksum1 && value != chksum2) {
+		return FormatUnknown
+	}
+
+	// 猜测魔术值。
+	magic := string(b.USTAR().Magic())
+	version := string(b.USTAR().Version())
+	trailer := string(b.STAR().Trailer())
+	switch {
+	case magic == magicUSTAR && trailer == trailerSTAR:
+		return formatSTAR
+	case magic == magicUSTAR:
+		return FormatUSTAR | FormatPAX
+	case magic == magicGNU && version == versionGNU:
+		return FormatGNU
+	default:
+		return formatV7
+	}
+}
+
+// SetFormat 将magic和version字段写入适当格式的块中。
+// 如果格式未知，则跳过写入校验和。
+func (b *block) SetFormat(format Format) { // 注：#
+	// 设置magic值。
+	switch {
+	case format.has(formatV7):
+		// 什么都不做。
+	case format.has(FormatGNU):
+		copy(b.GNU().Magic(), magicGNU)
+		copy(b.GNU().Version(), versionGNU)
+	case format.has(formatSTAR):
+		copy(b.STAR().Magic(), magicUSTAR)
+		copy(b.STAR().Version(), versionUSTAR)
+		copy(b.STAR().Trailer(), trailerSTAR)
+	case format.has(FormatUSTAR | FormatPAX):
+		copy(b.USTAR().Magic(), magicUSTAR)
+		copy(b.USTAR().Version(), versionUSTAR)
+	default:
+		panic("invalid format")
+	}
+
+	// 更新校验和。
+	// 与其他tar实现一样，此字段始终使用八进制格式进行格式化。
+	var f formatter
+	field := b.V7().Chksum()
+	chksum, _ := b.ComputeChecksum() // 可能是有符号或无符号的，注：#
+	f.formatOctal(field[:7], chksum) // 从不失败，因为保证了128*256 < 8^7
+	field[7] = ' '
+}
+
+// ComputeChecksum 计算标头块的校验和。
+// POSIX将校验和指定为所有字节的和，而Sun tar使用带符号的数学运算，因此我们计算两者。
+func (b *block) ComputeChecksum() (unsigned, signed int64) { // 注：计算b的无符号与有符号校验和
+	for i, c := range b {
+		if 148 <= i && i < 156 {
+			c = ' ' // 将校验和字段本身视为全部为空格。
+		}
+		unsigned += int64(c)
+		signed += int64(int8(c))
+	}
+	return unsigned, signed
+}
+
+// Reset 清除块状态。
+func (b *block) Reset() { // 注：将b的所有字节置为0
+	*b = block{}
+}
+
+type headerV7 [blockSize]byte
+
+func (h *headerV7) Name() []byte     { return h[000:][:100] }
+func (h *headerV7) Mode() []byte     { return h[100:][:8] }
+func (h *headerV7) UID() []byte      { return h[108:][:8] }
+func (h *headerV7) GID() []byte      { return h[116:][:8] }
+func (h *headerV7) Size() []byte     { return h[124:][:12] }
+func (h *headerV7) ModTime() []byte  { return h[136:][:12] }
+func (h *headerV7) Chksum() []byte   { return h[148:][:8] }
+func (h *headerV7) TypeFlag() []byte { return h[156:][:1] }
+func (h *headerV7) LinkName() []byte { return h[157:][:100] }
+
+type headerGNU [blockSize]byte
+
+func (h *headerGNU) V7() *headerV7       { return (*headerV7)(h) }
+func (h *headerGNU) Magic() []byte       { return h[257:][:6] }
+func (h *headerGNU) Version() []byte     { return h[263:][:2] }
+func (h *headerGNU) UserName() []byte    { return h[265:][:32] }
+func (h *headerGNU) GroupName() []byte   { return h[297:][:32] }
+func (h *headerGNU) DevMajor() []byte    { return h[329:][:8] }
+func (h *headerGNU) DevMinor() []byte    { return h[337:][:8] }
+func (h *headerGNU) AccessTime() []byte  { return h[345:][:12] }
+func (h *headerGNU) ChangeTime() []byte  { return h[357:][:12] }
+func (h *headerGNU) Sparse() sparseArray { return sparseArray(h[386:][:24*4+1]) }
+func (h *headerGNU) RealSize() []byte    { return h[483:][:12] }
+
+type headerSTAR [blockSize]byte
+
+func (h *headerSTAR) V7() *headerV7      { return (*headerV7)(h) }
+func (h *headerSTAR) Magic() []byte      { return h[257:][:6] }
+func (h *headerSTAR) Version() []byte    { return h[263:][:2] }
+func (h *headerSTAR) UserName() []byte   { return h[265:][:32] }
+func (h *headerSTAR) GroupName() []byte  { return h[297:][:32] }
+func (h *headerSTAR) DevMajor() []byte   { return h[329:][:8] }
+func (h *headerSTAR) DevMinor() []byte   { return h[337:][:8] }
+func (h *headerSTAR) Prefix() []byte     { return h[345:][:131] }
+func (h *headerSTAR) AccessTime() []byte { return h[476:][:12] }
+func (h *headerSTAR) ChangeTime() []byte { return h[488:][:12] }
+func (h *headerSTAR) Trailer() []byte    { return h[508:][:4] }
+
+type headerUSTAR [blockSize]byte
+
+func (h *headerUSTAR) V7() *headerV7     { return (*headerV7)(h) }
+func (h *headerUSTAR) Magic() []byte     { return h[257:][:6] }
+func (h *headerUSTAR) Version() []byte   { return h[263:][:2] }
+func (h *headerUSTAR) UserName() []byte  { return h[265:][:32] }
+func (h *headerUSTAR) GroupName() []byte { return h[297:][:32] }
+func (h *headerUSTAR) DevMajor() []byte  { return h[329:][:8] }
+func (h *headerUSTAR) DevMinor() []byte  { return h[337:][:8] }
+func (h *headerUSTAR) Prefix() []byte    { return h[345:][:155] }
+
+type sparseArray []byte
+
+func (s sparseArray) Entry(i int) sparseElem { return sparseElem(s[i*24:]) }
+func (s sparseArray) IsExtended() []byte     { return s[24*s.MaxEntries():][:1] }
+func (s sparseArray) MaxEntries() int        { return len(s) / 24 }
+
+type sparseElem []byte
+
+func (s sparseElem) Offset() []byte { return s[00:][:12] }
+func (s sparseElem) Length() []byte { return s[12:][:12] }
@@ -0,0 +1,379 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+var errInvalidIndex = errors.New("archive/tar: invalid index") // 错误："无效的索引"
+
+// indexMagic 是Index序列化形式的开头，最后一个字节是版本号。
+const indexMagic = "tarindex\x01"
+
+// IndexEntry 描述存档中一个条目的位置。
+type IndexEntry struct {
+	Name     string // 条目的名称
+	Linkname string // 硬链接和符号链接的目标
+	Typeflag byte   // 条目的类型
+	Size     int64  // 逻辑文件大小（以字节为单位），包括稀疏孔
+
+	// HeaderOffset 是属于该条目的第一个标头块（包括任何PAX或GNU元标头）在存档中的偏移量。
+	HeaderOffset int64
+
+	// DataOffset 是条目数据在存档中的偏移量。
+	DataOffset int64
+
+	// SparseDatas 对于稀疏文件，列出文件中包含数据的片段（按顺序连续地存储在DataOffset处）。
+	// 对于非稀疏文件，它为nil。
+	SparseDatas []SparseEntry
+}
+
+// physicalSize 返回条目数据在存档中占用的字节数（不包括块填充）。
+func (e *IndexEntry) physicalSize() int64 { // 注：#
+	if e.SparseDatas == nil {
+		if isHeaderOnlyType(e.Typeflag) {
+			return 0
+		}
+		return e.Size
+	}
+	var n int64
+	for _, s := range e.SparseDatas {
+		n += s.Length
+	}
+	return n
+}
+
+// Index 是存档中条目位置的索引，允许在不扫描整个存档的情况下随机访问单个条目。
+//
+// Index只需通过NewIndex构建一次，并可以使用MarshalBinary保存，之后使用LoadIndex重新加载。
+type Index struct {
+	r       io.ReaderAt
+	entries []IndexEntry
+	names   map[string]int // 名称与其最后一次出现在entries中的下标
+}
+
+// NewIndex 从头到尾读取r中的存档一次，并返回其索引。
+// r中的数据在Index的生命周期内不得更改。
+func NewIndex(r io.ReaderAt) (*Index, error) { // 注：#
+	or := &offsetReader{r: r}
+	tr := NewReader(or)
+	ix := &Index{r: r}
+	var next int64 // 下一个条目的第一个标头块的偏移量
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := IndexEntry{
+			Name:         hdr.Name,
+			Linkname:     hdr.Linkname,
+			Typeflag:     hdr.Typeflag,
+			Size:         hdr.Size,
+			HeaderOffset: next,
+			DataOffset:   or.off,
+		}
+		if _, ok := tr.curr.(*sparseFileReader); ok {
+			holes := append([]SparseEntry{}, hdr.SparseHoles...)
+			e.SparseDatas = invertSparseEntries(holes, hdr.Size)
+			if n := len(e.SparseDatas); e.SparseDatas[n-1].Length == 0 {
+				e.SparseDatas = e.SparseDatas[:n-1]
+			}
+		}
+		phys := tr.curr.PhysicalRemaining()
+		next = e.DataOffset + phys + blockPadding(phys)
+		ix.entries = append(ix.entries, e)
+	}
+	ix.buildNames()
+	return ix, nil
+}
+
+// LoadIndex 解码由Index.MarshalBinary生成的数据，并返回访问r中存档的Index。
+// r必须包含构建索引时的同一存档。
+func LoadIndex(data []byte, r io.ReaderAt) (*Index, error) { // 注：#
+	if len(data) < len(indexMagic) || string(data[:len(indexMagic)]) != indexMagic {
+		return nil, errInvalidIndex
+	}
+	d := indexDecoder{b: data[len(indexMagic):]}
+	n := d.uvarint()
+	if n > uint64(len(d.b)) {
+		return nil, errInvalidIndex // 每个条目至少占用一个字节
+	}
+	ix := &Index{r: r, entries: make([]IndexEntry, 0, n)}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		e := IndexEntry{
+			Name:         d.string(),
+			Linkname:     d.string(),
+			Typeflag:     d.byte(),
+			Size:         d.varint(),
+			HeaderOffset: d.varint(),
+			DataOffset:   d.varint(),
+		}
+		if ns := d.uvarint(); ns > 0 {
+			if ns-1 > uint64(len(d.b)) {
+				return nil, errInvalidIndex
+			}
+			e.SparseDatas = make([]SparseEntry, ns-1)
+			for j := range e.SparseDatas {
+				e.SparseDatas[j] = SparseEntry{Offset: d.varint(), Length: d.varint()}
+			}
+			if !validateSparseEntries(e.SparseDatas, e.Size) {
+				d.err = errInvalidIndex
+			}
+		}
+		if e.Size < 0 || e.HeaderOffset < 0 || e.DataOffset < e.HeaderOffset {
+			d.err = errInvalidIndex
+		}
+		ix.entries = append(ix.entries, e)
+	}
+	if d.err != nil || len(d.b) != 0 {
+		return nil, errInvalidIndex
+	}
+	ix.buildNames()
+	return ix, nil
+}
+
+// MarshalBinary 实现encoding.BinaryMarshaler接口。
+// 结果不包含存档本身，只包含条目的位置。
+func (ix *Index) MarshalBinary() ([]byte, error) { // 注：#
+	b := []byte(indexMagic)
+	var tmp [binary.MaxVarintLen64]byte
+	putUvarint := func(x uint64) { b = append(b, tmp[:binary.PutUvarint(tmp[:], x)]...) }
+	putVarint := func(x int64) { b = append(b, tmp[:binary.PutVarint(tmp[:], x)]...) }
+	putString := func(s string) { putUvarint(uint64(len(s))); b = append(b, s...) }
+
+	putUvarint(uint64(len(ix.entries)))
+	for _, e := range ix.entries {
+		putString(e.Name)
+		putString(e.Linkname)
+		b = append(b, e.Typeflag)
+		putVarint(e.Size)
+		putVarint(e.HeaderOffset)
+		putVarint(e.DataOffset)
+		if e.SparseDatas == nil {
+			putUvarint(0)
+			continue
+		}
+		putUvarint(uint64(len(e.SparseDatas)) + 1)
+		for _, s := range e.SparseDatas {
+			putVarint(s.Offset)
+			putVarint(s.Length)
+		}
+	}
+	return b, nil
+}
+
+// Entries 按存档中的顺序返回所有条目。
+// 返回的切片不得修改。
+func (ix *Index) Entries() []IndexEntry { // 注：#
+	return ix.entries
+}
+
+// Lookup 返回名称为name的条目。
+// 如果多个条目具有相同的名称，则返回最后一个，与提取存档时的结果一致。
+func (ix *Index) Lookup(name string) (*IndexEntry, bool) { // 注：#
+	i, ok := ix.names[name]
+	if !ok {
+		return nil, false
+	}
+	return &ix.entries[i], true
+}
+
+// Open 返回一个读取名为name的常规文件内容的io.SectionReader。
+// 与提取存档时一样，硬链接被解析为存档中位于链接之前的最后一个同名条目，
+// 硬链接链会被逐级跟随；稀疏文件的孔读取为NUL字节。
+// 如果没有这样的条目，则返回的错误满足os.IsNotExist。
+func (ix *Index) Open(name string) (*io.SectionReader, error) { // 注：#
+	i, ok := ix.names[name]
+	for ok && ix.entries[i].Typeflag == TypeLink {
+		i, ok = ix.linkTarget(i)
+	}
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	e := &ix.entries[i]
+	switch e.Typeflag {
+	case TypeReg, TypeRegA, TypeGNUSparse, TypeCont:
+	default:
+		return nil, &os.PathError{Op: "open", Path: name, Err: errors.New("not a regular file")}
+	}
+	if e.SparseDatas == nil {
+		return io.NewSectionReader(ix.r, e.DataOffset, e.Size), nil
+	}
+	return io.NewSectionReader(newSparseReaderAt(ix.r, e), 0, e.Size), nil
+}
+
+// linkTarget 返回位于硬链接条目i之前、名称为其Linkname的最后一个条目的位置。
+// 由于位置严格递减，跟随硬链接链总会终止。
+func (ix *Index) linkTarget(i int) (int, bool) { // 注：#
+	target := ix.entries[i].Linkname
+	for j := i - 1; j >= 0; j-- {
+		if e := &ix.entries[j]; e.Name == target && e.Typeflag != TypeXGlobalHeader {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func (ix *Index) buildNames() { // 注：#
+	ix.names = make(map[string]int, len(ix.entries))
+	for i, e := range ix.entries {
+		if e.Typeflag != TypeXGlobalHeader {
+			ix.names[e.Name] = i
+		}
+	}
+}
+
+// sparseReaderAt 在稀疏条目的逻辑内容中实现io.ReaderAt。
+type sparseReaderAt struct {
+	r    io.ReaderAt
+	e    *IndexEntry
+	phys []int64 // 每个数据片段相对于DataOffset的物理偏移量
+}
+
+func newSparseReaderAt(r io.ReaderAt, e *IndexEntry) *sparseReaderAt { // 注：#
+	phys := make([]int64, len(e.SparseDatas))
+	var n int64
+	for i, s := range e.SparseDatas {
+		phys[i] = n
+		n += s.Length
+	}
+	return &sparseReaderAt{r: r, e: e, phys: phys}
+}
+
+func (sr *sparseReaderAt) ReadAt(b []byte, off int64) (n int, err error) { // 注：#
+	if off < 0 {
+		return 0, errors.New("archive/tar: negative offset")
+	}
+	if off >= sr.e.Size {
+		return 0, io.EOF
+	}
+	if max := sr.e.Size - off; int64(len(b)) > max {
+		b, err = b[:max], io.EOF
+	}
+
+	sp := sr.e.SparseDatas
+	i := sort.Search(len(sp), func(i int) bool { return sp[i].endOffset() > off })
+	for n < len(b) {
+		pos := off + int64(n)
+		if i < len(sp) && pos >= sp[i].Offset {
+			// 读取数据片段。
+			m := int(min(sp[i].endOffset()-pos, int64(len(b)-n)))
+			m, rerr := sr.r.ReadAt(b[n:n+m], sr.e.DataOffset+sr.phys[i]+pos-sp[i].Offset)
+			n += m
+			if rerr != nil && (rerr != io.EOF || n < len(b)) {
+				if rerr == io.EOF {
+					rerr = io.ErrUnexpectedEOF
+				}
+				return n, rerr
+			}
+			i++
+			continue
+		}
+
+		// 填充孔直到下一个数据片段。
+		end := sr.e.Size
+		if i < len(sp) {
+			end = sp[i].Offset
+		}
+		m := int(min(end-pos, int64(len(b)-n)))
+		for j := range b[n : n+m] {
+			b[n+j] = 0
+		}
+		n += m
+	}
+	return n, err
+}
+
+// offsetReader 将io.ReaderAt转换为io.ReadSeeker，并记录当前偏移量。
+type offsetReader struct {
+	r   io.ReaderAt
+	off int64
+}
+
+func (or *offsetReader) Read(b []byte) (int, error) { // 注：#
+	n, err := or.r.ReadAt(b, or.off)
+	or.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil // 下一次Read再报告io.EOF
+	}
+	return n, err
+}
+
+func (or *offsetReader) Seek(offset int64, whence int) (int64, error) { // 注：#
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += or.off
+	default:
+		return or.off, errors.New("archive/tar: unsupported whence")
+	}
+	if offset < 0 {
+		return or.off, errors.New("archive/tar: negative position")
+	}
+	or.off = offset
+	return offset, nil
+}
+
+// indexDecoder 解码Index的序列化形式，并记录遇到的第一个错误。
+type indexDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *indexDecoder) uvarint() uint64 { // 注：#
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *indexDecoder) varint() int64 { // 注：#
+	x, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *indexDecoder) byte() byte { // 注：#
+	if len(d.b) == 0 {
+		d.fail()
+		return 0
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *indexDecoder) string() string { // 注：#
+	n := d.uvarint()
+	if n > uint64(len(d.b)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *indexDecoder) fail() { // 注：#
+	if d.err == nil {
+		d.err = errInvalidIndex
+	}
+	d.b = nil
+}
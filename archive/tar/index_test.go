@@ -0,0 +1,174 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	files := []string{
+		"testdata/ustar.tar",
+		"testdata/gnu-long.tar",
+		"testdata/pax-global.tar",
+		"testdata/gnu-sparse.tar",
+		"testdata/pax-sparse-0.0.tar",
+		"testdata/pax-sparse-0.1.tar",
+		"testdata/pax-sparse-1.0.tar",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ix, err := NewIndex(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("NewIndex(): unexpected error: %v", err)
+			}
+			b, err := ix.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary(): unexpected error: %v", err)
+			}
+			ix, err = LoadIndex(b, bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("LoadIndex(): unexpected error: %v", err)
+			}
+
+			// Compare every regular file against a sequential read.
+			tr := NewReader(bytes.NewReader(data))
+			for i := 0; ; i++ {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					if i != len(ix.Entries()) {
+						t.Errorf("got %d index entries, want %d", len(ix.Entries()), i)
+					}
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, err := ioutil.ReadAll(tr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e := ix.Entries()[i]
+				if e.Name != hdr.Name || e.Typeflag != hdr.Typeflag || e.Size != hdr.Size {
+					t.Errorf("entry %d: got %+v, want header %+v", i, e, hdr)
+				}
+				if hdr.Typeflag != TypeReg && hdr.Typeflag != TypeGNUSparse {
+					continue
+				}
+				sr, err := ix.Open(hdr.Name)
+				if err != nil {
+					t.Fatalf("Open(%q): unexpected error: %v", hdr.Name, err)
+				}
+				got, err := ioutil.ReadAll(sr)
+				if err != nil {
+					t.Fatalf("ReadAll(%q): unexpected error: %v", hdr.Name, err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("Open(%q): content mismatch", hdr.Name)
+				}
+
+				// Read a window straddling the end of the file.
+				if n := len(want); n > 10 {
+					buf := make([]byte, 20)
+					m, err := sr.ReadAt(buf, int64(n-10))
+					if m != 10 || err != io.EOF || !bytes.Equal(buf[:m], want[n-10:]) {
+						t.Errorf("ReadAt(%q) at end: got (%d, %v)", hdr.Name, m, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/ustar.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := NewIndex(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Open("missing"); !os.IsNotExist(err) {
+		t.Errorf("Open(missing): got %v, want not-exist error", err)
+	}
+	if _, err := ix.Open("dir/"); err == nil {
+		t.Errorf("Open(dir/): got nil error, want error")
+	}
+
+	b, _ := ix.MarshalBinary()
+	for _, bad := range [][]byte{nil, b[:len(b)-1], append(append([]byte{}, b...), 0)} {
+		if _, err := LoadIndex(bad, bytes.NewReader(data)); err != errInvalidIndex {
+			t.Errorf("LoadIndex(%d bytes): got %v, want %v", len(bad), err, errInvalidIndex)
+		}
+	}
+}
+
+func TestIndexHardlinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewWriter(&buf)
+	for _, f := range []struct {
+		hdr  Header
+		data string
+	}{
+		{Header{Name: "a", Typeflag: TypeReg, Mode: 0644}, "old a"},
+		{Header{Name: "b", Typeflag: TypeLink, Linkname: "a"}, ""},
+		{Header{Name: "c", Typeflag: TypeLink, Linkname: "b"}, ""},
+		{Header{Name: "a", Typeflag: TypeReg, Mode: 0644}, "new a"},
+		{Header{Name: "d", Typeflag: TypeLink, Linkname: "missing"}, ""},
+		{Header{Name: "e", Typeflag: TypeLink, Linkname: "e"}, ""},
+	} {
+		f.hdr.Size = int64(len(f.data))
+		if err := tw.WriteHeader(&f.hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := NewIndex(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Links refer to the entry before them, not to a later one with the same name.
+	for name, want := range map[string]string{"a": "new a", "b": "old a", "c": "old a"} {
+		sr, err := ix.Open(name)
+		if err != nil {
+			t.Errorf("Open(%q): unexpected error: %v", name, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(sr); string(got) != want {
+			t.Errorf("Open(%q) = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"d", "e"} {
+		if _, err := ix.Open(name); !os.IsNotExist(err) {
+			t.Errorf("Open(%q): got %v, want not-exist error", name, err)
+		}
+	}
+}
+
+func TestLoadIndexNegativeOffset(t *testing.T) {
+	ix := &Index{entries: []IndexEntry{{Name: "a", Typeflag: TypeReg, HeaderOffset: -512, DataOffset: 0}}}
+	b, err := ix.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadIndex(b, bytes.NewReader(nil)); err != errInvalidIndex {
+		t.Errorf("LoadIndex(negative HeaderOffset): got %v, want %v", err, errInvalidIndex)
+	}
+}
@@ -0,0 +1,909 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader 提供对tar存档内容的顺序访问。
+// Reader.Next前进到存档中的下一个文件（包括第一个文件），然后Reader可以被视为io.Reader以访问文件的数据。
+type Reader struct {
+	r    io.Reader
+	pad  int64      // 当前文件条目之后的填充量（已忽略）
+	curr fileReader // 当前文件条目的Reader
+	blk  block      // 用作临时本地存储的缓冲区
+
+	// paxGlobal 保存TypeXGlobalHeader中的记录，它们适用于之后的所有文件，直到被再次覆盖。
+	paxGlobal map[string]string
+
+	// ustarHdr 和 paxHdrs 记录Next返回的最后一个文件在合并PAX记录之前的标头以及它自己的PAX记录，供Validate使用。
+	ustarHdr Header
+	paxHdrs  map[string]string
+
+	// err 是一个持久性错误。
+	// 确保此错误具有粘性，是Reader的每个导出方法的责任。
+	err error
+}
+
+type fileReader interface {
+	io.Reader
+	fileState
+
+	WriteTo(io.Writer) (int64, error)
+}
+
+// NewReader 创建一个从r读取的新Reader。
+func NewReader(r io.Reader) *Reader { // 注：工厂函数，生成一个从r读取的Reader
+	return &Reader{r: r, curr: &regFileReader{r, 0}}
+}
+
+// Next 前进到tar存档中的下一个条目。
+// Header.Size确定下一个文件可以读取多少字节。
+// 当前文件中任何剩余的数据都将被自动丢弃。
+//
+// 在输入结束时返回io.EOF。
+// 如果输入格式错误，返回的错误满足errors.Is(err, ErrHeader)，并描述了发现的问题。
+func (tr *Reader) Next() (*Header, error) { // 注：#
+	if tr.err != nil {
+		return nil, tr.err
+	}
+	hdr, err := tr.next()
+	tr.err = err
+	return hdr, err
+}
+
+func (tr *Reader) next() (*Header, error) { // 注：#
+	var paxHdrs map[string]string
+	var gnuLongName, gnuLongLink string
+
+	// 在外部，Next遍历tar存档，就好像它是一系列文件一样。
+	// 在内部，tar格式通常使用伪造的"文件"来添加描述下一个文件的元数据。
+	// 这些元数据"文件"通常不应在外部可见。
+	// 因此，此循环遍历一个或多个"标头文件"，直到找到"普通文件"为止。
+	format := FormatUSTAR | FormatPAX | FormatGNU
+	for {
+		// 丢弃文件的其余部分和任何填充。
+		if err := discard(tr.r, tr.curr.PhysicalRemaining()); err != nil {
+			return nil, err
+		}
+		if _, err := tryReadFull(tr.r, tr.blk[:tr.pad]); err != nil {
+			return nil, err
+		}
+		tr.pad = 0
+
+		hdr, rawHdr, err := tr.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		if err := tr.handleRegularFile(hdr); err != nil {
+			return nil, err
+		}
+		format.mayOnlyBe(hdr.Format)
+
+		// 检查PAX/GNU特殊标头和文件。
+		switch hdr.Typeflag {
+		case TypeXHeader, TypeXGlobalHeader:
+			format.mayOnlyBe(FormatPAX)
+			paxHdrs, err = parsePAX(tr)
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag == TypeXGlobalHeader {
+				if err := mergePAX(hdr, paxHdrs); err != nil {
+					return nil, err
+				}
+				tr.setGlobalPAX(paxHdrs)
+				return &Header{
+					Name:       hdr.Name,
+					Typeflag:   hdr.Typeflag,
+					Xattrs:     hdr.Xattrs,
+					PAXRecords: hdr.PAXRecords,
+					Format:     format,
+				}, nil
+			}
+			continue // 这是影响下一个标头的元标头
+		case TypeGNULongName, TypeGNULongLink:
+			format.mayOnlyBe(FormatGNU)
+			realname, err := readSpecialFile(tr)
+			if err != nil {
+				return nil, err
+			}
+
+			var p parser
+			switch hdr.Typeflag {
+			case TypeGNULongName:
+				gnuLongName = p.parseString(realname)
+			case TypeGNULongLink:
+				gnuLongLink = p.parseString(realname)
+			}
+			continue // 这是影响下一个标头的元标头
+		default:
+			// 旧的GNU稀疏格式在这里处理，因为它在技术上只是具有附加属性的常规文件。
+
+			if len(tr.paxGlobal) > 0 {
+				format.mayOnlyBe(FormatPAX) // 全局记录仅存在于PAX存档中
+			}
+			tr.ustarHdr, tr.paxHdrs = *hdr, paxHdrs
+			if err := mergePAX(hdr, tr.withGlobalPAX(paxHdrs)); err != nil {
+				return nil, err
+			}
+			if gnuLongName != "" {
+				hdr.Name = gnuLongName
+			}
+			if gnuLongLink != "" {
+				hdr.Linkname = gnuLongLink
+			}
+			if hdr.Typeflag == TypeRegA {
+				if strings.HasSuffix(hdr.Name, "/") {
+					hdr.Typeflag = TypeDir // 旧存档使用尾部斜杠表示目录
+				} else {
+					hdr.Typeflag = TypeReg
+				}
+			}
+
+			// 扩展标头可能已更新了大小。
+			// 因此，在合并PAX标头后再次设置regFileReader。
+			if err := tr.handleRegularFile(hdr); err != nil {
+				return nil, err
+			}
+
+			// 稀疏格式依赖于能够从逻辑数据部分读取；必须先调用handleRegularFile。
+			if err := tr.handleSparseFile(hdr, rawHdr); err != nil {
+				return nil, err
+			}
+
+			// 设置对格式的最终猜测。
+			if format.has(FormatUSTAR) && format.has(FormatPAX) {
+				format.mayOnlyBe(FormatUSTAR)
+			}
+			hdr.Format = format
+			return hdr, nil // 这是一个文件，因此停止
+		}
+	}
+}
+
+// setGlobalPAX 将全局标头中的记录合并到tr.paxGlobal中。
+// 值为空的记录会删除先前的全局记录。
+func (tr *Reader) setGlobalPAX(paxHdrs map[string]string) { // 注：将paxHdrs合并到tr.paxGlobal中
+	for k, v := range paxHdrs {
+		if v == "" {
+			delete(tr.paxGlobal, k)
+			continue
+		}
+		if tr.paxGlobal == nil {
+			tr.paxGlobal = make(map[string]string)
+		}
+		tr.paxGlobal[k] = v
+	}
+}
+
+// withGlobalPAX 返回paxHdrs与可继承的全局记录的并集，其中paxHdrs中的记录优先。
+func (tr *Reader) withGlobalPAX(paxHdrs map[string]string) map[string]string { // 注：#
+	if len(tr.paxGlobal) == 0 {
+		return paxHdrs
+	}
+	merged := make(map[string]string, len(tr.paxGlobal)+len(paxHdrs))
+	for k, v := range tr.paxGlobal {
+		if inheritableGlobalPAX(k) {
+			merged[k] = v
+		}
+	}
+	for k, v := range paxHdrs {
+		merged[k] = v
+	}
+	return merged
+}
+
+// inheritableGlobalPAX 报告全局记录k是否可以应用于之后的文件。
+// path、linkpath、size和GNU稀疏记录只描述单个文件，
+// 如果继承它们，之后的每个文件都会得到同样的名称、链接目标、大小或稀疏映射。
+func inheritableGlobalPAX(k string) bool { // 注：#
+	switch k {
+	case paxPath, paxLinkpath, paxSize:
+		return false
+	}
+	return !strings.HasPrefix(k, paxGNUSparse)
+}
+
+// handleRegularFile 为当前文件设置读取器，设置所有可能的填充，并返回可能发生的任何错误。
+func (tr *Reader) handleRegularFile(hdr *Header) error { // 注：#
+	nb := hdr.Size
+	if isHeaderOnlyType(hdr.Typeflag) {
+		nb = 0
+	}
+	if nb < 0 {
+		return decodeHeaderError(fmt.Sprintf("negative size %d for %q", nb, hdr.Name))
+	}
+
+	tr.pad = blockPadding(nb)
+	tr.curr = &regFileReader{r: tr.r, nb: nb}
+	return nil
+}
+
+// handleSparseFile 检查当前文件是否为任何类型的稀疏格式，并相应地设置当前读取器和hdr.SparseHoles。
+func (tr *Reader) handleSparseFile(hdr *Header, rawHdr *block) error { // 注：#
+	var spd sparseDatas
+	var err error
+	if hdr.Typeflag == TypeGNUSparse {
+		spd, err = tr.readOldGNUSparseMap(hdr, rawHdr)
+	} else {
+		spd, err = tr.readGNUSparsePAXHeaders(hdr)
+	}
+
+	// 如果spd不为nil，则这是一个稀疏文件。
+	// 请注意，len(spd) == 0是可能的。
+	if err == nil && spd != nil {
+		if isHeaderOnlyType(hdr.Typeflag) || !validateSparseEntries(spd, hdr.Size) {
+			return decodeHeaderError(fmt.Sprintf("invalid sparse map for %q", hdr.Name))
+		}
+		sph := invertSparseEntries(spd, hdr.Size)
+		tr.curr = &sparseFileReader{tr.curr, sph, 0}
+
+		// 最后一个片段总是存在（可能为空），它只用于记录文件的大小。
+		hdr.SparseHoles = append([]SparseEntry{}, sph...)
+		if n := len(hdr.SparseHoles); hdr.SparseHoles[n-1].Length == 0 {
+			hdr.SparseHoles = hdr.SparseHoles[:n-1]
+		}
+	}
+	return err
+}
+
+// readGNUSparsePAXHeaders 检查PAX标头中是否有GNU稀疏标头。
+// 如果找到它们，则此函数将读取稀疏映射并返回它。
+// 这假定0.0标头已经通过PAX标头解析逻辑转换为0.1标头。
+func (tr *Reader) readGNUSparsePAXHeaders(hdr *Header) (sparseDatas, error) { // 注：#
+	// 确定GNU标头的版本。
+	var is1x0 bool
+	major, minor := hdr.PAXRecords[paxGNUSparseMajor], hdr.PAXRecords[paxGNUSparseMinor]
+	switch {
+	case major == "0" && (minor == "0" || minor == "1"):
+		is1x0 = false
+	case major == "1" && minor == "0":
+		is1x0 = true
+	case major != "" || minor != "":
+		return nil, nil // 未知的GNU稀疏PAX版本
+	case hdr.PAXRecords[paxGNUSparseMap] != "":
+		is1x0 = false // 0.0和0.1没有明确的版本记录，因此请猜测
+	default:
+		return nil, nil // 不是PAX格式的GNU稀疏文件。
+	}
+	hdr.Format.mayOnlyBe(FormatPAX)
+
+	// 从GNU稀疏PAX标头更新hdr。
+	if name := hdr.PAXRecords[paxGNUSparseName]; name != "" {
+		hdr.Name = name
+	}
+	size := hdr.PAXRecords[paxGNUSparseSize]
+	if size == "" {
+		size = hdr.PAXRecords[paxGNUSparseRealSize]
+	}
+	if size != "" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return nil, decodeHeaderError(fmt.Sprintf("invalid GNU sparse size %q", size))
+		}
+		hdr.Size = n
+	}
+
+	// 根据适当的格式读取稀疏映射。
+	if is1x0 {
+		return readGNUSparseMap1x0(tr.curr)
+	}
+	return readGNUSparseMap0x1(hdr.PAXRecords)
+}
+
+// mergePAX 将paxHdrs中的标准PAX记录合并到hdr中。
+//
+// 如果PAX记录的值为空，则保留原始USTAR字段的值。
+func mergePAX(hdr *Header, paxHdrs map[string]string) (err error) { // 注：#
+	for k, v := range paxHdrs {
+		if v == "" {
+			continue // 保留原始的USTAR值
+		}
+		var id64 int64
+		switch k {
+		case paxPath:
+			hdr.Name = v
+		case paxLinkpath:
+			hdr.Linkname = v
+		case paxUname:
+			hdr.Uname = v
+		case paxGname:
+			hdr.Gname = v
+		case paxUid:
+			id64, err = strconv.ParseInt(v, 10, 64)
+			hdr.Uid = int(id64) // 可能的整数溢出
+		case paxGid:
+			id64, err = strconv.ParseInt(v, 10, 64)
+			hdr.Gid = int(id64) // 可能的整数溢出
+		case paxAtime:
+			hdr.AccessTime, err = parsePAXTime(v)
+		case paxMtime:
+			hdr.ModTime, err = parsePAXTime(v)
+		case paxCtime:
+			hdr.ChangeTime, err = parsePAXTime(v)
+		case paxSize:
+			hdr.Size, err = strconv.ParseInt(v, 10, 64)
+		default:
+			if strings.HasPrefix(k, paxSchilyXattr) {
+				if hdr.Xattrs == nil {
+					hdr.Xattrs = make(map[string]string)
+				}
+				hdr.Xattrs[k[len(paxSchilyXattr):]] = v
+			}
+		}
+		if err != nil {
+			return decodeHeaderError(fmt.Sprintf("invalid PAX record: %q", k+" = "+v))
+		}
+	}
+	hdr.PAXRecords = paxHdrs
+	return nil
+}
+
+// parsePAX 从TypeXHeader解析PAX记录。
+// 这些记录将应用于下一个文件。
+func parsePAX(r io.Reader) (map[string]string, error) { // 注：#
+	buf, err := readSpecialFile(r)
+	if err != nil {
+		return nil, err
+	}
+	sbuf := string(buf)
+
+	// 为了支持GNU PAX稀疏格式0.0。
+	// 此函数将稀疏格式0.0的标头转换为格式0.1的标头，因为0.0的标头不符合PAX标准。
+	var sparseMap []string
+
+	paxHdrs := make(map[string]string)
+	for len(sbuf) > 0 {
+		key, value, residual, err := parsePAXRecord(sbuf)
+		if err != nil {
+			return nil, decodeHeaderError(fmt.Sprintf("malformed PAX record at %q", truncateForError(sbuf)))
+		}
+		sbuf = residual
+
+		switch key {
+		case paxGNUSparseOffset, paxGNUSparseNumBytes:
+			// 验证稀疏标头的顺序和值。
+			if (len(sparseMap)%2 == 0 && key != paxGNUSparseOffset) ||
+				(len(sparseMap)%2 == 1 && key != paxGNUSparseNumBytes) ||
+				strings.Contains(value, ",") {
+				return nil, decodeHeaderError(fmt.Sprintf("out of order or invalid %s record", key))
+			}
+			sparseMap = append(sparseMap, value)
+		default:
+			paxHdrs[key] = value
+		}
+	}
+	if len(sparseMap) > 0 {
+		paxHdrs[paxGNUSparseMap] = strings.Join(sparseMap, ",")
+	}
+	return paxHdrs, nil
+}
+
+// truncateForError 截断s，使其适合放在错误消息中。
+func truncateForError(s string) string { // 注：s超过32个字节时截断并添加"..."
+	const maxLen = 32
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
+
+// errChecksumMsg 是校验和不匹配时headerError中的问题描述。
+const errChecksumMsg = "checksum mismatch or unrecognized header block"
+
+// readHeader 从基础读取器读取下一个块头，并返回已解析的Header和原始块。
+// 原始块仅在下一次调用Next之前有效。
+// 如果遇到两个连续的零块，则返回io.EOF。
+//
+// 如果遇到格式错误的块头，返回描述问题的headerError。
+func (tr *Reader) readHeader() (*Header, *block, error) { // 注：#
+	// 两个零字节块标志着存档的结束。
+	if _, err := io.ReadFull(tr.r, tr.blk[:]); err != nil {
+		return nil, nil, err // 这里EOF可以； 正好读取0个字节
+	}
+	if bytes.Equal(tr.blk[:], zeroBlock[:]) {
+		if _, err := io.ReadFull(tr.r, tr.blk[:]); err != nil {
+			return nil, nil, err // 这里EOF可以； 正好读取1个块
+		}
+		if bytes.Equal(tr.blk[:], zeroBlock[:]) {
+			return nil, nil, io.EOF // 正常的EOF； 正好读取2个块
+		}
+		return nil, nil, decodeHeaderError("zero block followed by non-zero block") // 零块后跟非零块
+	}
+
+	// 验证标头是否与已知格式匹配。
+	format := tr.blk.GetFormat()
+	if format == FormatUnknown {
+		return nil, nil, decodeHeaderError(errChecksumMsg)
+	}
+
+	var p parser
+	hdr := new(Header)
+
+	// 解压V7标头。
+	v7 := tr.blk.V7()
+	hdr.Typeflag = v7.TypeFlag()[0]
+	hdr.Name = p.parseString(v7.Name())
+	hdr.Linkname = p.parseString(v7.LinkName())
+	hdr.Size = p.parseNumeric(v7.Size())
+	hdr.Mode = p.parseNumeric(v7.Mode())
+	hdr.Uid = int(p.parseNumeric(v7.UID()))
+	hdr.Gid = int(p.parseNumeric(v7.GID()))
+	hdr.ModTime = time.Unix(p.parseNumeric(v7.ModTime()), 0)
+
+	// 解压特定于格式的字段。
+	if format > formatV7 {
+		ustar := tr.blk.USTAR()
+		hdr.Uname = p.parseString(ustar.UserName())
+		hdr.Gname = p.parseString(ustar.GroupName())
+		hdr.Devmajor = p.parseNumeric(ustar.DevMajor())
+		hdr.Devminor = p.parseNumeric(ustar.DevMinor())
+
+		var prefix string
+		switch {
+		case format.has(FormatUSTAR | FormatPAX):
+			hdr.Format = format
+			ustar := tr.blk.USTAR()
+			prefix = p.parseString(ustar.Prefix())
+
+			// 为了进行格式检测，请检查块的格式是否正确，因为解析器比USTAR实际允许的更为宽松。
+			notASCII := func(r rune) bool { return r >= 0x80 }
+			if bytes.IndexFunc(tr.blk[:], notASCII) >= 0 {
+				hdr.Format = FormatUnknown // 块中的非ASCII字符。
+			}
+			nul := func(b []byte) bool { return int(b[len(b)-1]) == 0 }
+			if !(nul(v7.Size()) && nul(v7.Mode()) && nul(v7.UID()) && nul(v7.GID()) &&
+				nul(v7.ModTime()) && nul(ustar.DevMajor()) && nul(ustar.DevMinor())) {
+				hdr.Format = FormatUnknown // 数字字段必须以NUL结尾
+			}
+		case format.has(formatSTAR):
+			star := tr.blk.STAR()
+			prefix = p.parseString(star.Prefix())
+			hdr.AccessTime = time.Unix(p.parseNumeric(star.AccessTime()), 0)
+			hdr.ChangeTime = time.Unix(p.parseNumeric(star.ChangeTime()), 0)
+		case format.has(FormatGNU):
+			hdr.Format = format
+			var p2 parser
+			gnu := tr.blk.GNU()
+			if b := gnu.AccessTime(); b[0] != 0 {
+				hdr.AccessTime = time.Unix(p2.parseNumeric(b), 0)
+			}
+			if b := gnu.ChangeTime(); b[0] != 0 {
+				hdr.ChangeTime = time.Unix(p2.parseNumeric(b), 0)
+			}
+
+			// 在Go1.8之前，Writer有一个错误，即在某些罕见情况下会输出无效的tar文件，
+			// 因为逻辑错误地认为旧的GNU格式具有前缀字段。
+			// 这会导致atime和ctime字段被破坏。
+			//
+			// 为了继续读取由以前有问题的Go版本创建的tar文件，我们怀疑地解析atime和ctime字段。
+			// 如果我们无法解析它们并且前缀字段看起来像ASCII字符串，那么我们将退回到将这些字段视为USTAR前缀字段的Go1.8之前的行为。
+			//
+			// 参见https://golang.org/issues/12594
+			// 参见https://golang.org/issues/21005
+			if p2.err != nil {
+				hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+				ustar := tr.blk.USTAR()
+				if s := p.parseString(ustar.Prefix()); isASCII(s) {
+					prefix = s
+				}
+				hdr.Format = FormatUnknown // 有问题的文件不是GNU
+			}
+		}
+		if len(prefix) > 0 {
+			hdr.Name = prefix + "/" + hdr.Name
+		}
+	}
+	if p.err != nil {
+		return nil, nil, decodeHeaderError(fmt.Sprintf("invalid numeric field in header for %q", hdr.Name))
+	}
+	return hdr, &tr.blk, nil
+}
+
+// readOldGNUSparseMap 从旧的GNU稀疏格式读取稀疏映射。
+// 如果稀疏映射足够小，则将其存储在tar标头中。
+// 如果它大于四个条目，则使用一个或多个扩展标头来存储其余的稀疏映射。
+//
+// Header.Size不反映所使用的任何扩展标头的大小。
+// 因此，此函数将从原始io.Reader中读取以获取额外的标头。
+// 此方法在此过程中会改变blk。
+func (tr *Reader) readOldGNUSparseMap(hdr *Header, blk *block) (sparseDatas, error) { // 注：#
+	// 确保输入格式为GNU。
+	// 不幸的是，STAR格式也具有稀疏标头格式，该格式使用相同的类型标志，但具有完全不同的布局。
+	if blk.GetFormat() != FormatGNU {
+		return nil, decodeHeaderError(fmt.Sprintf("TypeGNUSparse entry %q is not in GNU format", hdr.Name))
+	}
+	hdr.Format.mayOnlyBe(FormatGNU)
+
+	var p parser
+	hdr.Size = p.parseNumeric(blk.GNU().RealSize())
+	if p.err != nil {
+		return nil, decodeHeaderError(fmt.Sprintf("invalid GNU sparse real size for %q", hdr.Name))
+	}
+	s := blk.GNU().Sparse()
+	spd := make(sparseDatas, 0, s.MaxEntries())
+	for {
+		for i := 0; i < s.MaxEntries(); i++ {
+			// 此终止条件与GNU和BSD tar相同。
+			if s.Entry(i).Offset()[0] == 0x00 {
+				break // 不要返回，需要处理扩展标头（即使为空）
+			}
+			offset := p.parseNumeric(s.Entry(i).Offset())
+			length := p.parseNumeric(s.Entry(i).Length())
+			if p.err != nil {
+				return nil, decodeHeaderError(fmt.Sprintf("invalid GNU sparse entry for %q", hdr.Name))
+			}
+			spd = append(spd, sparseEntry{Offset: offset, Length: length})
+		}
+
+		if s.IsExtended()[0] > 0 {
+			// 还有更多条目。 读取扩展标头并解析其条目。
+			if _, err := mustReadFull(tr.r, blk[:]); err != nil {
+				return nil, err
+			}
+			s = blk.Sparse()
+			continue
+		}
+		return spd, nil // 完成
+	}
+}
+
+// readGNUSparseMap1x0 读取以GNU的PAX稀疏格式版本1.0存储的稀疏映射。
+// 稀疏映射的格式由一系列以换行符终止的数字字段组成。
+// 第一个字段是条目数，并且始终存在。
+// 其后是条目，由两个字段（偏移量，长度）组成。
+// 此函数必须在包含最后一个换行符的块的结束边界处停止读取。
+//
+// 请注意，GNU手册说数字值应以八进制格式编码。
+// 但是，GNU tar实用程序本身以十进制输出这些值。
+// 因此，此库将值视为十进制编码。
+func readGNUSparseMap1x0(r io.Reader) (sparseDatas, error) { // 注：#
+	var (
+		cntNewline int64
+		buf        bytes.Buffer
+		blk        block
+	)
+
+	// feedTokens 将数据从r中按块复制到buf中，直到buf中至少有n个换行符。
+	// 它不会读取比所需更多的块。
+	feedTokens := func(n int64) error {
+		for cntNewline < n {
+			if _, err := mustReadFull(r, blk[:]); err != nil {
+				return err
+			}
+			buf.Write(blk[:])
+			for _, c := range blk {
+				if c == '\n' {
+					cntNewline++
+				}
+			}
+		}
+		return nil
+	}
+
+	// nextToken 获取由换行符分隔的下一个令牌。
+	// 这假定缓冲区中至少存在一个换行符。
+	nextToken := func() string {
+		cntNewline--
+		tok, _ := buf.ReadString('\n')
+		return strings.TrimRight(tok, "\n")
+	}
+
+	// 解析条目数。
+	// 使用抗整数溢出的数学进行检查。
+	if err := feedTokens(1); err != nil {
+		return nil, err
+	}
+	numEntries, err := strconv.ParseInt(nextToken(), 10, 0) // 故意解析为本机int
+	if err != nil || numEntries < 0 || int(2*numEntries) < int(numEntries) {
+		return nil, decodeHeaderError("invalid GNU sparse 1.0 entry count")
+	}
+
+	// 解析所有成员条目。
+	// 此后，numEntries是可信的，因为潜在的攻击者必须已经投入了与该库所用资源成比例的资源。
+	if err := feedTokens(2 * numEntries); err != nil {
+		return nil, err
+	}
+	spd := make(sparseDatas, 0, numEntries)
+	for i := int64(0); i < numEntries; i++ {
+		offset, err1 := strconv.ParseInt(nextToken(), 10, 64)
+		length, err2 := strconv.ParseInt(nextToken(), 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, decodeHeaderError("invalid GNU sparse 1.0 entry")
+		}
+		spd = append(spd, sparseEntry{Offset: offset, Length: length})
+	}
+	return spd, nil
+}
+
+// readGNUSparseMap0x1 读取以GNU的PAX稀疏格式版本0.1存储的稀疏映射。
+// 稀疏映射存储在PAX标头中。
+func readGNUSparseMap0x1(paxHdrs map[string]string) (sparseDatas, error) { // 注：#
+	// 获取条目数。
+	// 使用抗整数溢出的数学进行检查。
+	numEntriesStr := paxHdrs[paxGNUSparseNumBlocks]
+	numEntries, err := strconv.ParseInt(numEntriesStr, 10, 0) // 故意解析为本机int
+	if err != nil || numEntries < 0 || int(2*numEntries) < int(numEntries) {
+		return nil, decodeHeaderError(fmt.Sprintf("invalid %s record %q", paxGNUSparseNumBlocks, numEntriesStr))
+	}
+
+	// sparseMap中的每个条目应有两个数字。
+	sparseMap := strings.Split(paxHdrs[paxGNUSparseMap], ",")
+	if len(sparseMap) == 1 && sparseMap[0] == "" {
+		sparseMap = sparseMap[:0]
+	}
+	if int64(len(sparseMap)) != 2*numEntries {
+		return nil, decodeHeaderError(fmt.Sprintf("%s has %d values, want %d", paxGNUSparseMap, len(sparseMap), 2*numEntries))
+	}
+
+	// 遍历稀疏映射中的条目。
+	// 现在numEntries是可信的。
+	spd := make(sparseDatas, 0, numEntries)
+	for len(sparseMap) >= 2 {
+		offset, err1 := strconv.ParseInt(sparseMap[0], 10, 64)
+		length, err2 := strconv.ParseInt(sparseMap[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, decodeHeaderError(fmt.Sprintf("invalid %s entry", paxGNUSparseMap))
+		}
+		spd = append(spd, sparseEntry{Offset: offset, Length: length})
+		sparseMap = sparseMap[2:]
+	}
+	return spd, nil
+}
+
+// Read 从tar存档中的当前文件读取。
+// 当到达该文件的末尾时，它返回(0, io.EOF)，直到调用Next前进到下一个文件为止。
+//
+// 如果当前文件是稀疏的，则标记为孔的区域将作为NUL字节读回。
+//
+// 无论Header.Size声明如何，在TypeLink，TypeSymlink，TypeChar，TypeBlock，TypeDir和TypeFifo等特殊类型上调用Read都会返回(0, io.EOF)。
+func (tr *Reader) Read(b []byte) (int, error) { // 注：#
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	n, err := tr.curr.Read(b)
+	if err != nil && err != io.EOF {
+		tr.err = err
+	}
+	return n, err
+}
+
+// WriteTo 将当前文件的内容写入w。
+// 写入的字节数与Header.Size声明的数量匹配。
+//
+// 如果当前文件是稀疏的，并且w是io.WriteSeeker，
+// 则WriteTo使用Seek跳过Header.SparseHoles中定义的孔，并假定跳过的区域用NUL填充。
+// 这样写入的文件在磁盘上会保留孔，而不是写入零。
+// 这总是写入最后一个字节，以确保w是正确的大小。
+func (tr *Reader) WriteTo(w io.Writer) (int64, error) { // 注：#
+	if tr.err != nil {
+		return 0, tr.err
+	}
+	n, err := tr.curr.WriteTo(w)
+	if err != nil {
+		tr.err = err
+	}
+	return n, err
+}
+
+// regFileReader 是用于读取常规文件内容的fileReader。
+type regFileReader struct {
+	r  io.Reader // 基础Reader
+	nb int64     // 剩余要读取的字节数
+}
+
+func (fr *regFileReader) Read(b []byte) (n int, err error) { // 注：#
+	if int64(len(b)) > fr.nb {
+		b = b[:fr.nb]
+	}
+	if len(b) > 0 {
+		n, err = fr.r.Read(b)
+		fr.nb -= int64(n)
+	}
+	switch {
+	case err == io.EOF && fr.nb > 0:
+		return n, io.ErrUnexpectedEOF
+	case err == nil && fr.nb == 0:
+		return n, io.EOF
+	default:
+		return n, err
+	}
+}
+
+func (fr *regFileReader) WriteTo(w io.Writer) (int64, error) { // 注：将fr的剩余内容写入w
+	return io.Copy(w, struct{ io.Reader }{fr})
+}
+
+func (fr regFileReader) LogicalRemaining() int64 { // 注：获取剩余的逻辑字节数
+	return fr.nb
+}
+
+func (fr regFileReader) PhysicalRemaining() int64 { // 注：获取剩余的物理字节数
+	return fr.nb
+}
+
+// sparseFileReader 是用于从稀疏文件条目中读取数据的fileReader。
+type sparseFileReader struct {
+	fr  fileReader  // 基础fileReader
+	sp  sparseHoles // 规范化的稀疏孔列表
+	pos int64       // 稀疏文件中的当前位置
+}
+
+func (sr *sparseFileReader) Read(b []byte) (n int, err error) { // 注：#
+	finished := int64(len(b)) >= sr.LogicalRemaining()
+	if finished {
+		b = b[:sr.LogicalRemaining()]
+	}
+
+	b0 := b
+	endPos := sr.pos + int64(len(b))
+	for endPos > sr.pos && err == nil {
+		var nf int // 片段中读取的字节
+		holeStart, holeEnd := sr.sp[0].Offset, sr.sp[0].endOffset()
+		if sr.pos < holeStart { // 在数据片段中
+			bf := b[:min(int64(len(b)), holeStart-sr.pos)]
+			nf, err = tryReadFull(sr.fr, bf)
+		} else { // 在孔片段中
+			bf := b[:min(int64(len(b)), holeEnd-sr.pos)]
+			nf, err = tryReadFull(zeroReader{}, bf)
+		}
+		b = b[nf:]
+		sr.pos += int64(nf)
+		if sr.pos >= holeEnd && len(sr.sp) > 1 {
+			sr.sp = sr.sp[1:] // 确保最后一个片段始终保留
+		}
+	}
+
+	n = len(b0) - len(b)
+	switch {
+	case err == io.EOF:
+		return n, errMissData // 密集文件中的数据少于稀疏文件
+	case err != nil:
+		return n, err
+	case sr.LogicalRemaining() == 0 && sr.PhysicalRemaining() > 0:
+		return n, errUnrefData // 密集文件中的数据多于稀疏文件
+	case finished:
+		return n, io.EOF
+	default:
+		return n, nil
+	}
+}
+
+func (sr *sparseFileReader) WriteTo(w io.Writer) (n int64, err error) { // 注：#
+	ws, ok := w.(io.WriteSeeker)
+	if ok {
+		if _, err := ws.Seek(0, io.SeekCurrent); err != nil {
+			ok = false // 并非所有io.Seeker都能真正Seek
+		}
+	}
+	if !ok {
+		return io.Copy(w, struct{ io.Reader }{sr})
+	}
+
+	var writeLastByte bool
+	pos0 := sr.pos
+	for sr.LogicalRemaining() > 0 && !writeLastByte && err == nil {
+		var nf int64 // 片段大小
+		holeStart, holeEnd := sr.sp[0].Offset, sr.sp[0].endOffset()
+		if sr.pos < holeStart { // 在数据片段中
+			nf = holeStart - sr.pos
+			nf, err = io.CopyN(ws, sr.fr, nf)
+		} else { // 在孔片段中
+			nf = holeEnd - sr.pos
+			if sr.PhysicalRemaining() == 0 {
+				writeLastByte = true
+				nf--
+			}
+			_, err = ws.Seek(nf, io.SeekCurrent)
+		}
+		sr.pos += nf
+		if sr.pos >= holeEnd && len(sr.sp) > 1 {
+			sr.sp = sr.sp[1:] // 确保最后一个片段始终保留
+		}
+	}
+
+	// 如果最后一个片段是一个孔，则查找到EOF之前的1个字节，并写入一个字节以确保文件大小正确。
+	if writeLastByte && err == nil {
+		_, err = ws.Write([]byte{0})
+		sr.pos++
+	}
+
+	n = sr.pos - pos0
+	switch {
+	case err == io.EOF:
+		return n, errMissData // 密集文件中的数据少于稀疏文件
+	case err != nil:
+		return n, err
+	case sr.LogicalRemaining() == 0 && sr.PhysicalRemaining() > 0:
+		return n, errUnrefData // 密集文件中的数据多于稀疏文件
+	default:
+		return n, nil
+	}
+}
+
+func (sr sparseFileReader) LogicalRemaining() int64 { // 注：获取剩余的逻辑字节数
+	return sr.sp[len(sr.sp)-1].endOffset() - sr.pos
+}
+
+func (sr sparseFileReader) PhysicalRemaining() int64 { // 注：获取剩余的物理字节数
+	return sr.fr.PhysicalRemaining()
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) { // 注：将b全部置为0
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+// mustReadFull 类似于io.ReadFull，只是它将io.EOF返回为io.ErrUnexpectedEOF。
+func mustReadFull(r io.Reader, b []byte) (int, error) { // 注：#
+	n, err := tryReadFull(r, b)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// tryReadFull 类似于io.ReadFull，只是它在遇到EOF时不返回io.ErrUnexpectedEOF。
+func tryReadFull(r io.Reader, b []byte) (n int, err error) { // 注：#
+	for len(b) > n && err == nil {
+		var nn int
+		nn, err = r.Read(b[n:])
+		n += nn
+	}
+	if len(b) == n && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// readSpecialFile 就像ioutil.ReadAll一样，除了它返回ErrFieldTooLong（如果超过maxSpecialFileSize）。
+func readSpecialFile(r io.Reader) ([]byte, error) { // 注：#
+	const maxSpecialFileSize = 1 << 20
+	buf, err := ioutil.ReadAll(io.LimitReader(r, maxSpecialFileSize+1))
+	if len(buf) > maxSpecialFileSize {
+		return nil, ErrFieldTooLong
+	}
+	return buf, err
+}
+
+// discard 从r中跳过n个字节，如果无法这样做，则报告错误。
+func discard(r io.Reader, n int64) error { // 注：#
+	// 如果可能，请在数据部分结束之前查找到最后一个字节。
+	// 这样做是因为Seek经常懒于报告错误；这将掩盖流可能被截断的事实。
+	// 我们可以依靠不久之后完成的io.CopyN来触发任何IO错误。
+	var seekSkipped int64 // 通过Seek跳过的字节数
+	if sr, ok := r.(io.Seeker); ok && n > 1 {
+		// 并非所有io.Seeker都能真正Seek。
+		// 例如，os.Stdin实现io.Seeker，但是调用Seek始终返回错误并且不执行任何操作。
+		// 因此，我们尝试对当前位置进行无害的查找，以查看是否真正支持Seek。
+		pos1, err := sr.Seek(0, io.SeekCurrent)
+		if pos1 >= 0 && err == nil {
+			// 似乎支持Seek，因此请执行真正的Seek。
+			pos2, err := sr.Seek(n-1, io.SeekCurrent)
+			if pos2 < 0 || err != nil {
+				return err
+			}
+			seekSkipped = pos2 - pos1
+		}
+	}
+
+	copySkipped, err := io.CopyN(ioutil.Discard, r, n-seekSkipped)
+	if err == io.EOF && seekSkipped+copySkipped < n {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
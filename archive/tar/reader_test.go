@@ -0,0 +1,247 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	vectors := []struct {
+		file    string
+		headers []*Header
+		chksums []string
+	}{{
+		file: "testdata/ustar.tar",
+		headers: []*Header{{
+			Name:     "small.txt",
+			Typeflag: TypeReg,
+			Mode:     0644,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			Size:     5,
+			ModTime:  time.Unix(1500000000, 0),
+			Format:   FormatUSTAR,
+		}, {
+			Name:     "dir/",
+			Typeflag: TypeDir,
+			Mode:     0755,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			ModTime:  time.Unix(1500000000, 0),
+			Format:   FormatUSTAR,
+		}, {
+			Name:     "dir/link",
+			Linkname: "../small.txt",
+			Typeflag: TypeSymlink,
+			Mode:     0644,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			ModTime:  time.Unix(1500000000, 0),
+			Format:   FormatUSTAR,
+		}},
+		chksums: []string{"Kilts", "", ""},
+	}, {
+		file: "testdata/gnu-long.tar",
+		headers: []*Header{{
+			Name:     "long/" + strings.Repeat("x", 120) + "/file.txt",
+			Typeflag: TypeReg,
+			Mode:     0644,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			Size:     11,
+			ModTime:  time.Unix(1500000000, 0),
+			Format:   FormatGNU,
+		}, {
+			Name:     "hard",
+			Linkname: strings.Repeat("l", 130),
+			Typeflag: TypeLink,
+			Mode:     0644,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			ModTime:  time.Unix(1500000000, 0),
+			Format:   FormatGNU,
+		}},
+		chksums: []string{"Google.com\n", ""},
+	}, {
+		file: "testdata/pax-global.tar",
+		headers: []*Header{{
+			Name:       "././@PaxHeader",
+			Typeflag:   TypeXGlobalHeader,
+			PAXRecords: map[string]string{"GOLANG.pkg": "tar"},
+			Format:     FormatPAX,
+		}, {
+			Name:     "pax/" + strings.Repeat("y", 120) + "/name.txt",
+			Typeflag: TypeReg,
+			Mode:     0644,
+			Uid:      1000,
+			Gid:      1000,
+			Uname:    "gopher",
+			Gname:    "gopher",
+			Size:     5,
+			ModTime:  time.Unix(1500000000, 5e8),
+			Xattrs:   map[string]string{"user.key": "value"},
+			PAXRecords: map[string]string{
+				"GOLANG.pkg":            "tar",
+				"path":                  "pax/" + strings.Repeat("y", 120) + "/name.txt",
+				"mtime":                 "1500000000.5",
+				"SCHILY.xattr.user.key": "value",
+			},
+			Format: FormatPAX,
+		}, {
+			Name:       "second.txt",
+			Typeflag:   TypeReg,
+			Mode:       0644,
+			Uid:        1000,
+			Gid:        1000,
+			Uname:      "gopher",
+			Gname:      "gopher",
+			ModTime:    time.Unix(1500000000, 0),
+			PAXRecords: map[string]string{"GOLANG.pkg": "tar"},
+			Format:     FormatPAX,
+		}},
+		chksums: []string{"", "hello", ""},
+	}}
+
+	for _, v := range vectors {
+		t.Run(v.file, func(t *testing.T) {
+			f, err := os.Open(v.file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer f.Close()
+
+			tr := NewReader(f)
+			for i := 0; ; i++ {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					if i != len(v.headers) {
+						t.Fatalf("got %d headers, want %d", i, len(v.headers))
+					}
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next() #%d: unexpected error: %v", i, err)
+				}
+				if i >= len(v.headers) {
+					t.Fatalf("unexpected extra header: %+v", hdr)
+				}
+				if !equalHeader(*hdr, *v.headers[i]) {
+					t.Errorf("Next() #%d:\ngot  %+v\nwant %+v", i, *hdr, *v.headers[i])
+				}
+				b, err := ioutil.ReadAll(tr)
+				if err != nil {
+					t.Fatalf("Read #%d: unexpected error: %v", i, err)
+				}
+				if string(b) != v.chksums[i] {
+					t.Errorf("Read #%d: got %q, want %q", i, b, v.chksums[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReaderMalformed(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/ustar.tar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Corrupt the checksum of the first header.
+	bad := append([]byte(nil), data...)
+	bad[148] = 'X'
+	if _, err := NewReader(bytes.NewReader(bad)).Next(); !errors.Is(err, ErrHeader) {
+		t.Errorf("Next() with bad checksum: got %v, want ErrHeader", err)
+	}
+
+	// Truncate in the middle of the first file's data.
+	tr := NewReader(bytes.NewReader(data[:blockSize+2]))
+	if _, err := tr.Next(); err != nil {
+		t.Fatalf("Next(): unexpected error: %v", err)
+	}
+	if _, err := ioutil.ReadAll(tr); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadAll on truncated file: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestReaderGlobalPAX(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewWriter(&buf)
+	global := &Header{Typeflag: TypeXGlobalHeader, PAXRecords: map[string]string{
+		paxPath:  "global",
+		paxSize:  "100",
+		paxUname: "gopher",
+		paxMtime: "1000",
+	}}
+	if err := tw.WriteHeader(global); err != nil {
+		t.Fatalf("WriteHeader(global): %v", err)
+	}
+	if err := tw.WriteHeader(&Header{Name: "file", Mode: 0644, Size: 1, Format: FormatPAX}); err != nil {
+		t.Fatalf("WriteHeader(file): %v", err)
+	}
+	tw.Write([]byte("x"))
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	tr := NewReader(&buf)
+	if _, err := tr.Next(); err != nil {
+		t.Fatalf("Next(global): %v", err)
+	}
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Next(file): %v", err)
+	}
+	if hdr.Name != "file" || hdr.Size != 1 {
+		t.Errorf("Next() = {Name: %q, Size: %d}, want {Name: \"file\", Size: 1}", hdr.Name, hdr.Size)
+	}
+	if hdr.Uname != "gopher" || hdr.ModTime.Unix() != 1000 {
+		t.Errorf("Next() = {Uname: %q, ModTime: %d}, want global uname and mtime", hdr.Uname, hdr.ModTime.Unix())
+	}
+	if b, err := ioutil.ReadAll(tr); err != nil || string(b) != "x" {
+		t.Errorf("ReadAll() = %q, %v, want \"x\", nil", b, err)
+	}
+}
+
+func TestParsePAXMalformed(t *testing.T) {
+	_, err := parsePAX(strings.NewReader("13 key1=haha\n13 bad"))
+	if _, ok := err.(headerError); !ok || !errors.Is(err, ErrHeader) {
+		t.Errorf("parsePAX(): got %v, want headerError matching ErrHeader", err)
+	}
+}
+
+// equalHeader reports whether the two headers are semantically equal.
+func equalHeader(x, y Header) bool {
+	if !x.ModTime.Equal(y.ModTime) || !x.AccessTime.Equal(y.AccessTime) || !x.ChangeTime.Equal(y.ChangeTime) {
+		return false
+	}
+	x.ModTime, x.AccessTime, x.ChangeTime = time.Time{}, time.Time{}, time.Time{}
+	y.ModTime, y.AccessTime, y.ChangeTime = time.Time{}, time.Time{}, time.Time{}
+	if len(x.Xattrs) == 0 && len(y.Xattrs) == 0 {
+		x.Xattrs, y.Xattrs = nil, nil
+	}
+	if len(x.PAXRecords) == 0 && len(y.PAXRecords) == 0 {
+		x.PAXRecords, y.PAXRecords = nil, nil
+	}
+	return reflect.DeepEqual(x, y)
+}
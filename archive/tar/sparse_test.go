@@ -0,0 +1,187 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// sparseImage returns the logical content of testdata/*-sparse*.tar:
+// a 1MiB file with "hello" at 4096 and "world" at 512KiB.
+func sparseImage() []byte {
+	b := make([]byte, 1<<20)
+	copy(b[4096:], "hello")
+	copy(b[512<<10:], "world")
+	return b
+}
+
+func TestReadSparse(t *testing.T) {
+	want := sparseImage()
+	for _, file := range []string{
+		"testdata/gnu-sparse.tar",
+		"testdata/pax-sparse-0.0.tar",
+		"testdata/pax-sparse-0.1.tar",
+		"testdata/pax-sparse-1.0.tar",
+	} {
+		t.Run(file, func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer f.Close()
+
+			tr := NewReader(f)
+			hdr, err := tr.Next()
+			if err != nil {
+				t.Fatalf("Next() = %v", err)
+			}
+			if hdr.Name != "sparse.img" || hdr.Size != int64(len(want)) {
+				t.Errorf("Next() = (%q, %d), want (%q, %d)", hdr.Name, hdr.Size, "sparse.img", len(want))
+			}
+			if len(hdr.SparseHoles) == 0 || !validateSparseEntries(hdr.SparseHoles, hdr.Size) {
+				t.Errorf("SparseHoles = %v, want valid non-empty holes", hdr.SparseHoles)
+			}
+			got, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("ReadAll() = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("sparse file content mismatch")
+			}
+			if _, err := tr.Next(); err != io.EOF {
+				t.Errorf("Next() = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriteSparse(t *testing.T) {
+	want := sparseImage()
+	holes := []SparseEntry{{0, 4096}, {4096 + 512, 512<<10 - 4096 - 512}, {512<<10 + 512, 512<<10 - 512}}
+	for _, hdr := range []Header{
+		{Name: "pax.img", Typeflag: TypeReg, Size: int64(len(want)), SparseHoles: holes},
+		{Name: "gnu.img", Typeflag: TypeGNUSparse, Size: int64(len(want)), SparseHoles: holes},
+	} {
+		t.Run(hdr.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := NewWriter(&buf)
+			if err := tw.WriteHeader(&hdr); err != nil {
+				t.Fatalf("WriteHeader() = %v", err)
+			}
+			if _, err := tw.ReadFrom(bytes.NewReader(want)); err != nil {
+				t.Fatalf("ReadFrom() = %v", err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			if buf.Len() >= len(want) {
+				t.Errorf("archive size %d, want less than logical size %d", buf.Len(), len(want))
+			}
+
+			tr := NewReader(&buf)
+			got, err := tr.Next()
+			if err != nil {
+				t.Fatalf("Next() = %v", err)
+			}
+			if got.Name != hdr.Name || got.Size != hdr.Size || !reflect.DeepEqual(got.SparseHoles, holes) {
+				t.Errorf("Next() = (%q, %d, %v), want (%q, %d, %v)", got.Name, got.Size, got.SparseHoles, hdr.Name, hdr.Size, holes)
+			}
+			b, err := ioutil.ReadAll(tr)
+			if err != nil || !bytes.Equal(b, want) {
+				t.Errorf("ReadAll() = (len %d, %v), want matching content", len(b), err)
+			}
+		})
+	}
+
+	t.Run("WriteHole", func(t *testing.T) {
+		tw := NewWriter(new(bytes.Buffer))
+		if err := tw.WriteHeader(&Header{Name: "h", Size: 1024, SparseHoles: []SparseEntry{{0, 512}}}); err != nil {
+			t.Fatalf("WriteHeader() = %v", err)
+		}
+		if _, err := tw.Write([]byte("x")); err != errWriteHole {
+			t.Errorf("Write() = %v, want %v", err, errWriteHole)
+		}
+	})
+}
+
+func TestSparseFileRoundTrip(t *testing.T) {
+	if sysSparseDetect == nil {
+		t.Skip("sparse detection not supported on this platform")
+	}
+	dir, err := ioutil.TempDir("", "tar-sparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := sparseImage()
+	src, err := os.Create(dir + "/src.img")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if err := src.Truncate(int64(len(want))); err != nil {
+		t.Fatal(err)
+	}
+	for _, off := range []int64{4096, 512 << 10} {
+		if _, err := src.WriteAt(want[off:off+5], off); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hdr := &Header{Name: "src.img", Typeflag: TypeReg, Size: int64(len(want))}
+	if err := hdr.DetectSparseHoles(src); err != nil {
+		t.Fatalf("DetectSparseHoles() = %v", err)
+	}
+	if len(hdr.SparseHoles) == 0 {
+		t.Skip("filesystem does not report holes")
+	}
+
+	var buf bytes.Buffer
+	tw := NewWriter(&buf)
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("WriteHeader() = %v", err)
+	}
+	if _, err := tw.ReadFrom(src); err != nil {
+		t.Fatalf("ReadFrom() = %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	tr := NewReader(&buf)
+	got, err := tr.Next()
+	if err != nil {
+		t.Fatalf("Next() = %v", err)
+	}
+	dst, err := os.Create(dir + "/dst.img")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if err := got.PunchSparseHoles(dst); err != nil {
+		t.Fatalf("PunchSparseHoles() = %v", err)
+	}
+	if _, err := tr.WriteTo(dst); err != nil {
+		t.Fatalf("WriteTo() = %v", err)
+	}
+	b, err := ioutil.ReadFile(dir + "/dst.img")
+	if err != nil || !bytes.Equal(b, want) {
+		t.Fatalf("extracted content mismatch (len %d, err %v)", len(b), err)
+	}
+
+	check := &Header{Typeflag: TypeReg}
+	if err := check.DetectSparseHoles(dst); err != nil {
+		t.Fatalf("DetectSparseHoles() = %v", err)
+	}
+	if len(check.SparseHoles) == 0 {
+		t.Errorf("extracted file has no holes")
+	}
+}
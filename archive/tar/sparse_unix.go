@@ -0,0 +1,74 @@
+// 版权所有2017 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux darwin dragonfly freebsd openbsd netbsd solaris
+
+package tar
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func init() {
+	sysSparseDetect = sparseDetectUnix
+}
+
+// sparseDetectUnix 使用SEEK_DATA和SEEK_HOLE在f中查找孔。
+func sparseDetectUnix(f *os.File) (sph sparseHoles, err error) { // 注：#
+	// SEEK_DATA和SEEK_HOLE起源于Solaris，并且已经被添加到大多数其他主要的Unix系统中。
+	var seekData, seekHole = 3, 4 // 来自unistd.h的SEEK_DATA/SEEK_HOLE
+
+	if runtime.GOOS == "darwin" {
+		// 与所有其他UNIX相比，Darwin交换了这两个常量。
+		seekData, seekHole = 4, 3
+	}
+
+	// 检查是否支持seekData/seekHole。
+	// 当不支持时，不同的操作系统和文件系统返回的errno可能不同。
+	// 与其对每个可能表示"不支持"的errno进行特殊处理，不如假设非nil错误意味着不支持seekData/seekHole。
+	if _, err := f.Seek(0, seekHole); err != nil {
+		return nil, nil
+	}
+
+	// 填充SparseHoles。
+	var last, pos int64 = -1, 0
+	for {
+		// 获取下一个孔片段的位置。
+		if pos, err = fseek(f, pos, seekHole); pos == last || err != nil {
+			return sph, err
+		}
+		offset := pos
+		last = pos
+
+		// 获取下一个数据片段的位置。
+		if pos, err = fseek(f, pos, seekData); pos == last || err != nil {
+			return sph, err
+		}
+		length := pos - offset
+		last = pos
+
+		if length > 0 {
+			sph = append(sph, SparseEntry{offset, length})
+		}
+	}
+}
+
+func fseek(f *os.File, pos int64, whence int) (int64, error) { // 注：#
+	pos, err := f.Seek(pos, whence)
+	if errno(err) == syscall.ENXIO {
+		// 当超过最后一个数据片段时，SEEK_DATA返回ENXIO，这使得确定最后一个孔的大小变得困难。
+		pos, err = f.Seek(0, io.SeekEnd)
+	}
+	return pos, err
+}
+
+func errno(err error) error { // 注：获取err的底层错误
+	if perr, ok := err.(*os.PathError); ok {
+		return perr.Err
+	}
+	return err
+}
@@ -0,0 +1,20 @@
+// 版权所有2012 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build darwin freebsd
+
+package tar
+
+import (
+	"syscall"
+	"time"
+)
+
+func statAtime(st *syscall.Stat_t) time.Time { // 注：获取st的访问时间
+	return time.Unix(st.Atimespec.Unix())
+}
+
+func statCtime(st *syscall.Stat_t) time.Time { // 注：获取st的更改时间
+	return time.Unix(st.Ctimespec.Unix())
+}
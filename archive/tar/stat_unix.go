@@ -0,0 +1,78 @@
+// 版权所有2012 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux darwin freebsd
+
+package tar
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+func init() {
+	sysStat = statUnix
+}
+
+// userMap 和 groupMap 出于性能原因缓存UID和GID查找。
+// 缺点是操作系统对uname或gname的重命名永远不会生效。
+var userMap, groupMap sync.Map // map[int]string
+
+func statUnix(fi os.FileInfo, h *Header) error { // 注：使用fi中的系统相关字段填充h
+	sys, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	h.Uid = int(sys.Uid)
+	h.Gid = int(sys.Gid)
+
+	// 尽力填充Uname和Gname。
+	// os/user函数可能由于多种原因而失败（在该平台上未实现，未启用cgo等）。
+	if u, ok := userMap.Load(h.Uid); ok {
+		h.Uname = u.(string)
+	} else if u, err := user.LookupId(strconv.Itoa(h.Uid)); err == nil {
+		h.Uname = u.Username
+		userMap.Store(h.Uid, h.Uname)
+	}
+	if g, ok := groupMap.Load(h.Gid); ok {
+		h.Gname = g.(string)
+	} else if g, err := user.LookupGroupId(strconv.Itoa(h.Gid)); err == nil {
+		h.Gname = g.Name
+		groupMap.Store(h.Gid, h.Gname)
+	}
+
+	h.AccessTime = statAtime(sys)
+	h.ChangeTime = statCtime(sys)
+
+	// 尽力填充Devmajor和Devminor。
+	if h.Typeflag == TypeChar || h.Typeflag == TypeBlock {
+		dev := uint64(sys.Rdev) // 可能是int32或uint32
+		switch runtime.GOOS {
+		case "linux":
+			// 从golang.org/x/sys/unix/dev_linux.go复制。
+			major := uint32((dev & 0x00000000000fff00) >> 8)
+			major |= uint32((dev & 0xfffff00000000000) >> 32)
+			minor := uint32((dev & 0x00000000000000ff) >> 0)
+			minor |= uint32((dev & 0x00000ffffff00000) >> 12)
+			h.Devmajor, h.Devminor = int64(major), int64(minor)
+		case "darwin":
+			// 从golang.org/x/sys/unix/dev_darwin.go复制。
+			major := uint32((dev >> 24) & 0xff)
+			minor := uint32(dev & 0xffffff)
+			h.Devmajor, h.Devminor = int64(major), int64(minor)
+		case "freebsd":
+			// 从golang.org/x/sys/unix/dev_freebsd.go复制。
+			major := uint32((dev >> 8) & 0xff)
+			minor := uint32(dev & 0xffff00ff)
+			h.Devmajor, h.Devminor = int64(major), int64(minor)
+		default:
+			// TODO: 实现其他操作系统。
+		}
+	}
+	return nil
+}
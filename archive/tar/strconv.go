@@ -0,0 +1,326 @@
+// 版权所有2016 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hasNUL 报告NUL字符是否存在于s中。
+func hasNUL(s string) bool { // 注：获取s中是否包含'\x00'
+	return strings.IndexByte(s, 0) >= 0
+}
+
+// isASCII 报告输入是否为ASCII C样式的字符串。
+func isASCII(s string) bool { // 注：获取s是否全部由非NUL的ASCII字符组成
+	for _, c := range s {
+		if c >= 0x80 || c == 0x00 {
+			return false
+		}
+	}
+	return true
+}
+
+// toASCII 将输入转换为ASCII C样式的字符串。
+// 这是尽力而为的转换，因此无效字符将被删除。
+func toASCII(s string) string { // 注：删除s中的非ASCII字符与NUL字符
+	if isASCII(s) {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c < 0x80 && c != 0x00 {
+			b = append(b, byte(c))
+		}
+	}
+	return string(b)
+}
+
+type parser struct {
+	err error // 最后发生的错误
+}
+
+type formatter struct {
+	err error // 最后发生的错误
+}
+
+// parseString 将字节解析为NUL终止的C样式字符串。
+// 如果不存在NUL字节，则返回整个输入作为字符串。
+func (*parser) parseString(b []byte) string { // 注：获取b中第一个NUL之前的字符串
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return string(b[:i])
+	}
+	return string(b)
+}
+
+// formatString 将s复制到b中，如果可能的话，以NUL终止。
+func (f *formatter) formatString(b []byte, s string) { // 注：将s写入b，s过长时设置错误
+	if len(s) > len(b) {
+		f.err = ErrFieldTooLong
+	}
+	copy(b, s)
+	if len(s) < len(b) {
+		b[len(s)] = 0
+	}
+
+	// 某些缓冲区字段（例如V7的Name）没有用于NUL的空间，因此需要截断，以确保GNU tar不会将其解释为USTAR格式的前缀。
+	if len(s) > len(b) && b[len(b)-1] == '/' {
+		n := len(strings.TrimRight(s[:len(b)], "/"))
+		b[n] = 0 // 用NUL替换尾随斜杠以终止字符串
+	}
+}
+
+// fitsInBase256 报告x是否可以用n个字节编码为base-256。
+// 如果可能用n个字节编码为八进制，则首选八进制，因为它比base-256更容易被其他tar阅读器理解。
+//
+// 我们使用base-256编码的二进制补码编码负数，其中第一个字节的最高位为1（标记），次高位表示符号（负数为1，非负数为0）。
+func fitsInBase256(n int, x int64) bool { // 注：获取x能否以n个字节进行base-256编码
+	binBits := uint(n-1) * 8
+	return n >= 9 || (x >= -1<<binBits && x < 1<<binBits)
+}
+
+// parseNumeric 将输入解析为以base-256或八进制编码的数字。
+// 如果发生解析错误，则设置p.err。
+func (p *parser) parseNumeric(b []byte) int64 { // 注：以base-256或八进制解析b
+	// 检查base-256（二进制）格式。
+	// 如果第一位置位，则所有以下位构成二进制补码的大端数字。
+	// 通过识别这一点，我们可以正确处理有符号数字。
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		// 处理符号位的负数。
+		// 以二进制补码形式表示负数时，通过反转所有的位来处理。
+		var inv byte // 0x00（正数）或0xff（负数）
+		if b[0]&0x40 != 0 {
+			inv = 0xff
+		}
+
+		var x uint64
+		for i, c := range b {
+			c ^= inv // 反转位，如果为负数
+			if i == 0 {
+				c &= 0x7f // 忽略标记位的信号位
+			}
+			if (x >> 56) > 0 {
+				p.err = ErrHeader // 整数溢出
+				return 0
+			}
+			x = x<<8 | uint64(c)
+		}
+		if (x >> 63) > 0 {
+			p.err = ErrHeader // 整数溢出
+			return 0
+		}
+		if inv == 0xff {
+			return ^int64(x)
+		}
+		return int64(x)
+	}
+
+	// 正常情况是八进制格式。
+	return p.parseOctal(b)
+}
+
+// formatNumeric 将x编码到b中，如果x适合则使用八进制编码，否则使用base-256编码。
+func (f *formatter) formatNumeric(b []byte, x int64) { // 注：将x编码写入b
+	if fitsInOctal(len(b), x) {
+		f.formatOctal(b, x)
+		return
+	}
+
+	if fitsInBase256(len(b), x) {
+		for i := len(b) - 1; i >= 0; i-- {
+			b[i] = byte(x)
+			x >>= 8
+		}
+		b[0] |= 0x80 // 突出显示最高位
+		return
+	}
+
+	f.formatOctal(b, 0) // 最后的尝试
+	f.err = ErrFieldTooLong
+}
+
+// parseOctal 将b解析为八进制数字。
+func (p *parser) parseOctal(b []byte) int64 { // 注：以八进制解析b
+	// 由于向后兼容的原因，八进制值由空格和NUL字节填充和终止。
+	// 通过逆转提取的所有内容来解决这个问题。
+	b = bytes.Trim(b, " \x00")
+
+	if len(b) == 0 {
+		return 0
+	}
+	x, perr := strconv.ParseUint(p.parseString(b), 8, 64)
+	if perr != nil {
+		p.err = ErrHeader
+	}
+	return int64(x)
+}
+
+func (f *formatter) formatOctal(b []byte, x int64) { // 注：将x以八进制写入b
+	if !fitsInOctal(len(b), x) {
+		x = 0 // 最后的尝试
+		f.err = ErrFieldTooLong
+	}
+
+	s := strconv.FormatInt(x, 8)
+	// 以零开头，但保留NUL的空间。
+	if n := len(b) - len(s) - 1; n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+	f.formatString(b, s)
+}
+
+// fitsInOctal 报告整数x是否适合长度为n的字段（使用八进制编码，带有NUL终止符）。
+func fitsInOctal(n int, x int64) bool { // 注：获取x能否以n个字节进行八进制编码
+	octBits := uint(n-1) * 3
+	return x >= 0 && (n >= 22 || x < 1<<octBits)
+}
+
+// parsePAXTime 将PAX时间（例如"1350244992.023960108"）解析为time.Time值。
+// 小数部分使用纳秒级精度，其余的被截断。
+func parsePAXTime(s string) (time.Time, error) { // 注：解析PAX时间s
+	const maxNanoSecondDigits = 9
+
+	// 拆分为秒和亚秒部分。
+	ss, sn := s, ""
+	if pos := strings.IndexByte(s, '.'); pos >= 0 {
+		ss, sn = s[:pos], s[pos+1:]
+	}
+
+	// 解析秒部分。
+	secs, err := strconv.ParseInt(ss, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrHeader
+	}
+	if len(sn) == 0 {
+		return time.Unix(secs, 0), nil // 没有亚秒值
+	}
+
+	// 解析纳秒部分。
+	if strings.Trim(sn, "0123456789") != "" {
+		return time.Time{}, ErrHeader
+	}
+	if len(sn) < maxNanoSecondDigits {
+		sn += strings.Repeat("0", maxNanoSecondDigits-len(sn)) // 右侧补零
+	} else {
+		sn = sn[:maxNanoSecondDigits] // 右侧截断
+	}
+	nsecs, _ := strconv.ParseInt(sn, 10, 64) // 必定成功
+	if len(ss) > 0 && ss[0] == '-' {
+		return time.Unix(secs, -1*nsecs), nil // 负数校正
+	}
+	return time.Unix(secs, nsecs), nil
+}
+
+// formatPAXTime 将ts转换为"秒.纳秒"格式的时间。
+func formatPAXTime(ts time.Time) (s string) { // 注：将ts格式化为PAX时间
+	secs, nsecs := ts.Unix(), ts.Nanosecond()
+	if nsecs == 0 {
+		return strconv.FormatInt(secs, 10)
+	}
+
+	// 如果是负数，则需要将秒数校正为零。
+	sign := ""
+	if secs < 0 {
+		sign = "-"             // 记住符号
+		secs = -(secs + 1)     // 为零补足秒数
+		nsecs = -(nsecs - 1e9) // 为零补足纳秒数
+	}
+	return strings.TrimRight(fmt.Sprintf("%s%d.%09d", sign, secs, nsecs), "0")
+}
+
+// parsePAXRecord 解析s中的第一个PAX记录。
+// 它以字符串形式返回记录的键和值以及未解析的s的剩余部分。
+func parsePAXRecord(s string) (k, v, r string, err error) { // 注：#
+	// 大小字段以第一个空格结尾。
+	sp := strings.IndexByte(s, ' ')
+	if sp == -1 {
+		return "", "", s, ErrHeader
+	}
+
+	// 解析第一个令牌作为十进制整数。
+	n, perr := strconv.ParseInt(s[:sp], 10, 0) // 故意解析为本机int
+	if perr != nil || n < 5 || int64(len(s)) < n {
+		return "", "", s, ErrHeader
+	}
+
+	// 提取除长度前缀以外的所有内容。
+	rec, nl, rem := s[sp+1:n-1], s[n-1:n], s[n:]
+	if nl != "\n" {
+		return "", "", s, ErrHeader
+	}
+
+	// 第一个等号分隔键和值。
+	eq := strings.IndexByte(rec, '=')
+	if eq == -1 {
+		return "", "", s, ErrHeader
+	}
+	k, v = rec[:eq], rec[eq+1:]
+
+	if !validPAXRecord(k, v) {
+		return "", "", s, ErrHeader
+	}
+	return k, v, rem, nil
+}
+
+// formatPAXRecord 使用"%d %s=%s\n"格式格式化单个PAX记录，并添加长度前缀。
+func formatPAXRecord(k, v string) (string, error) { // 注：#
+	if !validPAXRecord(k, v) {
+		return "", ErrHeader
+	}
+
+	const padding = 3 // 额外的填充，用于' '，'='和'\n'
+	size := len(k) + len(v) + padding
+	size += len(strconv.Itoa(size))
+	record := strconv.Itoa(size) + " " + k + "=" + v + "\n"
+
+	// 最终调整，如果添加大小字段会使大小增加一位。
+	if len(record) != size {
+		size = len(record)
+		record = strconv.Itoa(size) + " " + k + "=" + v + "\n"
+	}
+	return record, nil
+}
+
+// validPAXRecord 报告键值对对于PAX记录是否有效，其中：
+// 键和值都必须是有效的UTF-8字符串（此处未检查），
+// 键不能包含'='或NUL字符，
+// 值不能包含NUL字符，除非是以"SCHILY.xattr."为前缀的键，
+// 因为它们的值可能是任意二进制数据。
+func validPAXRecord(k, v string) bool { // 注：获取k与v是否为有效的PAX记录
+	if k == "" || strings.IndexByte(k, '=') >= 0 {
+		return false
+	}
+	switch k {
+	case paxPath, paxLinkpath, paxUname, paxGname:
+		return !hasNUL(v)
+	default:
+		return !hasNUL(k)
+	}
+}
+
+// splitUSTARPath 将路径名称拆分为前缀和后缀，以便可以使用USTAR格式进行编码。
+// 如果无法拆分路径，则ok为false。
+func splitUSTARPath(name string) (prefix, suffix string, ok bool) { // 注：#
+	length := len(name)
+	if length <= nameSize || !isASCII(name) {
+		return "", "", false
+	} else if length > prefixSize+1 {
+		length = prefixSize + 1
+	} else if name[length-1] == '/' {
+		length--
+	}
+
+	i := strings.LastIndex(name[:length], "/")
+	nlen := len(name) - i - 1 // nlen是名称的长度
+	plen := i                 // plen是前缀的长度
+	if i <= 0 || nlen > nameSize || nlen == 0 || plen > prefixSize {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
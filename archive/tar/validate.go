@@ -0,0 +1,242 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProblemKind 是Validate报告的问题的类别。
+type ProblemKind int
+
+// ProblemKind的值。
+const (
+	// ProblemHeader 表示无法解码的标头（校验和不匹配除外）。
+	// 由于无法确定条目的大小，Validate在此之后停止。
+	ProblemHeader ProblemKind = iota + 1
+
+	// ProblemChecksum 表示标头块的校验和不匹配或无法识别该块。
+	// Validate在此之后停止。
+	ProblemChecksum
+
+	// ProblemTruncated 表示存档在标头或数据的中间结束，
+	// 或者缺少由两个零块组成的存档结束标记。
+	ProblemTruncated
+
+	// ProblemNonPortableName 表示名称为空、是绝对路径、包含".."元素，
+	// 或包含POSIX可移植文件名字符集（A-Z a-z 0-9 . _ -）之外的字符。
+	ProblemNonPortableName
+
+	// ProblemDuplicate 表示名称（忽略尾部斜杠）与之前的条目相同。
+	ProblemDuplicate
+
+	// ProblemPAXConflict 表示PAX记录与同一条目的USTAR标头字段相矛盾。
+	ProblemPAXConflict
+
+	// ProblemFormat 表示条目无法以请求的格式表示。
+	ProblemFormat
+)
+
+var problemKindNames = []string{
+	ProblemHeader:          "invalid header",
+	ProblemChecksum:        "checksum mismatch",
+	ProblemTruncated:       "truncated",
+	ProblemNonPortableName: "non-portable name",
+	ProblemDuplicate:       "duplicate name",
+	ProblemPAXConflict:     "PAX/USTAR conflict",
+	ProblemFormat:          "not representable",
+}
+
+func (k ProblemKind) String() string { // 注：获取k的名称
+	if k > 0 && int(k) < len(problemKindNames) {
+		return problemKindNames[k]
+	}
+	return "ProblemKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Problem 描述Validate在存档中发现的一个问题。
+type Problem struct {
+	Kind   ProblemKind
+	Offset int64  // 相关条目的第一个标头块在存档中的偏移量
+	Name   string // 相关条目的名称；如果无法解码标头，则为空
+	Detail string // 可读的描述
+}
+
+func (p Problem) String() string { // 注：输出"offset 1024: "name": kind: detail"
+	return fmt.Sprintf("offset %d: %q: %v: %s", p.Offset, p.Name, p.Kind, p.Detail)
+}
+
+// Validate 读取r中的整个存档并报告它发现的每个问题，而不是在第一个问题处停止。
+// 如果format不为FormatUnknown，则还报告无法以该格式表示的每个条目。
+//
+// 返回的错误仅用于与存档内容无关的读取错误；
+// 格式错误和截断作为Problem报告。
+func Validate(r io.Reader, format Format) ([]Problem, error) { // 注：#
+	cr := &countReader{r: r}
+	tr := NewReader(cr)
+	var problems []Problem
+	seen := make(map[string]bool)
+	var offset int64     // 下一个条目的第一个标头块的偏移量
+	var prevOffset int64 // 上一个条目的偏移量
+	var prevName string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			// Reader在零个或一个零块之后遇到EOF时也返回io.EOF。
+			if cr.n-offset < 2*blockSize {
+				problems = append(problems, Problem{ProblemTruncated, offset, "", "missing end-of-archive marker"})
+			}
+			break
+		}
+		if err != nil {
+			p := Problem{Offset: offset, Detail: err.Error()}
+			switch he, ok := err.(headerError); {
+			case err == io.ErrUnexpectedEOF:
+				// 截断可能发生在上一个条目的数据中，也可能发生在此条目的标头中。
+				p.Kind, p.Offset, p.Name = ProblemTruncated, prevOffset, prevName
+			case ok && he.decode && len(he.problems) == 1 && he.problems[0] == errChecksumMsg:
+				p.Kind = ProblemChecksum
+			case ok && he.decode || err == ErrHeader:
+				p.Kind = ProblemHeader
+			default:
+				return problems, err
+			}
+			problems = append(problems, p)
+			break
+		}
+
+		report := func(kind ProblemKind, detail string) {
+			problems = append(problems, Problem{kind, offset, hdr.Name, detail})
+		}
+		if hdr.Typeflag != TypeXGlobalHeader {
+			if why := nonPortableName(hdr.Name); why != "" {
+				report(ProblemNonPortableName, why)
+			}
+			key := path.Clean(strings.TrimRight(hdr.Name, "/"))
+			if seen[key] {
+				report(ProblemDuplicate, "name appears earlier in the archive")
+			}
+			seen[key] = true
+			for _, c := range paxConflicts(&tr.ustarHdr, tr.paxHdrs) {
+				report(ProblemPAXConflict, c)
+			}
+		}
+		if format != FormatUnknown {
+			if err := representable(hdr, format); err != nil {
+				report(ProblemFormat, err.Error())
+			}
+		}
+
+		prevOffset, prevName = offset, hdr.Name
+		offset = cr.n + tr.curr.PhysicalRemaining() + tr.pad
+	}
+	return problems, nil
+}
+
+// nonPortableName 返回名称不可移植的原因，如果名称可移植，则返回空字符串。
+func nonPortableName(name string) string { // 注：#
+	switch {
+	case name == "":
+		return "empty name"
+	case strings.HasPrefix(name, "/"):
+		return "absolute path"
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return `path contains ".." element`
+		}
+	}
+	for _, c := range name {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '.', c == '_', c == '-', c == '/':
+		default:
+			return fmt.Sprintf("character %q outside the portable filename character set", c)
+		}
+	}
+	return ""
+}
+
+// paxConflicts 返回PAX记录与USTAR标头字段相矛盾的描述。
+// 如果USTAR字段为空或为零（写入器在值无法表示时常常这样做），
+// 或者字符串字段是PAX值被截断或转换为ASCII的结果，则不认为存在矛盾。
+func paxConflicts(ustar *Header, paxHdrs map[string]string) []string { // 注：#
+	var conflicts []string
+	checkString := func(key, field, v string) {
+		if pv, ok := paxHdrs[key]; ok && v != "" && !strings.HasPrefix(toASCII(pv), v) {
+			conflicts = append(conflicts, fmt.Sprintf("%s record %q conflicts with %s field %q", key, pv, field, v))
+		}
+	}
+	checkNumeric := func(key, field string, v int64) {
+		pv, ok := paxHdrs[key]
+		if !ok || v == 0 {
+			return
+		}
+		var n int64
+		var err error
+		if key == paxMtime {
+			var t time.Time
+			t, err = parsePAXTime(pv)
+			n = t.Unix()
+		} else {
+			n, err = strconv.ParseInt(pv, 10, 64)
+		}
+		if err == nil && n != v {
+			conflicts = append(conflicts, fmt.Sprintf("%s record %q conflicts with %s field %d", key, pv, field, v))
+		}
+	}
+	checkString(paxPath, "name", ustar.Name)
+	checkString(paxLinkpath, "linkname", ustar.Linkname)
+	checkString(paxUname, "uname", ustar.Uname)
+	checkString(paxGname, "gname", ustar.Gname)
+	checkNumeric(paxSize, "size", ustar.Size)
+	checkNumeric(paxUid, "uid", int64(ustar.Uid))
+	checkNumeric(paxGid, "gid", int64(ustar.Gid))
+	if !ustar.ModTime.IsZero() {
+		checkNumeric(paxMtime, "mtime", ustar.ModTime.Unix())
+	}
+	return conflicts
+}
+
+// representable 报告hdr为什么不能以format格式写入。
+func representable(hdr *Header, format Format) error { // 注：#
+	h := *hdr
+	h.Format = format
+	// Reader会将已解码的记录保留在PAXRecords中，但Writer会从Header字段重新生成它们。
+	h.PAXRecords = nil
+	for k, v := range hdr.PAXRecords {
+		if hdr.Typeflag == TypeXGlobalHeader || (!basicKeys[k] && !strings.HasPrefix(k, paxGNUSparse)) {
+			if h.PAXRecords == nil {
+				h.PAXRecords = make(map[string]string)
+			}
+			h.PAXRecords[k] = v
+		}
+	}
+	if h.Typeflag == TypeXGlobalHeader {
+		if format.has(FormatPAX) {
+			return nil
+		}
+		return encodeHeaderError("only PAX supports TypeXGlobalHeader")
+	}
+	_, _, err := h.allowedFormats()
+	return err
+}
+
+// countReader 是记录已读取字节数的io.Reader。
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countReader) Read(b []byte) (int, error) { // 注：#
+	n, err := cr.r.Read(b)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	ustar, err := ioutil.ReadFile("testdata/ustar.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gnuLong, err := ioutil.ReadFile("testdata/gnu-long.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	badChksum := append([]byte(nil), ustar...)
+	badChksum[2*blockSize+148] = 'X' // checksum of the second header
+
+	var assorted bytes.Buffer
+	tw := NewWriter(&assorted)
+	for _, hdr := range []*Header{
+		{Name: "ok.txt", Typeflag: TypeReg},
+		{Name: "with space", Typeflag: TypeReg},
+		{Name: "../escape", Typeflag: TypeReg},
+		{Name: "ok.txt", Typeflag: TypeReg},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec, _ := formatPAXRecord(paxUid, "7")
+	if err := tw.writeRawFile("PaxHeaders/conflict", rec, TypeXHeader, FormatPAX); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&Header{Name: "conflict", Typeflag: TypeReg, Uid: 5, Format: FormatUSTAR}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An archive that stops after its last entry, without the two zero blocks.
+	var unterminated bytes.Buffer
+	tw = NewWriter(&unterminated)
+	if err := tw.WriteHeader(&Header{Name: "file", Typeflag: TypeReg, Size: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	end := int64(unterminated.Len())
+	oneZeroBlock := append(append([]byte(nil), unterminated.Bytes()...), zeroBlock[:]...)
+
+	type want struct {
+		kind   ProblemKind
+		offset int64
+		name   string
+	}
+	vectors := []struct {
+		label  string
+		data   []byte
+		format Format
+		want   []want
+	}{{
+		label:  "clean",
+		data:   ustar,
+		format: FormatUSTAR,
+	}, {
+		label:  "unrepresentable",
+		data:   gnuLong,
+		format: FormatUSTAR,
+		want: []want{
+			{ProblemFormat, 4 * blockSize, "hard"},
+		},
+	}, {
+		label: "checksum",
+		data:  badChksum,
+		want:  []want{{ProblemChecksum, 2 * blockSize, ""}},
+	}, {
+		label: "truncated",
+		data:  ustar[:blockSize+2],
+		want:  []want{{ProblemTruncated, 0, "small.txt"}},
+	}, {
+		label: "no end marker",
+		data:  unterminated.Bytes(),
+		want:  []want{{ProblemTruncated, end, ""}},
+	}, {
+		label: "one zero block",
+		data:  oneZeroBlock,
+		want:  []want{{ProblemTruncated, end, ""}},
+	}, {
+		label: "empty",
+		data:  nil,
+		want:  []want{{ProblemTruncated, 0, ""}},
+	}, {
+		label: "assorted",
+		data:  assorted.Bytes(),
+		want: []want{
+			{ProblemNonPortableName, 1 * blockSize, "with space"},
+			{ProblemNonPortableName, 2 * blockSize, "../escape"},
+			{ProblemDuplicate, 3 * blockSize, "ok.txt"},
+			{ProblemPAXConflict, 4 * blockSize, "conflict"},
+		},
+	}}
+
+	for _, v := range vectors {
+		t.Run(v.label, func(t *testing.T) {
+			got, err := Validate(bytes.NewReader(v.data), v.format)
+			if err != nil {
+				t.Fatalf("Validate(): unexpected error: %v", err)
+			}
+			if len(got) != len(v.want) {
+				t.Fatalf("Validate(): got %d problems %v, want %d", len(got), got, len(v.want))
+			}
+			for i, p := range got {
+				if w := v.want[i]; p.Kind != w.kind || p.Offset != w.offset || p.Name != w.name {
+					t.Errorf("problem %d: got %v, want %v at %d for %q", i, p, w.kind, w.offset, w.name)
+				}
+			}
+		})
+	}
+}
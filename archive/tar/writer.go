@@ -0,0 +1,607 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writer 提供tar存档的顺序写入。
+// Write.WriteHeader使用提供的Header开始一个新文件，然后可以将Writer视为io.Writer以提供该文件的数据。
+type Writer struct {
+	w    io.Writer
+	pad  int64      // 在当前文件条目之后写入的填充量
+	curr fileWriter // 当前文件条目的Writer
+	hdr  Header     // 可以安全修改的Header的浅拷贝
+	blk  block      // 用作临时本地存储的缓冲区
+
+	// err 是一个持久性错误。
+	// 确保此错误具有粘性，是Writer的每个导出方法的责任。
+	err error
+}
+
+// NewWriter 创建一个写入w的新Writer。
+func NewWriter(w io.Writer) *Writer { // 注：工厂函数，生成一个写入w的Writer
+	return &Writer{w: w, curr: &regFileWriter{w, 0}}
+}
+
+type fileWriter interface {
+	io.Writer
+	fileState
+
+	ReadFrom(io.Reader) (int64, error)
+}
+
+// Flush 完成写入当前文件的块填充。
+// 在调用Flush之前，必须完全写入当前文件。
+//
+// 这是不必要的，因为下一次调用WriteHeader或Close将隐式清除文件的填充。
+func (tw *Writer) Flush() error { // 注：#
+	if tw.err != nil {
+		return tw.err
+	}
+	if nb := tw.curr.LogicalRemaining(); nb > 0 {
+		return fmt.Errorf("archive/tar: missed writing %d bytes", nb)
+	}
+	if _, tw.err = tw.w.Write(zeroBlock[:tw.pad]); tw.err != nil {
+		return tw.err
+	}
+	tw.pad = 0
+	return nil
+}
+
+// WriteHeader 写入hdr并准备接受文件的内容。
+// Header.Size确定可以为下一个文件写入多少字节。
+// 如果当前文件未完全写入，则返回错误。
+// 这会在写入标头之前隐式清除所有必要的填充。
+//
+// 格式由Header.allowedFormats决定：如果未指定Header.Format，
+// 则按USTAR，PAX，GNU的顺序使用第一种能够编码hdr的格式。
+// 对于PAX，无法放入USTAR字段的值将作为扩展记录写入；
+// 对于GNU，过长的Name和Linkname将作为TypeGNULongName和TypeGNULongLink元文件写入。
+func (tw *Writer) WriteHeader(hdr *Header) error { // 注：#
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	tw.hdr = *hdr // Header的浅拷贝
+
+	// 避免使用旧的TypeRegA标志，并自动将其提升为使用TypeReg或TypeDir。
+	if tw.hdr.Typeflag == TypeRegA {
+		if strings.HasSuffix(tw.hdr.Name, "/") {
+			tw.hdr.Typeflag = TypeDir
+		} else {
+			tw.hdr.Typeflag = TypeReg
+		}
+	}
+
+	// 除非明确选择格式，否则将ModTime取整，并忽略AccessTime和ChangeTime。
+	// 这确保了WriteHeader的常规用法（不指定格式）并不总是选择PAX格式，这会导致发出1KiB的hdr.Size。
+	if tw.hdr.Format == FormatUnknown {
+		tw.hdr.ModTime = tw.hdr.ModTime.Round(time.Second)
+		tw.hdr.AccessTime = time.Time{}
+		tw.hdr.ChangeTime = time.Time{}
+	}
+
+	allowedFormats, paxHdrs, err := tw.hdr.allowedFormats()
+	switch {
+	case allowedFormats.has(FormatUSTAR):
+		tw.err = tw.writeUSTARHeader(&tw.hdr)
+		return tw.err
+	case allowedFormats.has(FormatPAX):
+		tw.err = tw.writePAXHeader(&tw.hdr, paxHdrs)
+		return tw.err
+	case allowedFormats.has(FormatGNU):
+		tw.err = tw.writeGNUHeader(&tw.hdr)
+		return tw.err
+	default:
+		return err // 非致命错误
+	}
+}
+
+func (tw *Writer) writeUSTARHeader(hdr *Header) error { // 注：#
+	// 检查是否可以使用USTAR前缀/后缀拆分。
+	var namePrefix string
+	if prefix, suffix, ok := splitUSTARPath(hdr.Name); ok {
+		namePrefix, hdr.Name = prefix, suffix
+	}
+
+	// 打包主标头。
+	var f formatter
+	blk := tw.templateV7Plus(hdr, f.formatString, f.formatOctal)
+	f.formatString(blk.USTAR().Prefix(), namePrefix)
+	blk.SetFormat(FormatUSTAR)
+	if f.err != nil {
+		return f.err // 永远不会发生，因为标头已经过验证
+	}
+	return tw.writeRawHeader(blk, hdr.Size, hdr.Typeflag)
+}
+
+func (tw *Writer) writePAXHeader(hdr *Header, paxHdrs map[string]string) error { // 注：#
+	realName, realSize := hdr.Name, hdr.Size
+
+	// 处理稀疏文件。
+	var spd sparseDatas
+	var spb []byte
+	if len(hdr.SparseHoles) > 0 {
+		sph := append([]sparseEntry{}, hdr.SparseHoles...) // 复制稀疏映射
+		sph = alignSparseEntries(sph, hdr.Size)
+		spd = invertSparseEntries(sph, hdr.Size)
+
+		// 格式化稀疏映射。
+		hdr.Size = 0 // 替换为编码后的大小
+		spb = append(strconv.AppendInt(spb, int64(len(spd)), 10), '\n')
+		for _, s := range spd {
+			hdr.Size += s.Length
+			spb = append(strconv.AppendInt(spb, s.Offset, 10), '\n')
+			spb = append(strconv.AppendInt(spb, s.Length, 10), '\n')
+		}
+		pad := blockPadding(int64(len(spb)))
+		spb = append(spb, zeroBlock[:pad]...)
+		hdr.Size += int64(len(spb)) // 计入编码后的稀疏映射
+
+		// 添加和修改适当的PAX记录。
+		dir, file := path.Split(realName)
+		hdr.Name = path.Join(dir, "GNUSparseFile.0", file)
+		paxHdrs[paxGNUSparseMajor] = "1"
+		paxHdrs[paxGNUSparseMinor] = "0"
+		paxHdrs[paxGNUSparseName] = realName
+		paxHdrs[paxGNUSparseRealSize] = strconv.FormatInt(realSize, 10)
+		paxHdrs[paxSize] = strconv.FormatInt(hdr.Size, 10)
+		delete(paxHdrs, paxPath) // 由paxGNUSparseName记录
+	}
+
+	// 将PAX记录写入输出。
+	isGlobal := hdr.Typeflag == TypeXGlobalHeader
+	if len(paxHdrs) > 0 || isGlobal {
+		// 对键进行排序以确定顺序。
+		var keys []string
+		for k := range paxHdrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		// 将每个记录写入缓冲区。
+		var buf strings.Builder
+		for _, k := range keys {
+			rec, err := formatPAXRecord(k, paxHdrs[k])
+			if err != nil {
+				return err
+			}
+			buf.WriteString(rec)
+		}
+
+		// 写入扩展头文件。
+		var name string
+		var flag byte
+		if isGlobal {
+			name = realName
+			if name == "" {
+				name = "GlobalHead.0.0"
+			}
+			flag = TypeXGlobalHeader
+		} else {
+			dir, file := path.Split(realName)
+			name = path.Join(dir, "PaxHeaders.0", file)
+			flag = TypeXHeader
+		}
+		data := buf.String()
+		if err := tw.writeRawFile(name, data, flag, FormatPAX); err != nil || isGlobal {
+			return err // 全局标头在这里返回
+		}
+	}
+
+	// 打包主标头。
+	var f formatter // 忽略错误，因为它们是预期的
+	fmtStr := func(b []byte, s string) { f.formatString(b, toASCII(s)) }
+	blk := tw.templateV7Plus(hdr, fmtStr, f.formatOctal)
+	blk.SetFormat(FormatPAX)
+	if err := tw.writeRawHeader(blk, hdr.Size, hdr.Typeflag); err != nil {
+		return err
+	}
+
+	// 如有必要，写入稀疏映射并设置稀疏写入器。
+	if len(spd) > 0 {
+		// 使用tw.curr，因为稀疏映射已计入hdr.Size。
+		if _, err := tw.curr.Write(spb); err != nil {
+			return err
+		}
+		tw.curr = &sparseFileWriter{tw.curr, spd, 0}
+	}
+	return nil
+}
+
+func (tw *Writer) writeGNUHeader(hdr *Header) error { // 注：#
+	// 如果Name或Linkname超过字段大小，则使用长链接文件。
+	const longName = "././@LongLink"
+	if len(hdr.Name) > nameSize {
+		data := hdr.Name + "\x00"
+		if err := tw.writeRawFile(longName, data, TypeGNULongName, FormatGNU); err != nil {
+			return err
+		}
+	}
+	if len(hdr.Linkname) > nameSize {
+		data := hdr.Linkname + "\x00"
+		if err := tw.writeRawFile(longName, data, TypeGNULongLink, FormatGNU); err != nil {
+			return err
+		}
+	}
+
+	// 打包主标头。
+	var f formatter // 忽略错误，因为它们是预期的
+	var spd sparseDatas
+	var spb []byte
+	blk := tw.templateV7Plus(hdr, f.formatString, f.formatNumeric)
+	if !hdr.AccessTime.IsZero() {
+		f.formatNumeric(blk.GNU().AccessTime(), hdr.AccessTime.Unix())
+	}
+	if !hdr.ChangeTime.IsZero() {
+		f.formatNumeric(blk.GNU().ChangeTime(), hdr.ChangeTime.Unix())
+	}
+	if hdr.Typeflag == TypeGNUSparse {
+		sph := append([]sparseEntry{}, hdr.SparseHoles...) // 复制稀疏映射
+		sph = alignSparseEntries(sph, hdr.Size)
+		spd = invertSparseEntries(sph, hdr.Size)
+
+		// 格式化稀疏映射。
+		formatSPD := func(sp sparseDatas, sa sparseArray) sparseDatas {
+			for i := 0; len(sp) > 0 && i < sa.MaxEntries(); i++ {
+				f.formatNumeric(sa.Entry(i).Offset(), sp[0].Offset)
+				f.formatNumeric(sa.Entry(i).Length(), sp[0].Length)
+				sp = sp[1:]
+			}
+			if len(sp) > 0 {
+				sa.IsExtended()[0] = 1
+			}
+			return sp
+		}
+		sp2 := formatSPD(spd, blk.GNU().Sparse())
+		for len(sp2) > 0 {
+			var spHdr block
+			sp2 = formatSPD(sp2, spHdr.Sparse())
+			spb = append(spb, spHdr[:]...)
+		}
+
+		// 更新标头块中的大小字段。
+		realSize := hdr.Size
+		hdr.Size = 0 // 编码后的大小； 不计入编码后的稀疏映射
+		for _, s := range spd {
+			hdr.Size += s.Length
+		}
+		copy(blk.V7().Size(), zeroBlock[:]) // 重置字段
+		f.formatNumeric(blk.V7().Size(), hdr.Size)
+		f.formatNumeric(blk.GNU().RealSize(), realSize)
+	}
+	blk.SetFormat(FormatGNU)
+	if err := tw.writeRawHeader(blk, hdr.Size, hdr.Typeflag); err != nil {
+		return err
+	}
+
+	// 如有必要，写入扩展的稀疏映射并设置稀疏写入器。
+	if len(spd) > 0 {
+		// 使用tw.w，因为稀疏映射未计入hdr.Size。
+		if _, err := tw.w.Write(spb); err != nil {
+			return err
+		}
+		tw.curr = &sparseFileWriter{tw.curr, spd, 0}
+	}
+	return nil
+}
+
+type (
+	stringFormatter func([]byte, string)
+	numberFormatter func([]byte, int64)
+)
+
+// templateV7Plus 用hdr中V7字段和USTAR扩展字段（Uname，Gname，Devmajor，Devminor）的值填充tw.blk。
+// 它使用提供的字符串和数字格式化函数来格式化它们。
+//
+// 不会写入magic，版本和校验和字段。
+func (tw *Writer) templateV7Plus(hdr *Header, fmtStr stringFormatter, fmtNum numberFormatter) *block { // 注：#
+	tw.blk.Reset()
+
+	modTime := hdr.ModTime
+	if modTime.IsZero() {
+		modTime = time.Unix(0, 0)
+	}
+
+	v7 := tw.blk.V7()
+	v7.TypeFlag()[0] = hdr.Typeflag
+	fmtStr(v7.Name(), hdr.Name)
+	fmtStr(v7.LinkName(), hdr.Linkname)
+	fmtNum(v7.Mode(), hdr.Mode)
+	fmtNum(v7.UID(), int64(hdr.Uid))
+	fmtNum(v7.GID(), int64(hdr.Gid))
+	fmtNum(v7.Size(), hdr.Size)
+	fmtNum(v7.ModTime(), modTime.Unix())
+
+	ustar := tw.blk.USTAR()
+	fmtStr(ustar.UserName(), hdr.Uname)
+	fmtStr(ustar.GroupName(), hdr.Gname)
+	fmtNum(ustar.DevMajor(), hdr.Devmajor)
+	fmtNum(ustar.DevMinor(), hdr.Devminor)
+
+	return &tw.blk
+}
+
+// writeRawFile 使用提供的数据写入一个最小的文件。
+// 这将名称和标志字段设置为提供的值，而所有其他字段均为零值。
+func (tw *Writer) writeRawFile(name, data string, flag byte, format Format) error { // 注：#
+	tw.blk.Reset()
+
+	// 尽力而为的文件名。
+	name = toASCII(name)
+	if len(name) > nameSize {
+		name = name[:nameSize]
+	}
+	name = strings.TrimRight(name, "/")
+
+	var f formatter
+	v7 := tw.blk.V7()
+	v7.TypeFlag()[0] = flag
+	f.formatString(v7.Name(), name)
+	f.formatOctal(v7.Mode(), 0)
+	f.formatOctal(v7.UID(), 0)
+	f.formatOctal(v7.GID(), 0)
+	f.formatOctal(v7.Size(), int64(len(data))) // 必须小于8GiB
+	f.formatOctal(v7.ModTime(), 0)
+	tw.blk.SetFormat(format)
+	if f.err != nil {
+		return f.err // 仅在违反大小条件时发生
+	}
+
+	// 写入标头和数据。
+	if err := tw.writeRawHeader(&tw.blk, int64(len(data)), flag); err != nil {
+		return err
+	}
+	_, err := io.WriteString(tw, data)
+	return err
+}
+
+// writeRawHeader 写入给定的块头，并为大小为size的文件设置下一个文件写入器。
+// 当前文件必须已经完全写入。
+func (tw *Writer) writeRawHeader(blk *block, size int64, flag byte) error { // 注：#
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if _, err := tw.w.Write(blk[:]); err != nil {
+		return err
+	}
+	if isHeaderOnlyType(flag) {
+		size = 0
+	}
+	tw.curr = &regFileWriter{tw.w, size}
+	tw.pad = blockPadding(size)
+	return nil
+}
+
+// Write 写入tar存档中的当前文件。
+// 如果在WriteHeader之后写入超过Header.Size个字节，则Write返回错误ErrWriteTooLong。
+//
+// 无论Header.Size声明如何，在TypeLink，TypeSymlink，TypeChar，TypeBlock，TypeDir和TypeFifo等特殊类型上调用Write都会返回(0, ErrWriteTooLong)。
+func (tw *Writer) Write(b []byte) (int, error) { // 注：#
+	if tw.err != nil {
+		return 0, tw.err
+	}
+	n, err := tw.curr.Write(b)
+	if err != nil && err != ErrWriteTooLong {
+		tw.err = err
+	}
+	return n, err
+}
+
+// ReadFrom 从r中读取所有数据并将其写入当前文件。
+// 这等效于io.Copy(tw, r)，但有所优化。
+//
+// 如果当前文件是稀疏的，并且r是io.ReadSeeker，
+// 则ReadFrom使用Seek跳过Header.SparseHoles中定义的孔，并假定跳过的区域全为NUL。
+// 这总是读取最后一个字节，以确保r是正确的大小。
+func (tw *Writer) ReadFrom(r io.Reader) (int64, error) { // 注：#
+	if tw.err != nil {
+		return 0, tw.err
+	}
+	n, err := tw.curr.ReadFrom(r)
+	if err != nil && err != ErrWriteTooLong {
+		tw.err = err
+	}
+	return n, err
+}
+
+// Close 通过刷新填充并写入页脚来关闭tar存档。
+// 如果当前文件（来自先前对WriteHeader的调用）未完全写入，则返回错误。
+func (tw *Writer) Close() error { // 注：#
+	if tw.err == ErrWriteAfterClose {
+		return nil
+	}
+	if tw.err != nil {
+		return tw.err
+	}
+
+	// 尾部：两个零块。
+	err := tw.Flush()
+	for i := 0; i < 2 && err == nil; i++ {
+		_, err = tw.w.Write(zeroBlock[:])
+	}
+
+	// 确保以后的所有操作均无效。
+	tw.err = ErrWriteAfterClose
+	return err // 报告IO错误
+}
+
+// regFileWriter 是用于写入常规文件数据的fileWriter。
+type regFileWriter struct {
+	w  io.Writer // 基础Writer
+	nb int64     // 剩余要写入的字节数
+}
+
+func (fw *regFileWriter) Write(b []byte) (n int, err error) { // 注：#
+	overwrite := int64(len(b)) > fw.nb
+	if overwrite {
+		b = b[:fw.nb]
+	}
+	if len(b) > 0 {
+		n, err = fw.w.Write(b)
+		fw.nb -= int64(n)
+	}
+	switch {
+	case err != nil:
+		return n, err
+	case overwrite:
+		return n, ErrWriteTooLong
+	default:
+		return n, nil
+	}
+}
+
+func (fw *regFileWriter) ReadFrom(r io.Reader) (int64, error) { // 注：将r中的数据写入fw
+	return io.Copy(struct{ io.Writer }{fw}, r)
+}
+
+func (fw regFileWriter) LogicalRemaining() int64 { // 注：获取剩余的逻辑字节数
+	return fw.nb
+}
+
+func (fw regFileWriter) PhysicalRemaining() int64 { // 注：获取剩余的物理字节数
+	return fw.nb
+}
+
+// sparseFileWriter 是用于将数据写入稀疏文件条目的fileWriter。
+type sparseFileWriter struct {
+	fw  fileWriter  // 基础fileWriter
+	sp  sparseDatas // 规范化的数据片段列表
+	pos int64       // 稀疏文件中的当前位置
+}
+
+func (sw *sparseFileWriter) Write(b []byte) (n int, err error) { // 注：#
+	overwrite := int64(len(b)) > sw.LogicalRemaining()
+	if overwrite {
+		b = b[:sw.LogicalRemaining()]
+	}
+
+	b0 := b
+	endPos := sw.pos + int64(len(b))
+	for endPos > sw.pos && err == nil {
+		var nf int // 片段中写入的字节
+		dataStart, dataEnd := sw.sp[0].Offset, sw.sp[0].endOffset()
+		if sw.pos < dataStart { // 在孔片段中
+			bf := b[:min(int64(len(b)), dataStart-sw.pos)]
+			nf, err = zeroWriter{}.Write(bf)
+		} else { // 在数据片段中
+			bf := b[:min(int64(len(b)), dataEnd-sw.pos)]
+			nf, err = sw.fw.Write(bf)
+		}
+		b = b[nf:]
+		sw.pos += int64(nf)
+		if sw.pos >= dataEnd && len(sw.sp) > 1 {
+			sw.sp = sw.sp[1:] // 确保最后一个片段始终保留
+		}
+	}
+
+	n = len(b0) - len(b)
+	switch {
+	case err == ErrWriteTooLong:
+		return n, errMissData // 不可能； 表示验证逻辑中有错误
+	case err != nil:
+		return n, err
+	case sw.LogicalRemaining() == 0 && sw.PhysicalRemaining() > 0:
+		return n, errUnrefData // 不可能； 表示验证逻辑中有错误
+	case overwrite:
+		return n, ErrWriteTooLong
+	default:
+		return n, nil
+	}
+}
+
+func (sw *sparseFileWriter) ReadFrom(r io.Reader) (n int64, err error) { // 注：#
+	rs, ok := r.(io.ReadSeeker)
+	if ok {
+		if _, err := rs.Seek(0, io.SeekCurrent); err != nil {
+			ok = false // 并非所有io.Seeker都能真正Seek
+		}
+	}
+	if !ok {
+		return io.Copy(struct{ io.Writer }{sw}, r)
+	}
+
+	var readLastByte bool
+	pos0 := sw.pos
+	for sw.LogicalRemaining() > 0 && !readLastByte && err == nil {
+		var nf int64 // 片段大小
+		dataStart, dataEnd := sw.sp[0].Offset, sw.sp[0].endOffset()
+		if sw.pos < dataStart { // 在孔片段中
+			nf = dataStart - sw.pos
+			if sw.PhysicalRemaining() == 0 {
+				readLastByte = true
+				nf--
+			}
+			_, err = rs.Seek(nf, io.SeekCurrent)
+		} else { // 在数据片段中
+			nf = dataEnd - sw.pos
+			nf, err = io.CopyN(sw.fw, rs, nf)
+		}
+		sw.pos += nf
+		if sw.pos >= dataEnd && len(sw.sp) > 1 {
+			sw.sp = sw.sp[1:] // 确保最后一个片段始终保留
+		}
+	}
+
+	// 如果最后一个片段是一个孔，则查找到EOF之前的1个字节，并读取一个字节以确保文件大小正确。
+	if readLastByte && err == nil {
+		_, err = mustReadFull(rs, []byte{0})
+		sw.pos++
+	}
+
+	n = sw.pos - pos0
+	switch {
+	case err == io.EOF:
+		return n, io.ErrUnexpectedEOF
+	case err == ErrWriteTooLong:
+		return n, errMissData // 不可能； 表示验证逻辑中有错误
+	case err != nil:
+		return n, err
+	case sw.LogicalRemaining() == 0 && sw.PhysicalRemaining() > 0:
+		return n, errUnrefData // 不可能； 表示验证逻辑中有错误
+	default:
+		return n, ensureEOF(rs)
+	}
+}
+
+func (sw sparseFileWriter) LogicalRemaining() int64 { // 注：获取剩余的逻辑字节数
+	return sw.sp[len(sw.sp)-1].endOffset() - sw.pos
+}
+
+func (sw sparseFileWriter) PhysicalRemaining() int64 { // 注：获取剩余的物理字节数
+	return sw.fw.PhysicalRemaining()
+}
+
+// zeroWriter 只能写入NUL，否则返回errWriteHole。
+type zeroWriter struct{}
+
+func (zeroWriter) Write(b []byte) (int, error) { // 注：b中存在非NUL字节时返回errWriteHole
+	for i, c := range b {
+		if c != 0 {
+			return i, errWriteHole
+		}
+	}
+	return len(b), nil
+}
+
+// ensureEOF 检查r是否位于EOF，如果不是，则报告ErrWriteTooLong。
+func ensureEOF(r io.Reader) error { // 注：#
+	n, err := tryReadFull(r, []byte{0})
+	switch {
+	case n > 0:
+		return ErrWriteTooLong
+	case err == io.EOF:
+		return nil
+	default:
+		return err
+	}
+}
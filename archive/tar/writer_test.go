@@ -0,0 +1,131 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tar
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	vectors := []struct {
+		label  string
+		hdr    Header
+		data   string
+		format Format // Expected format reported by Reader
+	}{{
+		label:  "USTAR",
+		hdr:    Header{Name: "file.txt", Typeflag: TypeReg, Mode: 0644, Size: 5, ModTime: time.Unix(1, 0)},
+		data:   "hello",
+		format: FormatUSTAR,
+	}, {
+		label:  "USTAR/split-prefix",
+		hdr:    Header{Name: strings.Repeat("d", 100) + "/" + strings.Repeat("f", 90), Typeflag: TypeReg, ModTime: time.Unix(1, 0)},
+		format: FormatUSTAR,
+	}, {
+		label:  "PAX/long-name",
+		hdr:    Header{Name: strings.Repeat("n", 300), Typeflag: TypeReg, ModTime: time.Unix(1, 0)},
+		format: FormatPAX,
+	}, {
+		label:  "PAX/sub-second",
+		hdr:    Header{Name: "t", Typeflag: TypeReg, ModTime: time.Unix(1, 5e8), Format: FormatPAX},
+		format: FormatPAX,
+	}, {
+		label:  "PAX/records",
+		hdr:    Header{Name: "r", Typeflag: TypeReg, ModTime: time.Unix(1, 0), PAXRecords: map[string]string{"GOLANG.key": "value"}},
+		format: FormatPAX,
+	}, {
+		label:  "GNU/long-link",
+		hdr:    Header{Name: strings.Repeat("g", 120), Linkname: strings.Repeat("l", 120), Typeflag: TypeSymlink, ModTime: time.Unix(1, 0), Format: FormatGNU},
+		format: FormatGNU,
+	}, {
+		label:  "GNU/large-uid",
+		hdr:    Header{Name: "u", Typeflag: TypeReg, Uid: 1 << 30, ModTime: time.Unix(1, 0), Format: FormatGNU},
+		format: FormatGNU,
+	}}
+
+	for _, v := range vectors {
+		t.Run(v.label, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := NewWriter(&buf)
+			if err := tw.WriteHeader(&v.hdr); err != nil {
+				t.Fatalf("WriteHeader() = %v", err)
+			}
+			if _, err := io.WriteString(tw, v.data); err != nil {
+				t.Fatalf("Write() = %v", err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			if buf.Len()%blockSize != 0 {
+				t.Errorf("archive size %d is not a multiple of %d", buf.Len(), blockSize)
+			}
+			if trailer := buf.Bytes()[buf.Len()-2*blockSize:]; !bytes.Equal(trailer, make([]byte, 2*blockSize)) {
+				t.Errorf("archive does not end with two zero blocks")
+			}
+
+			tr := NewReader(&buf)
+			hdr, err := tr.Next()
+			if err != nil {
+				t.Fatalf("Next() = %v", err)
+			}
+			if hdr.Name != v.hdr.Name || hdr.Linkname != v.hdr.Linkname || hdr.Uid != v.hdr.Uid || !hdr.ModTime.Equal(v.hdr.ModTime) {
+				t.Errorf("Next() = %+v, want %+v", *hdr, v.hdr)
+			}
+			if hdr.Format != v.format {
+				t.Errorf("Format = %v, want %v", hdr.Format, v.format)
+			}
+			b, err := ioutil.ReadAll(tr)
+			if err != nil || string(b) != v.data {
+				t.Errorf("ReadAll() = (%q, %v), want (%q, nil)", b, err, v.data)
+			}
+			if _, err := tr.Next(); err != io.EOF {
+				t.Errorf("Next() = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	t.Run("WriteTooLong", func(t *testing.T) {
+		tw := NewWriter(new(bytes.Buffer))
+		if err := tw.WriteHeader(&Header{Name: "a", Size: 1}); err != nil {
+			t.Fatalf("WriteHeader() = %v", err)
+		}
+		if _, err := tw.Write([]byte("ab")); err != ErrWriteTooLong {
+			t.Errorf("Write() = %v, want %v", err, ErrWriteTooLong)
+		}
+	})
+	t.Run("MissedBytes", func(t *testing.T) {
+		tw := NewWriter(new(bytes.Buffer))
+		if err := tw.WriteHeader(&Header{Name: "a", Size: 2}); err != nil {
+			t.Fatalf("WriteHeader() = %v", err)
+		}
+		if err := tw.Close(); err == nil {
+			t.Errorf("Close() = nil, want error for unwritten data")
+		}
+	})
+	t.Run("FormatMismatch", func(t *testing.T) {
+		tw := NewWriter(new(bytes.Buffer))
+		err := tw.WriteHeader(&Header{Name: strings.Repeat("x", 300), Format: FormatUSTAR})
+		if _, ok := err.(headerError); !ok || errors.Is(err, ErrHeader) {
+			t.Errorf("WriteHeader() = %v, want headerError not matching ErrHeader", err)
+		}
+	})
+	t.Run("WriteAfterClose", func(t *testing.T) {
+		tw := NewWriter(new(bytes.Buffer))
+		if err := tw.Close(); err != nil {
+			t.Fatalf("Close() = %v", err)
+		}
+		if err := tw.WriteHeader(&Header{Name: "a"}); err != ErrWriteAfterClose {
+			t.Errorf("WriteHeader() = %v, want %v", err, ErrWriteAfterClose)
+		}
+	})
+}
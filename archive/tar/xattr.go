@@ -0,0 +1,71 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// sysListXattrs 如果不为nil，则返回path处文件（不跟随符号链接）的所有扩展属性。
+// 如果操作系统或文件系统不支持扩展属性，它返回(nil, nil)。
+var sysListXattrs func(path string) (map[string]string, error)
+
+// sysSetXattr 如果不为nil，则设置path处文件（不跟随符号链接）的扩展属性name。
+var sysSetXattr func(path, name, value string) error
+
+// CaptureXattrs 读取path处文件的扩展属性（在受支持的操作系统和文件系统上），
+// 并将它们作为"SCHILY.xattr."前缀的记录存储在PAXRecords中。
+// 这包括保存文件功能的security.capability，以及保存POSIX ACL的
+// system.posix_acl_access和system.posix_acl_default。
+// 符号链接的扩展属性不会被读取。
+//
+// 由于只有PAX格式可以存储扩展属性，因此写入此类Header需要使用FormatPAX。
+func (h *Header) CaptureXattrs(path string) error { // 注：#
+	if sysListXattrs == nil || h.Typeflag == TypeSymlink {
+		return nil
+	}
+	xattrs, err := sysListXattrs(path)
+	if err != nil {
+		return &os.PathError{Op: "listxattr", Path: path, Err: err}
+	}
+	if len(xattrs) > 0 && h.PAXRecords == nil {
+		h.PAXRecords = make(map[string]string, len(xattrs))
+	}
+	for k, v := range xattrs {
+		h.PAXRecords[paxSchilyXattr+k] = v
+	}
+	return nil
+}
+
+// RestoreXattrs 将h中的扩展属性（PAXRecords中"SCHILY.xattr."前缀的记录以及Xattrs）设置到path处的文件上。
+// 与Writer.WriteHeader一样，Xattrs中的值优先于PAXRecords中的值。
+//
+// 由于更改文件的所有者或内容会清除security.capability，因此应在这些操作之后调用RestoreXattrs。
+// 如果h包含扩展属性，但操作系统不支持它们，则返回错误。
+func (h *Header) RestoreXattrs(path string) error { // 注：#
+	xattrs := make(map[string]string)
+	for k, v := range h.PAXRecords {
+		if strings.HasPrefix(k, paxSchilyXattr) {
+			xattrs[k[len(paxSchilyXattr):]] = v
+		}
+	}
+	for k, v := range h.Xattrs {
+		xattrs[k] = v
+	}
+	if len(xattrs) == 0 || h.Typeflag == TypeSymlink {
+		return nil
+	}
+	if sysSetXattr == nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: errors.New("extended attributes not supported")}
+	}
+	for k, v := range xattrs {
+		if err := sysSetXattr(path, k, v); err != nil {
+			return &os.PathError{Op: "setxattr", Path: path, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package tar
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+func init() {
+	sysListXattrs = listXattrsLinux
+	sysSetXattr = setXattrLinux
+}
+
+// listXattrsLinux 使用llistxattr(2)和lgetxattr(2)读取path处文件的所有扩展属性，不跟随符号链接。
+func listXattrsLinux(path string) (map[string]string, error) { // 注：#
+	names, err := xattrBuf(func(b []byte) (int, error) { return llistxattr(path, b) })
+	if err == syscall.ENOTSUP {
+		return nil, nil // 文件系统不支持扩展属性
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var xattrs map[string]string
+	for _, name := range strings.Split(string(names), "\x00") {
+		if name == "" {
+			continue
+		}
+		v, err := xattrBuf(func(b []byte) (int, error) { return lgetxattr(path, name, b) })
+		if err == syscall.ENODATA {
+			continue // 在列出之后被删除
+		}
+		if err != nil {
+			return nil, err
+		}
+		if xattrs == nil {
+			xattrs = make(map[string]string)
+		}
+		xattrs[name] = string(v)
+	}
+	return xattrs, nil
+}
+
+// xattrBuf 调用f获取所需的缓冲区大小，然后使用该大小的缓冲区再次调用f。
+// 如果在两次调用之间值变大（ERANGE），则重试。
+func xattrBuf(f func(b []byte) (int, error)) ([]byte, error) { // 注：#
+	for {
+		n, err := f(nil)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		b := make([]byte, n)
+		n, err = f(b)
+		if err == syscall.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+
+// setXattrLinux 使用lsetxattr(2)设置path处文件的扩展属性，不跟随符号链接。
+func setXattrLinux(path, name, value string) error { // 注：#
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	a, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return err
+	}
+	v := []byte(value)
+	_, _, e := syscall.Syscall6(syscall.SYS_LSETXATTR, uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(a)),
+		uintptr(bufPtr(v)), uintptr(len(v)), 0, 0)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// llistxattr 调用llistxattr(2)将path处文件的扩展属性名称列表存入b，b为空时只返回所需的大小。
+func llistxattr(path string, b []byte) (int, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	r, _, e := syscall.Syscall(syscall.SYS_LLISTXATTR, uintptr(unsafe.Pointer(p)), uintptr(bufPtr(b)), uintptr(len(b)))
+	if e != 0 {
+		return 0, e
+	}
+	return int(r), nil
+}
+
+// lgetxattr 调用lgetxattr(2)将path处文件的扩展属性name的值存入b，b为空时只返回所需的大小。
+func lgetxattr(path, name string, b []byte) (int, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	a, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	r, _, e := syscall.Syscall6(syscall.SYS_LGETXATTR, uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(a)),
+		uintptr(bufPtr(b)), uintptr(len(b)), 0, 0)
+	if e != 0 {
+		return 0, e
+	}
+	return int(r), nil
+}
+
+// bufPtr 返回b的第一个元素的地址，b为空时返回nil。
+func bufPtr(b []byte) unsafe.Pointer {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&b[0])
+}
@@ -0,0 +1,195 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package io
+
+// 与包io的其余部分只依赖errors与sync不同，本文件还依赖context与time：
+// 这里的API以context.Context为参数，截止时间接口使用time.Time。
+// 这两个包都不依赖io，不会形成导入循环；导入io因此也会导入它们，
+// 但time已被os等包依赖，context只依赖internal/reflectlite、sync与time等底层包。
+import (
+	"context"
+	"time"
+)
+
+// ContextError 是CopyContext以及ContextReader、ContextWriter返回的Reader、Writer
+// 因上下文被取消或超时而停止时返回的错误。
+type ContextError struct {
+	Err error // ctx.Err()的返回值
+	N   int64 // 停止前已传输的字节数
+}
+
+func (e *ContextError) Error() string { //注：返回错误字符串，例："io: context canceled after 512 bytes"
+	return "io: " + e.Err.Error() + " after " + itoa(e.N) + " bytes"
+}
+
+// Unwrap 返回ctx.Err()，使errors.Is(err, context.Canceled)等判断成立。
+func (e *ContextError) Unwrap() error { return e.Err }
+
+// readDeadliner 由支持读取截止时间的值实现，例如*os.File与net.Conn。
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// writeDeadliner 由支持写入截止时间的值实现，例如*os.File与net.Conn。
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// aLongTimeAgo 是一个早已过去的非零时间，设置为截止时间可以立即中断阻塞的读写。
+var aLongTimeAgo = time.Unix(1, 0)
+
+// watchContext 在ctx结束时为非nil的rd和wd设置立即到期的截止时间，以中断正在阻塞的Read或Write。
+// 返回的stop结束监视，并在监视goroutine退出后才返回，因此stop返回后不会再设置截止时间；
+// 如果截止时间已被设置，stop会将它清除，使底层的值在之后仍可被其他代码使用。
+// 清除会同时移除调用方之前设置的截止时间。
+//
+// 监视只持续一次Read、Write或CopyContext调用，不会比调用活得更久。
+func watchContext(ctx context.Context, rd readDeadliner, wd writeDeadliner) (stop func()) {
+	if ctx.Done() == nil || (rd == nil && wd == nil) { //注：ctx永远不会结束或无法中断时无需监视
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	fired := false // 由监视goroutine写入，在exited关闭之后读取
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			fired = true
+			if rd != nil {
+				rd.SetReadDeadline(aLongTimeAgo)
+			}
+			if wd != nil {
+				wd.SetWriteDeadline(aLongTimeAgo)
+			}
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+		if fired {
+			if rd != nil {
+				rd.SetReadDeadline(time.Time{})
+			}
+			if wd != nil {
+				wd.SetWriteDeadline(time.Time{})
+			}
+		}
+	}
+}
+
+// CopyContext 与Copy相同，但会在每次读取前检查ctx，ctx结束后停止复制。
+// 如果src实现了SetReadDeadline或dst实现了SetWriteDeadline，
+// ctx结束时还会设置一个已过期的截止时间，以中断正在阻塞的Read或Write，该截止时间在CopyContext返回前被清除。
+// 因ctx结束而停止时，返回的err为*ContextError，其中包含ctx.Err()与已复制的字节数。
+//
+// 为了能在每块数据之间检查ctx，CopyContext不使用src的WriterTo接口。
+func CopyContext(ctx context.Context, dst Writer, src Reader) (written int64, err error) { //注：从src中读取数据写入dst，每次读取前检查ctx，返回拷贝的数据长度written与错误err
+	if cerr := ctx.Err(); cerr != nil {
+		return 0, &ContextError{cerr, 0}
+	}
+	rd, _ := src.(readDeadliner)
+	wd, _ := dst.(writeDeadliner)
+	stop := watchContext(ctx, rd, wd)
+	written, err = copyBuffer(dst, &ctxCheckReader{ctx, src}, nil)
+	stop()
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil { //注：因ctx结束导致的错误（包括截止时间到期）统一报告为ContextError
+			err = &ContextError{cerr, written}
+		}
+	}
+	return written, err
+}
+
+// ctxCheckReader 在每次Read之前检查ctx，供CopyContext使用。
+type ctxCheckReader struct {
+	ctx context.Context
+	r   Reader
+}
+
+func (r *ctxCheckReader) Read(p []byte) (n int, err error) { //注：ctx已结束则返回ctx.Err()，否则调用r.r.Read
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ContextReader 返回一个从r读取的Reader，它在每次Read前检查ctx，ctx结束后返回*ContextError。
+// 如果r实现了SetReadDeadline，ctx结束时还会设置一个已过期的读取截止时间，以中断正在阻塞的Read。
+// *ContextError中的字节数是通过该Reader读取的总字节数。
+// 截止时间只在Read期间被监视，Read返回前会被清除，之后r可以继续被直接使用。
+func ContextReader(ctx context.Context, r Reader) Reader { //工厂函数
+	rd, _ := r.(readDeadliner)
+	return &contextReader{ctx: ctx, r: r, rd: rd}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   Reader
+	rd  readDeadliner // r实现了SetReadDeadline时不为nil
+	n   int64         // 已读取的字节数
+}
+
+func (r *contextReader) Read(p []byte) (n int, err error) { //注：ctx未结束时从r.r读取数据存到p中，返回读取到的数据长度n与错误err
+	if cerr := r.ctx.Err(); cerr != nil {
+		return 0, &ContextError{cerr, r.n}
+	}
+	stop := watchContext(r.ctx, r.rd, nil)
+	n, err = r.r.Read(p)
+	stop()
+	r.n += int64(n)
+	if err != nil && err != EOF {
+		if cerr := r.ctx.Err(); cerr != nil {
+			err = &ContextError{cerr, r.n}
+		}
+	}
+	return n, err
+}
+
+// ContextWriter 返回一个写入w的Writer，它在每次Write前检查ctx，ctx结束后返回*ContextError。
+// 如果w实现了SetWriteDeadline，ctx结束时还会设置一个已过期的写入截止时间，以中断正在阻塞的Write。
+// *ContextError中的字节数是通过该Writer写入的总字节数。
+// 截止时间只在Write期间被监视，Write返回前会被清除，之后w可以继续被直接使用。
+func ContextWriter(ctx context.Context, w Writer) Writer { //工厂函数
+	wd, _ := w.(writeDeadliner)
+	return &contextWriter{ctx: ctx, w: w, wd: wd}
+}
+
+type contextWriter struct {
+	ctx context.Context
+	w   Writer
+	wd  writeDeadliner // w实现了SetWriteDeadline时不为nil
+	n   int64          // 已写入的字节数
+}
+
+func (w *contextWriter) Write(p []byte) (n int, err error) { //注：ctx未结束时将p写入w.w，返回写入的数据长度n与错误err
+	if cerr := w.ctx.Err(); cerr != nil {
+		return 0, &ContextError{cerr, w.n}
+	}
+	stop := watchContext(w.ctx, nil, w.wd)
+	n, err = w.w.Write(p)
+	stop()
+	w.n += int64(n)
+	if err != nil {
+		if cerr := w.ctx.Err(); cerr != nil {
+			err = &ContextError{cerr, w.n}
+		}
+	}
+	return n, err
+}
+
+// itoa 将非负整数转换为十进制字符串，避免依赖strconv。
+func itoa(v int64) string {
+	var b [20]byte
+	i := len(b) - 1
+	for v >= 10 {
+		b[i] = byte('0' + v%10)
+		v /= 10
+		i--
+	}
+	b[i] = byte('0' + v)
+	return string(b[i:])
+}
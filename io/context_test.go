@@ -0,0 +1,257 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextError(t *testing.T) {
+	err := error(&ContextError{context.Canceled, 512})
+	if got, want := err.Error(), "io: context canceled after 512 bytes"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("errors.Is(err, context.Canceled) = false")
+	}
+}
+
+func TestCopyContext(t *testing.T) {
+	var buf bytes.Buffer
+	src := NewSectionReader(strings.NewReader("hello, world"), 0, 12)
+	n, err := CopyContext(context.Background(), &buf, src)
+	if n != 12 || err != nil || buf.String() != "hello, world" {
+		t.Errorf("CopyContext() = %d, %v, copied %q", n, err, buf.String())
+	}
+}
+
+func TestCopyContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	n, err := CopyContext(ctx, &buf, strings.NewReader("data"))
+	var cerr *ContextError
+	if n != 0 || !errors.As(err, &cerr) || cerr.N != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("CopyContext(canceled) = %d, %v, want 0, *ContextError{context.Canceled, 0}", n, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("CopyContext(canceled) copied %q", buf.String())
+	}
+}
+
+// cancelReader returns n bytes on its first Read and calls cancel.
+type cancelReader struct {
+	n      int
+	cancel func()
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	r.cancel()
+	return copy(p, strings.Repeat("x", r.n)), nil
+}
+
+func TestCopyContextCanceledMidway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	n, err := CopyContext(ctx, &buf, &cancelReader{5, cancel})
+	var cerr *ContextError
+	if n != 5 || !errors.As(err, &cerr) || cerr.N != 5 || cerr.Err != context.Canceled {
+		t.Errorf("CopyContext() = %d, %v, want 5, *ContextError{context.Canceled, 5}", n, err)
+	}
+}
+
+func TestContextReaderInterruptsRead(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.Write([]byte("ab"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cr := ContextReader(ctx, r)
+	b := make([]byte, 2)
+	if n, err := cr.Read(b); n != 2 || err != nil {
+		t.Fatalf("Read() = %d, %v", n, err)
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	n, err := cr.Read(b)
+	var cerr *ContextError
+	if n != 0 || !errors.As(err, &cerr) || cerr.N != 2 || cerr.Err != context.Canceled {
+		t.Errorf("blocked Read() = %d, %v, want 0, *ContextError{context.Canceled, 2}", n, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("blocked Read() took %v to be interrupted", d)
+	}
+	if n, err := cr.Read(b); n != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Read() after cancel = %d, %v, want context.Canceled", n, err)
+	}
+}
+
+func TestContextWriterInterruptsWrite(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// Nobody reads r, so the write blocks once the pipe buffer is full.
+	n, err := ContextWriter(ctx, w).Write(make([]byte, 16<<20))
+	var cerr *ContextError
+	if !errors.As(err, &cerr) || cerr.Err != context.DeadlineExceeded || cerr.N != int64(n) {
+		t.Errorf("blocked Write() = %d, %v, want *ContextError{context.DeadlineExceeded, %d}", n, err, n)
+	}
+}
+
+func TestContextWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	cw := ContextWriter(ctx, &buf)
+	if n, err := cw.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	cancel()
+	n, err := cw.Write([]byte("def"))
+	var cerr *ContextError
+	if n != 0 || !errors.As(err, &cerr) || cerr.N != 3 {
+		t.Errorf("Write() after cancel = %d, %v, want 0, *ContextError{context.Canceled, 3}", n, err)
+	}
+	if buf.String() != "abc" {
+		t.Errorf("written %q, want %q", buf.String(), "abc")
+	}
+}
+
+// deadlineReader is a SectionReader with a no-op SetReadDeadline.
+type deadlineReader struct {
+	*SectionReader
+}
+
+func (deadlineReader) SetReadDeadline(time.Time) error { return nil }
+
+// waitGoroutines waits for the number of goroutines to drop to at most n.
+func waitGoroutines(n int) int {
+	got := runtime.NumGoroutine()
+	for i := 0; got > n && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+		got = runtime.NumGoroutine()
+	}
+	return got
+}
+
+func TestContextReaderWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	base := waitGoroutines(runtime.NumGoroutine())
+
+	// The watcher lives only for the duration of each Read, so a
+	// reader that is dropped half way leaves nothing running.
+	data := strings.NewReader(strings.Repeat("x", 100))
+	cr := ContextReader(ctx, deadlineReader{NewSectionReader(data, 0, 100)})
+	b := make([]byte, 1)
+	for i := 0; i < 10; i++ {
+		if _, err := cr.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		if n := waitGoroutines(base); n > base {
+			t.Fatalf("after %d Reads: %d goroutines, want %d", i+1, n, base)
+		}
+	}
+}
+
+func TestContextReaderReuse(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.Write([]byte("a"))
+
+	// A successful Read followed by cancel leaves r untouched.
+	ctx, cancel := context.WithCancel(context.Background())
+	b := make([]byte, 1)
+	if _, err := ContextReader(ctx, r).Read(b); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	w.Write([]byte("b"))
+	if n, err := r.Read(b); n != 1 || err != nil {
+		t.Fatalf("direct Read() after cancel = %d, %v", n, err)
+	}
+
+	// An interrupted Read clears the deadline it set.
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := ContextReader(ctx, r).Read(b); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("blocked Read() = %v, want context.DeadlineExceeded", err)
+	}
+	w.Write([]byte("c"))
+	if n, err := r.Read(b); n != 1 || err != nil || b[0] != 'c' {
+		t.Errorf("direct Read() after interrupted Read = %q, %v", b[:n], err)
+	}
+}
+
+func TestCopyContextReuse(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var buf bytes.Buffer
+	if _, err := CopyContext(ctx, &buf, r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CopyContext() = %v, want context.DeadlineExceeded", err)
+	}
+	w.Write([]byte("after"))
+	b := make([]byte, 5)
+	if n, err := r.Read(b); n != 5 || err != nil {
+		t.Errorf("direct Read() after CopyContext = %q, %v", b[:n], err)
+	}
+}
+
+func TestContextWriterReuse(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	n, err := ContextWriter(ctx, w).Write(make([]byte, 16<<20))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("blocked Write() = %d, %v, want context.DeadlineExceeded", n, err)
+	}
+	// Drain what was written, then write directly.
+	go func() {
+		b := make([]byte, 64<<10)
+		for {
+			if _, err := r.Read(b); err != nil {
+				return
+			}
+		}
+	}()
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Errorf("direct Write() after interrupted Write = %v", err)
+	}
+}
@@ -48,6 +48,13 @@
 			(w *PipeWriter) Write(data []byte) (n int, err error) 	w写入data
 			(w *PipeWriter) Close() error							关闭管道
 			(w *PipeWriter) CloseWithError(err error) error 		关闭管道
+		---context.go
+		type ContextError struct
+			(e *ContextError) Error() string							返回错误字符串
+			(e *ContextError) Unwrap() error							返回ctx.Err()
+		type ctxCheckReader struct
+		type contextReader struct
+		type contextWriter struct
 		---ioutil/ioutil.go
 		type nopCloser struct
 	函数与方法
@@ -73,6 +80,12 @@
 		MultiWriter(writers ...Writer) Writer								工厂函数，遍历writers，检查是否为multiWriter，追加w.writers或w
 			(t *multiWriter) Write(p []byte) (n int, err error)				遍历t.writers，每个Writer都写入p，返回p的长度n与错误err
 			(t *multiWriter) WriteString(s string) (n int, err error)		遍历t.writers，执行w.StringWriter或w.Write写入s，返回s的长度n与错误err
+		---context.go
+		CopyContext(ctx, dst, src) (written int64, err error)				从src中读取数据写入dst，每次读取前检查ctx，返回拷贝的数据长度written与错误err
+		ContextReader(ctx context.Context, r Reader) Reader					工厂函数
+			(r *contextReader) Read(p []byte) (n int, err error)			ctx未结束时从r.r读取数据存到p中，返回读取到的数据长度n与错误err
+		ContextWriter(ctx context.Context, w Writer) Writer					工厂函数
+			(w *contextWriter) Write(p []byte) (n int, err error)			ctx未结束时将p写入w.w，返回写入的数据长度n与错误err
 		---pipe.go
 		Pipe() (*PipeReader, *PipeWriter)									工厂函数
 		---ioutil/ioutil.go
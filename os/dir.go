@@ -4,6 +4,8 @@
 
 package os
 
+import "sort"
+
 // Readdir 读取与file关联的目录的内容，并按目录顺序返回最多n个FileInfo值的切片，这将由Lstat返回。
 // 对同一文件的后续调用将产生进一步的FileInfo。
 // 如果n> 0，则Readdir最多返回n个FileInfo结构。
@@ -39,3 +41,53 @@ func (f *File) Readdirnames(n int) (names []string, err error) {
 	}
 	return f.readdirnames(n)
 }
+
+// DirEntry 是从目录读取的一项（使用ReadDir函数或File.ReadDir方法）。
+// 与FileInfo不同，在Linux上DirEntry的类型来自目录项本身，读取它不需要对每一项调用lstat。
+type DirEntry interface {
+	// Name 返回该项描述的文件（或子目录）的名称。
+	// 此名称只是路径的最后一个元素（基本名称），而不是整个路径。
+	Name() string
+
+	// IsDir 报告该项是否描述一个目录。
+	IsDir() bool
+
+	// Type 返回该项的类型位。
+	// 类型位是常规FileMode位的子集，即FileMode.Type方法返回的那些位。
+	Type() FileMode
+
+	// Info 返回该项描述的文件或子目录的FileInfo。
+	// 返回的FileInfo可能来自读取原始目录时，也可能来自调用Info时（使用lstat）。
+	// 如果文件在读取目录之后被删除或重命名，Info可能返回满足IsNotExist的错误。
+	Info() (FileInfo, error)
+}
+
+// ReadDir 读取与f关联的目录的内容，并按目录顺序返回最多n个DirEntry值的切片。
+// 对同一文件的后续调用将产生后续的DirEntry记录。
+//
+// 如果n> 0，则ReadDir最多返回n个DirEntry记录。
+// 在这种情况下，如果ReadDir返回一个空切片，它将返回一个非nil错误，说明原因。 在目录末尾，错误是io.EOF。
+//
+// 如果n <= 0，则ReadDir在单个切片中返回目录中的所有DirEntry记录。
+// 在这种情况下，如果ReadDir成功（一直读取到目录的末尾），它将返回切片和nil错误。
+// 如果它在目录末尾之前遇到错误，则ReadDir返回读取的DirEntry列表直到该点为止，并且返回非nil错误。
+func (f *File) ReadDir(n int) ([]DirEntry, error) {
+	if f == nil {
+		return nil, ErrInvalid
+	}
+	return f.readDir(n)
+}
+
+// ReadDir 读取命名目录，返回按文件名排序的所有目录项。
+// 如果读取目录时发生错误，则ReadDir返回在错误之前能够读取的目录项以及该错误。
+func ReadDir(name string) ([]DirEntry, error) { // 注：#
+	f, err := Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dirs, err := f.ReadDir(-1)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name() < dirs[j].Name() })
+	return dirs, err
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestReaddirentsSkipAndStop(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	var want []string
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		want = append(want, name)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Skipped entries do not count toward n: with the first two entries
+	// vanishing, n = 2 still yields two entries.
+	var got, skipped []string
+	err = Readdirents(f, 2, func(name string) (bool, error) {
+		if len(skipped) < 2 {
+			skipped = append(skipped, name)
+			return false, nil
+		}
+		got = append(got, name)
+		return true, nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Readdirents(2) with two skipped = %q, %v, want two names", got, err)
+	}
+
+	// An error stops the read at the failing entry; the entries after
+	// it are returned by the next call.
+	errStat := errors.New("stat failed")
+	var failed string
+	err = Readdirents(f, -1, func(name string) (bool, error) {
+		failed = name
+		return false, errStat
+	})
+	if err != errStat {
+		t.Fatalf("Readdirents() = %v, want %v", err, errStat)
+	}
+	var rest []string
+	err = Readdirents(f, -1, func(name string) (bool, error) {
+		rest = append(rest, name)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := append(append(append(skipped, got...), failed), rest...)
+	sort.Strings(all)
+	if strings.Join(all, ",") != strings.Join(want, ",") {
+		t.Errorf("entries seen across calls = %q, want each of %q exactly once", all, want)
+	}
+
+	// At the end, n > 0 with nothing accepted reports io.EOF, even if
+	// everything left was skipped.
+	if err := Readdirents(f, 1, func(string) (bool, error) { return false, nil }); err != io.EOF {
+		t.Errorf("Readdirents(1) at end = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,197 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+import (
+	"io"
+	"runtime"
+)
+
+// 如果文件描述符是目录，则为辅助信息
+type dirInfo struct { // 注：目录信息
+	buf  []byte // 目录I/O的缓冲区
+	nbuf int    // buf的长度，getdents64的返回值
+	bufp int    // buf中下一条记录的位置
+}
+
+const (
+	// 超过5760以解决https://golang.org/issue/24015。
+	blockSize = 8192
+)
+
+func (d *dirInfo) close() {}
+
+func (f *File) readdir(n int) (fi []FileInfo, err error) { // 注：#
+	dirname := f.name
+	if dirname == "" {
+		dirname = "."
+	}
+	names, err := f.Readdirnames(n)
+	fi = make([]FileInfo, 0, len(names))
+	for _, filename := range names {
+		fip, lerr := lstat(dirname + "/" + filename)
+		if IsNotExist(lerr) {
+			// 文件在Readdirnames和lstat之间消失了，可以忽略它
+			continue
+		}
+		if lerr != nil {
+			return fi, lerr
+		}
+		fi = append(fi, fip)
+	}
+	if len(fi) == 0 && err == nil && n > 0 {
+		// 要求返回非空结果，但所有条目都消失了
+		err = io.EOF
+	}
+	return fi, err
+}
+
+func (f *File) readdirnames(n int) (names []string, err error) { // 注：#
+	size := n
+	if size <= 0 {
+		size = 100
+	}
+	names = make([]string, 0, size) // 空但有增长的空间
+	err = f.readdirents(n, func(name string, typ FileMode) (bool, error) {
+		names = append(names, name)
+		return true, nil
+	})
+	return names, err
+}
+
+func (f *File) readDir(n int) (dirents []DirEntry, err error) { // 注：#
+	size := n
+	if size <= 0 {
+		size = 100
+	}
+	dirents = make([]DirEntry, 0, size) // 空但有增长的空间
+	err = f.readdirents(n, func(name string, typ FileMode) (bool, error) {
+		d, e := newUnixDirent(f.name, name, typ)
+		if IsNotExist(e) {
+			// 文件在读取目录与lstat之间消失了，可以忽略它，也不计入n
+			return false, nil
+		}
+		if e != nil {
+			return false, e
+		}
+		dirents = append(dirents, d)
+		return true, nil
+	})
+	return dirents, err
+}
+
+// readdirents 使用getdents64(2)读取目录项并对每一项调用fn，直到fn接受了n项（n <= 0表示读取全部）。
+// fn返回是否接受了该项，未被接受的项不计入n；fn返回错误时立即停止并返回该错误，
+// 之后的目录项留给下一次调用。
+// 与Readdirnames相同，n > 0且没有接受任何目录项时返回io.EOF。
+func (f *File) readdirents(n int, fn func(name string, typ FileMode) (bool, error)) error { // 注：#
+	// 如果该文件没有dirinfo，则创建一个
+	if f.dirinfo == nil {
+		f.dirinfo = new(dirInfo)
+		f.dirinfo.buf = make([]byte, blockSize)
+	}
+	d := f.dirinfo
+
+	if n <= 0 {
+		n = -1
+	}
+	count := 0
+	for n != 0 {
+		// 必要时重新填充缓冲区
+		if d.bufp >= d.nbuf {
+			d.bufp = 0
+			var errno error
+			errno = ignoringEINTR(func() error {
+				var err error
+				d.nbuf, err = f.pfd.ReadDirent(d.buf) // 注：getdents64(2)
+				return err
+			})
+			runtime.KeepAlive(f)
+			if errno != nil {
+				return &PathError{"readdirent", f.name, errno}
+			}
+			if d.nbuf <= 0 {
+				break // EOF
+			}
+		}
+
+		// 从缓冲区中取出记录
+		buf := d.buf[d.bufp:d.nbuf]
+		reclen, ok := direntReclen(buf)
+		if !ok || reclen > uint64(len(buf)) {
+			break
+		}
+		rec := buf[:reclen]
+		d.bufp += int(reclen)
+		ino, ok := direntIno(rec)
+		if !ok {
+			break
+		}
+		if ino == 0 { // 注：已被删除但尚未回收的条目
+			continue
+		}
+		name, ok := direntName(rec)
+		if !ok {
+			break
+		}
+		if name == "." || name == ".." { // 无用的名称
+			continue
+		}
+		ok, err := fn(name, direntType(rec))
+		if err != nil {
+			return err
+		}
+		if ok {
+			count++
+			n--
+		}
+	}
+	if n >= 0 && count == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+// unixDirent 是Linux上的DirEntry，类型来自getdents64(2)返回的d_type，不需要lstat。
+type unixDirent struct {
+	parent string
+	name   string
+	typ    FileMode
+	info   FileInfo
+}
+
+func (d *unixDirent) Name() string   { return d.name }
+func (d *unixDirent) IsDir() bool    { return d.typ.IsDir() }
+func (d *unixDirent) Type() FileMode { return d.typ }
+
+func (d *unixDirent) Info() (FileInfo, error) { // 注：#
+	if d.info != nil {
+		return d.info, nil
+	}
+	return lstat(d.parent + "/" + d.name)
+}
+
+// newUnixDirent 创建一个DirEntry。
+// 文件系统不提供d_type（DT_UNKNOWN）时，立即使用lstat确定类型。
+func newUnixDirent(parent, name string, typ FileMode) (DirEntry, error) { // 注：#
+	ude := &unixDirent{
+		parent: parent,
+		name:   name,
+		typ:    typ,
+	}
+	if typ != ^FileMode(0) {
+		return ude, nil
+	}
+
+	info, err := lstat(parent + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	ude.typ = info.Mode().Type()
+	ude.info = info
+	return ude, nil
+}
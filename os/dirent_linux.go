@@ -0,0 +1,86 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// getdents64(2)返回的linux_dirent64记录按8字节对齐，字段采用本机字节序，
+// 因此可以直接按syscall.Dirent的字段偏移量读取。
+
+// direntIno 返回buf中第一条记录的inode号。
+func direntIno(buf []byte) (uint64, bool) { // 注：#
+	off := unsafe.Offsetof(syscall.Dirent{}.Ino)
+	if uintptr(len(buf)) < off+8 {
+		return 0, false
+	}
+	return *(*uint64)(unsafe.Pointer(&buf[off])), true
+}
+
+// direntReclen 返回buf中第一条记录的长度。
+func direntReclen(buf []byte) (uint64, bool) { // 注：#
+	off := unsafe.Offsetof(syscall.Dirent{}.Reclen)
+	if uintptr(len(buf)) < off+2 {
+		return 0, false
+	}
+	return uint64(*(*uint16)(unsafe.Pointer(&buf[off]))), true
+}
+
+// direntNamlen 返回buf中第一条记录的名称字段长度（包括结尾的NUL填充）。
+func direntNamlen(buf []byte) (uint64, bool) { // 注：#
+	reclen, ok := direntReclen(buf)
+	if !ok {
+		return 0, false
+	}
+	return reclen - uint64(unsafe.Offsetof(syscall.Dirent{}.Name)), true
+}
+
+// direntName 返回rec中的文件名，rec是一条完整的记录。
+func direntName(rec []byte) (string, bool) { // 注：#
+	namlen, ok := direntNamlen(rec)
+	if !ok {
+		return "", false
+	}
+	off := uint64(unsafe.Offsetof(syscall.Dirent{}.Name))
+	if off+namlen > uint64(len(rec)) {
+		return "", false
+	}
+	name := rec[off : off+namlen]
+	for i, c := range name { // 注：名称以NUL结尾
+		if c == 0 {
+			name = name[:i]
+			break
+		}
+	}
+	return string(name), true
+}
+
+// direntType 将rec中的d_type转换为FileMode的类型位。
+// 文件系统不提供类型（DT_UNKNOWN）时返回^FileMode(0)。
+func direntType(rec []byte) FileMode { // 注：#
+	off := unsafe.Offsetof(syscall.Dirent{}.Type)
+	if off >= uintptr(len(rec)) {
+		return ^FileMode(0) // 未知
+	}
+	switch rec[off] {
+	case syscall.DT_BLK:
+		return ModeDevice
+	case syscall.DT_CHR:
+		return ModeDevice | ModeCharDevice
+	case syscall.DT_DIR:
+		return ModeDir
+	case syscall.DT_FIFO:
+		return ModeNamedPipe
+	case syscall.DT_LNK:
+		return ModeSymlink
+	case syscall.DT_REG:
+		return 0
+	case syscall.DT_SOCK:
+		return ModeSocket
+	}
+	return ^FileMode(0) // 未知
+}
@@ -7,6 +7,7 @@
 package os
 
 import (
+	"errors"
 	"internal/testlog"
 	"syscall"
 )
@@ -51,6 +52,362 @@ func ExpandEnv(s string) string {
 	return Expand(s, Getenv)
 }
 
+// ExpandShell is like Expand but also understands the POSIX shell parameter
+// expansion forms:
+//
+//	${var:-word}  word if var is unset or empty, otherwise $var
+//	${var-word}   word if var is unset, otherwise $var
+//	${var:=word}  like ${var:-word}, and var is set to word
+//	${var=word}   like ${var-word}, and var is set to word
+//	${var:?word}  an error with message word if var is unset or empty
+//	${var?word}   an error with message word if var is unset
+//	${var:+word}  word if var is set and not empty, otherwise ""
+//	${var+word}   word if var is set, otherwise ""
+//	${#var}       the length of $var in characters
+//	${var#pat}    $var with the shortest prefix matching pat removed
+//	${var##pat}   $var with the longest prefix matching pat removed
+//	${var%pat}    $var with the shortest suffix matching pat removed
+//	${var%%pat}   $var with the longest suffix matching pat removed
+//
+// The lookup function reports the value of a variable and whether it is set.
+// Word and pat are themselves expanded, but only when they are used.
+// Patterns use shell glob syntax: *, ? and [...] brackets, with a backslash quoting
+// the next character. In word a backslash also quotes the next character, so
+// ${var:-a\}b} expands to "a}b" and ${var:-\$HOME} to "$HOME"; outside
+// ${...} backslashes are copied unchanged, as in Expand. Assignments made by
+// ${var:=word} are visible to the rest of s but are not passed back to the
+// caller.
+//
+// Unlike Expand, malformed expansions and ${var:?word} with an unset
+// variable are reported as errors rather than silently producing an empty
+// string.
+func ExpandShell(s string, lookup func(string) (string, bool)) (string, error) {
+	e := shellExpander{lookup: lookup}
+	return e.expand(s, escNone)
+}
+
+// ExpandShellEnv is like ExpandShell using the current environment variables.
+// Assignments made by ${var:=word} do not modify the environment.
+func ExpandShellEnv(s string) (string, error) {
+	return ExpandShell(s, LookupEnv)
+}
+
+// shellExpander holds the state of a single ExpandShell call.
+type shellExpander struct {
+	lookup   func(string) (string, bool)
+	assigned map[string]string // variables set by ${var:=word}
+}
+
+func (e *shellExpander) get(name string) (string, bool) {
+	if v, ok := e.assigned[name]; ok {
+		return v, true
+	}
+	return e.lookup(name)
+}
+
+// escMode controls how shellExpander.expand treats backslashes.
+type escMode int
+
+const (
+	escNone   escMode = iota // backslashes are ordinary characters
+	escRemove                // a backslash quotes the next character and is removed
+	escKeep                  // a backslash quotes the next character and is kept for shellMatch
+)
+
+func (e *shellExpander) expand(s string, esc escMode) (string, error) {
+	var buf []byte
+	i := 0
+	for j := 0; j < len(s); j++ {
+		if esc != escNone && s[j] == '\\' && j+1 < len(s) {
+			if buf == nil {
+				buf = make([]byte, 0, 2*len(s))
+			}
+			buf = append(buf, s[i:j]...)
+			if esc == escKeep {
+				buf = append(buf, s[j])
+			}
+			buf = append(buf, s[j+1])
+			j++
+			i = j + 1
+			continue
+		}
+		if s[j] == '$' && j+1 < len(s) {
+			if buf == nil {
+				buf = make([]byte, 0, 2*len(s))
+			}
+			buf = append(buf, s[i:j]...)
+			if s[j+1] == '{' {
+				end := closingBrace(s, j+1)
+				if end < 0 {
+					return "", errors.New("os: missing closing brace in " + s[j:])
+				}
+				v, err := e.param(s[j+2 : end])
+				if err != nil {
+					return "", err
+				}
+				buf = append(buf, v...)
+				j = end
+			} else {
+				name, w := getShellName(s[j+1:])
+				if name == "" {
+					// $ was not followed by a name. Leave the
+					// dollar character untouched.
+					buf = append(buf, s[j])
+				} else {
+					v, _ := e.get(name)
+					buf = append(buf, v...)
+				}
+				j += w
+			}
+			i = j + 1
+		}
+	}
+	if buf == nil {
+		return s, nil
+	}
+	return string(buf) + s[i:], nil
+}
+
+// param evaluates the body of a ${...} expansion.
+func (e *shellExpander) param(expr string) (string, error) {
+	if len(expr) > 1 && expr[0] == '#' {
+		// ${#var}
+		name, rest := splitShellParam(expr[1:])
+		if name == "" || rest != "" {
+			return "", badSubstitution(expr)
+		}
+		v, _ := e.get(name)
+		return itoa(len([]rune(v))), nil
+	}
+	name, rest := splitShellParam(expr)
+	if name == "" {
+		return "", badSubstitution(expr)
+	}
+	v, set := e.get(name)
+	if rest == "" {
+		return v, nil
+	}
+	colon := rest[0] == ':'
+	if colon {
+		rest = rest[1:]
+		if rest == "" {
+			return "", badSubstitution(expr)
+		}
+	}
+	op, word := rest[0], rest[1:]
+	unset := !set || colon && v == ""
+	switch op {
+	case '-':
+		if unset {
+			return e.expand(word, escRemove)
+		}
+		return v, nil
+	case '=':
+		if unset {
+			w, err := e.expand(word, escRemove)
+			if err != nil {
+				return "", err
+			}
+			if e.assigned == nil {
+				e.assigned = make(map[string]string)
+			}
+			e.assigned[name] = w
+			return w, nil
+		}
+		return v, nil
+	case '?':
+		if unset {
+			msg, err := e.expand(word, escRemove)
+			if err != nil {
+				return "", err
+			}
+			if msg == "" {
+				if colon {
+					msg = "parameter null or not set"
+				} else {
+					msg = "parameter not set"
+				}
+			}
+			return "", errors.New(name + ": " + msg)
+		}
+		return v, nil
+	case '+':
+		if unset {
+			return "", nil
+		}
+		return e.expand(word, escRemove)
+	case '#', '%':
+		if colon {
+			break
+		}
+		longest := false
+		if word != "" && word[0] == op {
+			longest = true
+			word = word[1:]
+		}
+		pat, err := e.expand(word, escKeep)
+		if err != nil {
+			return "", err
+		}
+		return trimShellPattern(v, pat, op == '#', longest), nil
+	}
+	return "", badSubstitution(expr)
+}
+
+func badSubstitution(expr string) error {
+	return errors.New("os: bad substitution: ${" + expr + "}")
+}
+
+// splitShellParam splits the body of a ${...} expansion into the
+// variable name and the operator that follows it.
+func splitShellParam(expr string) (name, rest string) {
+	if expr == "" {
+		return "", ""
+	}
+	if c := expr[0]; c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		i := 1
+		for i < len(expr) && isAlphaNum(expr[i]) {
+			i++
+		}
+		return expr[:i], expr[i:]
+	}
+	if isShellSpecialVar(expr[0]) {
+		return expr[:1], expr[1:]
+	}
+	return "", expr
+}
+
+// closingBrace returns the index of the '}' that closes the '{' at s[open],
+// skipping over nested ${...} expansions, or -1 if there is none.
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// trimShellPattern removes the shortest or longest prefix or suffix of v
+// that matches the shell pattern pat. If nothing matches, v is returned.
+func trimShellPattern(v, pat string, prefix, longest bool) string {
+	r, p := []rune(v), []rune(pat)
+	for k := 0; k <= len(r); k++ {
+		n := k
+		if longest {
+			n = len(r) - k
+		}
+		if prefix {
+			if shellMatch(p, r[:n]) {
+				return string(r[n:])
+			}
+		} else {
+			if shellMatch(p, r[len(r)-n:]) {
+				return string(r[:len(r)-n])
+			}
+		}
+	}
+	return v
+}
+
+// shellMatch reports whether the whole of name matches the shell pattern.
+// A '[' without a closing ']' matches itself.
+func shellMatch(pattern, name []rune) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if shellMatch(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(name) == 0 {
+				return false
+			}
+		case '[':
+			if len(name) == 0 {
+				return false
+			}
+			ok, w := matchShellClass(pattern, name[0])
+			if w > 0 {
+				if !ok {
+					return false
+				}
+				pattern, name = pattern[w:], name[1:]
+				continue
+			}
+			if name[0] != '[' {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(name) == 0 || name[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchShellClass matches c against the bracket expression at the start of
+// pattern. It returns the number of runes in the bracket expression, or 0 if
+// the expression is not terminated.
+func matchShellClass(pattern []rune, c rune) (matched bool, width int) {
+	i := 1
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return matched != negate, i + 1
+		}
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			if hi == '\\' && i+3 < len(pattern) {
+				i++
+				hi = pattern[i+2]
+			}
+			i += 2
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+		i++
+	}
+	return false, 0
+}
+
 // isShellSpecialVar reports whether the character identifies a special
 // shell variable such as $*.
 func isShellSpecialVar(c uint8) bool {
@@ -0,0 +1,152 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	. "os"
+	"strings"
+	"testing"
+)
+
+var shellVars = map[string]string{
+	"HOME":  "/home/gopher",
+	"EMPTY": "",
+	"FILE":  "archive.tar.gz",
+	"PATHV": "/usr/local/bin/go",
+	"UNI":   "héllo",
+	"STAR":  "*",
+	"1":     "first",
+}
+
+func lookupShellVar(name string) (string, bool) {
+	v, ok := shellVars[name]
+	return v, ok
+}
+
+var expandShellTests = []struct {
+	in, out string
+}{
+	// Plain expansion, as in Expand.
+	{"", ""},
+	{"no vars", "no vars"},
+	{"$HOME/bin", "/home/gopher/bin"},
+	{"${HOME}bin", "/home/gopherbin"},
+	{"$1 ${1}", "first first"},
+	{"$UNSET.", "."},
+	{"cost: $", "cost: $"},
+	{"a\\b", "a\\b"},
+
+	// ${var:-word} and ${var-word}.
+	{"${HOME:-x}", "/home/gopher"},
+	{"${EMPTY:-x}", "x"},
+	{"${UNSET:-x}", "x"},
+	{"${EMPTY-x}", ""},
+	{"${UNSET-x}", "x"},
+	{"${UNSET:-$HOME/src}", "/home/gopher/src"},
+	{"${UNSET:-${EMPTY:-nested}}", "nested"},
+
+	// ${var:=word} and ${var=word}.
+	{"${UNSET:=set} $UNSET", "set set"},
+	{"${EMPTY:=set} $EMPTY", "set set"},
+	{"${EMPTY=set}.$EMPTY", "."},
+	{"${UNSET=a}${UNSET=b}", "aa"},
+
+	// ${var:+word} and ${var+word}.
+	{"${HOME:+alt}", "alt"},
+	{"${EMPTY:+alt}", ""},
+	{"${EMPTY+alt}", "alt"},
+	{"${UNSET+alt}", ""},
+	{"${HOME:+$FILE}", "archive.tar.gz"},
+
+	// ${var:?word} and ${var?word} when set.
+	{"${HOME:?unset}", "/home/gopher"},
+	{"${EMPTY?unset}", ""},
+
+	// ${#var}.
+	{"${#HOME}", "12"},
+	{"${#EMPTY}", "0"},
+	{"${#UNSET}", "0"},
+	{"${#UNI}", "5"},
+
+	// Prefix and suffix removal.
+	{"${FILE#*.}", "tar.gz"},
+	{"${FILE##*.}", "gz"},
+	{"${FILE%.*}", "archive.tar"},
+	{"${FILE%%.*}", "archive"},
+	{"${PATHV##*/}", "go"},
+	{"${PATHV%/*}", "/usr/local/bin"},
+	{"${FILE#nomatch}", "archive.tar.gz"},
+	{"${FILE#a?c}", "hive.tar.gz"},
+	{"${FILE%[a-z][a-z]}", "archive.tar."},
+	{"${FILE%[!z]}", "archive.tar.gz"},
+	{"${UNI#h?}", "llo"},
+	{"${STAR#\\*}", ""},
+	{"${FILE#$UNSET}", "archive.tar.gz"},
+
+	// Backslashes quote the next character inside ${...}.
+	{"${UNSET:-a\\}b}", "a}b"},
+	{"${UNSET:-\\$HOME}", "$HOME"},
+	{"${UNSET:-a\\\\b}", "a\\b"},
+	{"${HOME:+\\}}", "}"},
+	{"${UNSET:=x\\}y}$UNSET", "x}yx}y"},
+	{"${FILE%\\.gz}", "archive.tar"},
+	{"${STAR%\\}}", "*"},
+}
+
+func TestExpandShell(t *testing.T) {
+	for _, tt := range expandShellTests {
+		got, err := ExpandShell(tt.in, lookupShellVar)
+		if err != nil || got != tt.out {
+			t.Errorf("ExpandShell(%q) = %q, %v, want %q, nil", tt.in, got, err, tt.out)
+		}
+	}
+}
+
+func TestExpandShellErrors(t *testing.T) {
+	tests := []struct {
+		in, err string
+	}{
+		{"${UNSET:?}", "UNSET: parameter null or not set"},
+		{"${EMPTY:?}", "EMPTY: parameter null or not set"},
+		{"${UNSET?}", "UNSET: parameter not set"},
+		{"${UNSET:?need $HOME}", "UNSET: need /home/gopher"},
+		{"${UNSET?a\\}b}", "UNSET: a}b"},
+		{"${HOME", "missing closing brace"},
+		{"${UNSET:-a\\}", "missing closing brace"},
+		{"${}", "bad substitution"},
+		{"${HOME:}", "bad substitution"},
+		{"${HOME:#x}", "bad substitution"},
+		{"${HOME/a/b}", "bad substitution"},
+		{"${#HOME:-x}", "bad substitution"},
+		{"${UNSET:-${HOME:}}", "bad substitution"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandShell(tt.in, lookupShellVar)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ExpandShell(%q) = %q, %v, want error containing %q", tt.in, got, err, tt.err)
+		}
+	}
+}
+
+func TestExpandShellEnv(t *testing.T) {
+	const name = "OS_TEST_EXPAND_SHELL"
+	defer Unsetenv(name)
+	if err := Setenv(name, "value"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ExpandShellEnv("${" + name + ":-x} ${" + name + "%ue}")
+	if err != nil || got != "value val" {
+		t.Errorf("ExpandShellEnv() = %q, %v, want %q", got, err, "value val")
+	}
+
+	Unsetenv(name)
+	got, err = ExpandShellEnv("${" + name + ":=assigned}")
+	if err != nil || got != "assigned" {
+		t.Errorf("ExpandShellEnv(:=) = %q, %v, want %q", got, err, "assigned")
+	}
+	if v, ok := LookupEnv(name); ok {
+		t.Errorf("${var:=word} modified the environment: %s=%q", name, v)
+	}
+}
@@ -4,6 +4,7 @@
 package os
 
 import (
+	"errors"
 	"internal/oserror"
 	"internal/poll"
 )
@@ -21,6 +22,10 @@ var (
 	ErrNotExist   = errNotExist()   // "文件不存在
 	ErrClosed     = errClosed()     // "文件已关闭"
 	ErrNoDeadline = errNoDeadline() // "文件类型不支持截止日期"
+
+	// ErrNoXattr表示请求的扩展属性不存在。
+	// Linux上，Getxattr等函数将内核返回的ENODATA转换为ErrNoXattr。
+	ErrNoXattr = errNoXattr() // "扩展属性不存在"
 )
 
 func errInvalid() error    { return oserror.ErrInvalid }    // 注："无效的参数"
@@ -30,6 +35,9 @@ func errNotExist() error   { return oserror.ErrNotExist }   // 注："文件不
 func errClosed() error     { return oserror.ErrClosed }     // 注："文件已关闭"
 func errNoDeadline() error { return poll.ErrNoDeadline }    // 注："文件类型不支持截止日期"
 
+// errNoXattr 没有对应的系统调用错误：内核返回的ENODATA也用于与扩展属性无关的情况。
+func errNoXattr() error { return errors.New("extended attribute does not exist") } // 注："扩展属性不存在"
+
 type timeout interface {
 	Timeout() bool
 }
@@ -93,6 +101,12 @@ func IsPermission(err error) bool { // 注：获取err的底层错误是否为"
 	return underlyingErrorIs(err, ErrPermission)
 }
 
+// IsNoXattr 返回一个布尔值，指示是否已知该错误以报告请求的扩展属性不存在。
+// ErrNoXattr以及Getxattr等函数返回的属性不存在的错误都可以满足要求；与扩展属性无关的ENODATA不满足。
+func IsNoXattr(err error) bool { // 注：获取err的底层错误是否为"扩展属性不存在"
+	return underlyingErrorIs(err, ErrNoXattr)
+}
+
 // IsTimeout 返回一个布尔值，指示是否已知该错误以报告发生了超时。
 func IsTimeout(err error) bool { // 注：获取err的底层错误是否超时
 	terr, ok := underlyingError(err).(timeout)
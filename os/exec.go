@@ -0,0 +1,154 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"internal/testlog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrProcessDone 表示进程已经结束。
+var ErrProcessDone = errors.New("os: process already finished")
+
+// Process 存储由StartProcess创建的进程的信息。
+type Process struct {
+	Pid    int
+	handle uintptr      // Linux上为pidfd，内核不支持pidfd时为unsetHandle
+	isdone uint32       // 进程已被等待时设置为1
+	sigMu  sync.RWMutex // 避免等待与发送信号之间的竞争
+}
+
+// unsetHandle 表示Process没有可用的句柄。
+const unsetHandle = ^uintptr(0)
+
+func newProcess(pid int, handle uintptr) *Process { // 注：#
+	p := &Process{Pid: pid, handle: handle}
+	runtime.SetFinalizer(p, (*Process).Release)
+	return p
+}
+
+func (p *Process) setDone() {
+	atomic.StoreUint32(&p.isdone, 1)
+}
+
+func (p *Process) done() bool {
+	return atomic.LoadUint32(&p.isdone) > 0
+}
+
+// ProcAttr 保存将应用于由StartProcess启动的新进程的属性。
+type ProcAttr struct {
+	// 如果Dir不为空，则子进程在创建进程之前更改到该目录。
+	Dir string
+	// 如果Env不为nil，它将以Environ返回的形式为新进程提供环境变量。
+	// 如果为nil，则使用Environ的结果。
+	Env []string
+	// Files 指定新进程继承的打开文件。
+	// 前三个条目对应于标准输入，标准输出和标准错误。
+	// 实现可以根据底层操作系统支持其他条目。 nil条目对应于该进程终止时关闭的文件。
+	Files []*File
+
+	// 操作系统特定的流程创建属性。
+	// 请注意，设置此字段意味着您的程序可能无法在某些操作系统上正确执行，甚至无法编译。
+	Sys *syscall.SysProcAttr
+}
+
+// Signal 表示操作系统信号。
+// 通常的底层实现取决于操作系统：在Unix上是syscall.Signal。
+type Signal interface {
+	String() string
+	Signal() // 与其他Stringer区别
+}
+
+// Getpid 返回调用方的进程ID。
+func Getpid() int { return syscall.Getpid() }
+
+// Getppid 返回调用方父级的进程ID。
+func Getppid() int { return syscall.Getppid() }
+
+// FindProcess 通过其pid查找正在运行的进程。
+//
+// 它返回的Process可用于获取有关基础操作系统进程的信息。
+//
+// 在Unix系统上，无论进程是否存在，FindProcess始终成功并为给定的pid返回Process。
+// 在支持pidfd的Linux上，若进程已不存在，返回的Process的Signal与Wait会报告ErrProcessDone。
+func FindProcess(pid int) (*Process, error) {
+	return findProcess(pid)
+}
+
+// StartProcess 使用name，argv和attr指定的程序，参数和属性启动一个新进程。
+// argv切片在新进程中将成为os.Args，因此它通常以程序名称开头。
+//
+// 如果调用goroutine已使用runtime.LockOSThread锁定了操作系统线程并修改了任何可继承的OS级线程状态
+// （例如Linux或Plan 9名称空间），则新进程将继承调用者的线程状态。
+//
+// StartProcess是一个低级接口。 os/exec软件包提供了更高级别的接口。
+//
+// 如果有错误，它将是*PathError类型。
+func StartProcess(name string, argv []string, attr *ProcAttr) (*Process, error) {
+	testlog.Open(name)
+	return startProcess(name, argv, attr)
+}
+
+// Release 释放与进程p关联的所有资源，使其在将来无法使用。
+// 仅在不调用Wait时才需要调用Release。
+func (p *Process) Release() error {
+	return p.release()
+}
+
+// Kill 使进程立即退出。 Kill不等待进程实际退出。 这只会杀死进程本身，而不会杀死它可能已启动的任何其他进程。
+func (p *Process) Kill() error {
+	return p.kill()
+}
+
+// Wait 等待进程退出，然后返回描述其状态的ProcessState和错误（如果有）。
+// Wait释放与该进程关联的所有资源。
+// 在大多数操作系统上，进程必须是当前进程的子进程，否则将返回错误。
+func (p *Process) Wait() (*ProcessState, error) {
+	return p.wait()
+}
+
+// Signal 向进程发送信号。
+// 在Windows上未实现发送中断。
+func (p *Process) Signal(sig Signal) error {
+	return p.signal(sig)
+}
+
+// UserTime 返回退出的进程及其子进程的用户CPU时间。
+func (p *ProcessState) UserTime() time.Duration {
+	return p.userTime()
+}
+
+// SystemTime 返回退出的进程及其子进程的系统CPU时间。
+func (p *ProcessState) SystemTime() time.Duration {
+	return p.systemTime()
+}
+
+// Exited 报告程序是否已退出。
+func (p *ProcessState) Exited() bool {
+	return p.exited()
+}
+
+// Success 报告程序是否成功退出，例如在Unix上以退出状态0退出。
+func (p *ProcessState) Success() bool {
+	return p.success()
+}
+
+// Sys 返回有关进程的系统相关退出信息。
+// 将其转换为适当的基础类型，例如Unix上的syscall.WaitStatus，以访问其内容。
+func (p *ProcessState) Sys() interface{} {
+	return p.sys()
+}
+
+// SysUsage 返回有关已退出进程的系统相关资源使用情况信息。
+// 将其转换为适当的基础类型，例如Unix上的*syscall.Rusage，以访问其内容。
+// （在Unix上，*syscall.Rusage与getrusage(2)手册页中定义的struct rusage匹配。）
+func (p *ProcessState) SysUsage() interface{} {
+	return p.sysUsage()
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	. "os"
+	"syscall"
+	"testing"
+)
+
+func startShell(t *testing.T, script string) *Process {
+	t.Helper()
+	p, err := StartProcess("/bin/sh", []string{"sh", "-c", script}, &ProcAttr{})
+	if err != nil {
+		t.Skipf("StartProcess: %v", err)
+	}
+	return p
+}
+
+func TestCheckPidfd(t *testing.T) {
+	// The probe must not fail in a way other than reporting that
+	// the kernel lacks pidfd support.
+	if err := CheckPidfd(); err != nil {
+		t.Logf("pidfd not usable: %v", err)
+	}
+}
+
+func TestProcessWaitExitCode(t *testing.T) {
+	p := startShell(t, "exit 3")
+	ps, err := p.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ps.Exited() || ps.Success() || ps.ExitCode() != 3 || ps.Pid() != p.Pid {
+		t.Errorf("Wait() = %v (pid %d), want exit status 3 (pid %d)", ps, ps.Pid(), p.Pid)
+	}
+	if err := p.Signal(Kill); err != ErrProcessDone {
+		t.Errorf("Signal() after Wait = %v, want ErrProcessDone", err)
+	}
+	if _, err := p.Wait(); err != ErrProcessDone {
+		t.Errorf("second Wait() = %v, want ErrProcessDone", err)
+	}
+}
+
+func TestProcessKill(t *testing.T) {
+	p := startShell(t, "exec sleep 60")
+	if err := p.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	ps, err := p.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := ps.Sys().(syscall.WaitStatus)
+	if !ws.Signaled() || ws.Signal() != syscall.SIGKILL || ps.ExitCode() != -1 {
+		t.Errorf("Wait() after Kill = %v, want killed by SIGKILL", ps)
+	}
+}
+
+func TestProcessReleaseDuringWait(t *testing.T) {
+	p := startShell(t, "exec sleep 60")
+	pid := p.Pid
+	defer syscall.Kill(pid, syscall.SIGKILL)
+
+	type result struct {
+		ps  *ProcessState
+		err error
+	}
+	c := make(chan result)
+	go func() {
+		ps, err := p.Wait()
+		c <- result{ps, err}
+	}()
+	// Closing the pidfd must not disturb the blocked Wait.
+	for i := 0; i < 100; i++ {
+		Getpid()
+	}
+	if err := p.Release(); err != nil {
+		t.Fatal(err)
+	}
+	syscall.Kill(pid, syscall.SIGKILL)
+	r := <-c
+	if r.err != nil && r.err != syscall.EINVAL {
+		t.Fatalf("Wait() = %v", r.err)
+	}
+	if r.ps != nil {
+		if ws := r.ps.Sys().(syscall.WaitStatus); !ws.Signaled() {
+			t.Errorf("Wait() = %v, want killed by SIGKILL", r.ps)
+		}
+	}
+}
+
+func TestFindProcessExited(t *testing.T) {
+	p := startShell(t, "exit 0")
+	if _, err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	q, err := FindProcess(p.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Release()
+	if CheckPidfd() != nil {
+		t.Skip("pidfd not usable")
+	}
+	if err := q.Signal(syscall.Signal(0)); err != ErrProcessDone {
+		t.Errorf("Signal() to exited process = %v, want ErrProcessDone", err)
+	}
+}
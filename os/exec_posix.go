@@ -0,0 +1,136 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// 保证在所有系统上都存在于os包中的唯一信号值是os.Interrupt（向进程发送中断）和os.Kill（强制进程退出）。
+var (
+	Interrupt Signal = syscall.SIGINT
+	Kill      Signal = syscall.SIGKILL
+)
+
+func startProcess(name string, argv []string, attr *ProcAttr) (p *Process, err error) { // 注：#
+	// 如果没有SysProcAttr（即没有Chroot或更改的UID/GID），请仔细检查我们要chdir进入的目录是否存在。
+	// 这样可以使错误更清晰。
+	if attr != nil && attr.Sys == nil && attr.Dir != "" {
+		if _, err := Stat(attr.Dir); err != nil {
+			pe := err.(*PathError)
+			pe.Op = "chdir"
+			return nil, pe
+		}
+	}
+
+	sysattr := &syscall.ProcAttr{
+		Dir: attr.Dir,
+		Env: attr.Env,
+		Sys: attr.Sys,
+	}
+	if sysattr.Env == nil {
+		sysattr.Env = Environ()
+	}
+	sysattr.Files = make([]uintptr, 0, len(attr.Files))
+	for _, f := range attr.Files {
+		sysattr.Files = append(sysattr.Files, f.Fd())
+	}
+
+	// 在Linux上，syscall.StartProcess使用clone(CLONE_VFORK|CLONE_VM)创建子进程，
+	// 子进程与父进程共享地址空间直到execve(2)成功，避免了复制页表的开销。
+	pid, _, e := syscall.StartProcess(name, argv, sysattr)
+
+	// 确保我们不运行attr.Files的终结器。
+	runtime.KeepAlive(attr)
+
+	if e != nil {
+		return nil, &PathError{"fork/exec", name, e}
+	}
+
+	// 子进程在被等待之前不会被回收，因此它的PID不可能被重用，此时打开的pidfd一定指向该子进程。
+	h := unsetHandle
+	if fd, err := pidfdOpen(pid); err == nil {
+		h = uintptr(fd)
+	}
+	return newProcess(pid, h), nil
+}
+
+func (p *Process) kill() error {
+	return p.Signal(Kill)
+}
+
+// ProcessState 存储有关Wait报告的进程的信息。
+type ProcessState struct {
+	pid    int                // 进程的ID。
+	status syscall.WaitStatus // 系统相关的状态信息。
+	rusage *syscall.Rusage
+}
+
+// Pid 返回退出进程的进程ID。
+func (p *ProcessState) Pid() int {
+	return p.pid
+}
+
+func (p *ProcessState) exited() bool {
+	return p.status.Exited()
+}
+
+func (p *ProcessState) success() bool {
+	return p.status.ExitStatus() == 0
+}
+
+func (p *ProcessState) sys() interface{} {
+	return p.status
+}
+
+func (p *ProcessState) sysUsage() interface{} {
+	return p.rusage
+}
+
+func (p *ProcessState) userTime() time.Duration {
+	return time.Duration(p.rusage.Utime.Nano()) * time.Nanosecond
+}
+
+func (p *ProcessState) systemTime() time.Duration {
+	return time.Duration(p.rusage.Stime.Nano()) * time.Nanosecond
+}
+
+func (p *ProcessState) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	status := p.Sys().(syscall.WaitStatus)
+	res := ""
+	switch {
+	case status.Exited():
+		res = "exit status " + itoa(status.ExitStatus())
+	case status.Signaled():
+		res = "signal: " + status.Signal().String()
+	case status.Stopped():
+		res = "stop signal: " + status.StopSignal().String()
+		if status.StopSignal() == syscall.SIGTRAP && status.TrapCause() != 0 {
+			res += " (trap " + itoa(status.TrapCause()) + ")"
+		}
+	case status.Continued():
+		res = "continued"
+	}
+	if status.CoreDump() {
+		res += " (core dumped)"
+	}
+	return res
+}
+
+// ExitCode 返回退出进程的退出代码；如果进程尚未退出或被信号终止，则返回-1。
+func (p *ProcessState) ExitCode() int {
+	// 如果进程尚未启动，则返回-1。
+	if p == nil {
+		return -1
+	}
+	return p.status.ExitStatus()
+}
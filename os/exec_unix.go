@@ -0,0 +1,146 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+)
+
+func (p *Process) wait() (ps *ProcessState, err error) { // 注：#
+	// 在副本上等待，使并发的Release关闭p.handle不会影响阻塞中的waitid。
+	waitPid, fd := p.waitHandle()
+	if waitPid == -1 {
+		return nil, syscall.EINVAL
+	}
+	if p.done() {
+		if fd != -1 {
+			syscall.Close(fd)
+		}
+		return nil, ErrProcessDone
+	}
+
+	var (
+		status syscall.WaitStatus
+		rusage syscall.Rusage
+		pid    int
+	)
+	if fd != -1 {
+		// 通过pidfd等待，与PID的重用无关。
+		pid, status, err = pidfdWait(fd, &rusage)
+		syscall.Close(fd)
+		if err != nil {
+			return nil, NewSyscallError("waitid", err)
+		}
+	} else {
+		var e error
+		e = ignoringEINTR(func() error {
+			pid, e = syscall.Wait4(waitPid, &status, 0, &rusage)
+			return e
+		})
+		if e != nil {
+			return nil, NewSyscallError("wait", e)
+		}
+	}
+
+	// 进程已被回收，它的PID随时可能被重用；
+	// 在持有写锁的情况下标记完成，使并发的signal不会把信号发送给其他进程。
+	if pid != 0 {
+		p.sigMu.Lock()
+		p.setDone()
+		p.closeHandle()
+		p.sigMu.Unlock()
+	}
+	ps = &ProcessState{
+		pid:    pid,
+		status: status,
+		rusage: &rusage,
+	}
+	return ps, nil
+}
+
+func (p *Process) signal(sig Signal) error { // 注：#
+	if p.Pid == -1 {
+		return errors.New("os: process already released")
+	}
+	if p.Pid == 0 {
+		return errors.New("os: process not initialized")
+	}
+	p.sigMu.RLock()
+	defer p.sigMu.RUnlock()
+	if p.done() {
+		return ErrProcessDone
+	}
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return errors.New("os: unsupported signal type")
+	}
+	var e error
+	if h := p.handle; h != unsetHandle {
+		e = pidfdSendSignal(int(h), s)
+	} else {
+		e = syscall.Kill(p.Pid, s)
+	}
+	if e != nil {
+		if e == syscall.ESRCH {
+			return ErrProcessDone
+		}
+		return e
+	}
+	return nil
+}
+
+func (p *Process) release() error {
+	p.sigMu.Lock()
+	p.closeHandle()
+	// 注：将Pid设置为-1，之后的Wait与Signal报告错误
+	p.Pid = -1
+	p.sigMu.Unlock()
+	// 不再需要终结器
+	runtime.SetFinalizer(p, nil)
+	return nil
+}
+
+// waitHandle 在持有p.sigMu读锁的情况下读取p.Pid并复制p的pidfd，返回的副本由调用方关闭。
+// p没有pidfd或复制失败时fd为-1，此时调用方应退回到基于PID的操作。
+func (p *Process) waitHandle() (pid, fd int) { // 注：#
+	p.sigMu.RLock()
+	defer p.sigMu.RUnlock()
+	pid, h := p.Pid, p.handle
+	if h == unsetHandle {
+		return pid, -1
+	}
+	r, _, e := syscall.Syscall(syscall.SYS_FCNTL, h, syscall.F_DUPFD_CLOEXEC, 0)
+	if e != 0 {
+		return pid, -1
+	}
+	return pid, int(r)
+}
+
+// closeHandle 关闭p的pidfd，调用方必须持有p.sigMu的写锁。
+func (p *Process) closeHandle() {
+	if h := p.handle; h != unsetHandle {
+		syscall.Close(int(h))
+		p.handle = unsetHandle
+	}
+}
+
+func findProcess(pid int) (p *Process, err error) { // 注：#
+	fd, err := pidfdOpen(pid)
+	if err == syscall.ESRCH {
+		// 进程已不存在，返回一个已完成的Process，之后的Signal与Wait报告ErrProcessDone
+		p = newProcess(pid, unsetHandle)
+		p.setDone()
+		return p, nil
+	}
+	if err != nil {
+		// 内核不支持pidfd，或没有权限：退回到基于PID的操作
+		return newProcess(pid, unsetHandle), nil
+	}
+	return newProcess(pid, uintptr(fd)), nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os
+
+// Export for testing.
+
+var StatxUnsupported = &statxUnsupported
+
+var CheckPidfd = checkPidfd
+
+var (
+	Openat2Unsupported = &openat2Unsupported
+	ErrPathEscapes     = errPathEscapes
+)
+
+// Readdirents calls f.readdirents, so tests can simulate entries that
+// vanish or fail to stat while the directory is being read.
+func Readdirents(f *File, n int, fn func(name string) (bool, error)) error {
+	return f.readdirents(n, func(name string, typ FileMode) (bool, error) { return fn(name) })
+}
+
+var TestHookCopy = &testHookCopy
@@ -157,6 +157,72 @@ func (f *File) Write(b []byte) (n int, err error) { // 注：#
 	return n, err
 }
 
+// ReadFrom 实现io.ReaderFrom。
+// 在Linux上，当r是常规文件时使用copy_file_range(2)，当r是管道时使用splice(2)，
+// 数据不经过用户空间缓冲区；内核不支持时退回到普通的复制。
+func (f *File) ReadFrom(r io.Reader) (n int64, err error) { // 注：#
+	if err := f.checkValid("write"); err != nil {
+		return 0, err
+	}
+	n, handled, e := f.readFrom(r)
+	if !handled {
+		return genericReadFrom(f, r) // 不包装错误
+	}
+	return n, f.wrapErr("write", e)
+}
+
+// noReadFrom 可以与另一个类型一起嵌入，以隐藏该类型的ReadFrom方法。
+type noReadFrom struct{}
+
+// ReadFrom 隐藏另一个ReadFrom方法，永远不应该被调用。
+func (noReadFrom) ReadFrom(io.Reader) (int64, error) {
+	panic("can't happen")
+}
+
+// fileWithoutReadFrom 实现*File除ReadFrom以外的所有方法。
+// 这使ReadFrom可以调用io.Copy而不会导致对ReadFrom的递归调用。
+type fileWithoutReadFrom struct {
+	noReadFrom
+	*File
+}
+
+func genericReadFrom(f *File, r io.Reader) (int64, error) {
+	return io.Copy(fileWithoutReadFrom{File: f}, r)
+}
+
+// WriteTo 实现io.WriterTo。
+// 在Linux上，w是常规文件时与ReadFrom一样使用copy_file_range(2)，否则当f是常规文件且w是*File时使用sendfile(2)；
+// 内核不支持时退回到普通的复制。
+func (f *File) WriteTo(w io.Writer) (n int64, err error) { // 注：#
+	if err := f.checkValid("read"); err != nil {
+		return 0, err
+	}
+	n, handled, e := f.writeTo(w)
+	if handled {
+		return n, f.wrapErr("read", e)
+	}
+	return genericWriteTo(f, w) // 不包装错误
+}
+
+// noWriteTo 可以与另一个类型一起嵌入，以隐藏该类型的WriteTo方法。
+type noWriteTo struct{}
+
+// WriteTo 隐藏另一个WriteTo方法，永远不应该被调用。
+func (noWriteTo) WriteTo(io.Writer) (int64, error) {
+	panic("can't happen")
+}
+
+// fileWithoutWriteTo 实现*File除WriteTo以外的所有方法。
+// 这使WriteTo可以调用io.Copy而不会导致对WriteTo的递归调用。
+type fileWithoutWriteTo struct {
+	noWriteTo
+	*File
+}
+
+func genericWriteTo(f *File, w io.Writer) (int64, error) {
+	return io.Copy(w, fileWithoutWriteTo{File: f})
+}
+
 var errWriteAtInAppendMode = errors.New("os: invalid use of WriteAt on file opened with O_APPEND") // 错误："在以O_APPEND打开的文件上无效使用WriteAt"
 
 // WriteAt 从字节偏移量开始将len(b)字节写入文件。
@@ -344,7 +410,7 @@ func TempDir() string {
 //
 // On Unix systems, it returns $XDG_CACHE_HOME as specified by
 // https://standards.freedesktop.org/basedir-spec/basedir-spec-latest.html if
-// non-empty, else $HOME/.cache.
+// non-empty and absolute, else $HOME/.cache.
 // On Darwin, it returns $HOME/Library/Caches.
 // On Windows, it returns %LocalAppData%.
 // On Plan 9, it returns $home/lib/cache.
@@ -376,7 +442,7 @@ func UserCacheDir() (string, error) {
 		dir += "/lib/cache"
 
 	default: // Unix
-		dir = Getenv("XDG_CACHE_HOME")
+		dir = xdgEnv("XDG_CACHE_HOME")
 		if dir == "" {
 			dir = Getenv("HOME")
 			if dir == "" {
@@ -395,7 +461,7 @@ func UserCacheDir() (string, error) {
 //
 // On Unix systems, it returns $XDG_CONFIG_HOME as specified by
 // https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html if
-// non-empty, else $HOME/.config.
+// non-empty and absolute, else $HOME/.config.
 // On Darwin, it returns $HOME/Library/Application Support.
 // On Windows, it returns %AppData%.
 // On Plan 9, it returns $home/lib.
@@ -427,7 +493,7 @@ func UserConfigDir() (string, error) {
 		dir += "/lib"
 
 	default: // Unix
-		dir = Getenv("XDG_CONFIG_HOME")
+		dir = xdgEnv("XDG_CONFIG_HOME")
 		if dir == "" {
 			dir = Getenv("HOME")
 			if dir == "" {
@@ -440,6 +506,249 @@ func UserConfigDir() (string, error) {
 	return dir, nil
 }
 
+// UserDataDir returns the default root directory to use for user-specific
+// data files. Users should create their own application-specific
+// subdirectory within this one and use that.
+//
+// On Unix systems, it returns $XDG_DATA_HOME as specified by
+// https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html if
+// non-empty and absolute, else $HOME/.local/share.
+// On Darwin, it returns $HOME/Library/Application Support.
+// On Windows, it returns %LocalAppData%.
+// On Plan 9, it returns $home/lib.
+//
+// If the location cannot be determined (for example, $HOME is not defined),
+// then it will return an error.
+func UserDataDir() (string, error) {
+	var dir string
+
+	switch runtime.GOOS {
+	case "windows":
+		dir = Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("%LocalAppData% is not defined")
+		}
+
+	case "darwin":
+		dir = Getenv("HOME")
+		if dir == "" {
+			return "", errors.New("$HOME is not defined")
+		}
+		dir += "/Library/Application Support"
+
+	case "plan9":
+		dir = Getenv("home")
+		if dir == "" {
+			return "", errors.New("$home is not defined")
+		}
+		dir += "/lib"
+
+	default: // Unix
+		dir = xdgEnv("XDG_DATA_HOME")
+		if dir == "" {
+			dir = Getenv("HOME")
+			if dir == "" {
+				return "", errors.New("neither $XDG_DATA_HOME nor $HOME are defined")
+			}
+			dir += "/.local/share"
+		}
+	}
+
+	return dir, nil
+}
+
+// UserStateDir returns the default root directory to use for user-specific
+// state data, such as logs and history, that should persist between restarts
+// but is not important enough to be kept with UserDataDir. Users should
+// create their own application-specific subdirectory within this one and
+// use that.
+//
+// On Unix systems, it returns $XDG_STATE_HOME as specified by
+// https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html if
+// non-empty and absolute, else $HOME/.local/state.
+// On Darwin, it returns $HOME/Library/Application Support.
+// On Windows, it returns %LocalAppData%.
+// On Plan 9, it returns $home/lib/state.
+//
+// If the location cannot be determined (for example, $HOME is not defined),
+// then it will return an error.
+func UserStateDir() (string, error) {
+	var dir string
+
+	switch runtime.GOOS {
+	case "windows":
+		dir = Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("%LocalAppData% is not defined")
+		}
+
+	case "darwin":
+		dir = Getenv("HOME")
+		if dir == "" {
+			return "", errors.New("$HOME is not defined")
+		}
+		dir += "/Library/Application Support"
+
+	case "plan9":
+		dir = Getenv("home")
+		if dir == "" {
+			return "", errors.New("$home is not defined")
+		}
+		dir += "/lib/state"
+
+	default: // Unix
+		dir = xdgEnv("XDG_STATE_HOME")
+		if dir == "" {
+			dir = Getenv("HOME")
+			if dir == "" {
+				return "", errors.New("neither $XDG_STATE_HOME nor $HOME are defined")
+			}
+			dir += "/.local/state"
+		}
+	}
+
+	return dir, nil
+}
+
+// UserRuntimeDir returns the directory to use for user-specific runtime
+// files such as sockets and named pipes. Its contents do not survive a
+// logout or reboot.
+//
+// On Unix systems, it returns $XDG_RUNTIME_DIR as specified by
+// https://specifications.freedesktop.org/basedir-spec/basedir-spec-latest.html
+// if non-empty and absolute. There is no fallback: the specification
+// leaves it to the application to pick a replacement with similar
+// guarantees, so an error is returned instead.
+// On Darwin, Windows and Plan 9, it returns TempDir().
+func UserRuntimeDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "plan9":
+		return TempDir(), nil
+	}
+	dir := xdgEnv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return "", errors.New("$XDG_RUNTIME_DIR is not defined")
+	}
+	return dir, nil
+}
+
+// UserConfigDirs returns the preference-ordered list of system-wide
+// directories to search for configuration files, in addition to
+// UserConfigDir.
+//
+// On Unix systems, it returns the absolute entries of $XDG_CONFIG_DIRS, or
+// /etc/xdg if the variable is empty.
+// On Darwin, it returns /Library/Application Support.
+// On Windows, it returns %ProgramData% if defined.
+// On Plan 9, it returns /lib.
+func UserConfigDirs() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return nonEmpty(Getenv("ProgramData"))
+	case "darwin":
+		return []string{"/Library/Application Support"}
+	case "plan9":
+		return []string{"/lib"}
+	}
+	return xdgEnvList("XDG_CONFIG_DIRS", "/etc/xdg")
+}
+
+// UserDataDirs returns the preference-ordered list of system-wide
+// directories to search for data files, in addition to UserDataDir.
+//
+// On Unix systems, it returns the absolute entries of $XDG_DATA_DIRS, or
+// /usr/local/share and /usr/share if the variable is empty.
+// On Darwin, it returns /Library/Application Support.
+// On Windows, it returns %ProgramData% if defined.
+// On Plan 9, it returns /lib.
+func UserDataDirs() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return nonEmpty(Getenv("ProgramData"))
+	case "darwin":
+		return []string{"/Library/Application Support"}
+	case "plan9":
+		return []string{"/lib"}
+	}
+	return xdgEnvList("XDG_DATA_DIRS", "/usr/local/share:/usr/share")
+}
+
+// FindUserConfigFile looks for the file name, a path relative to the
+// configuration directories such as "myapp/config.toml", first in
+// UserConfigDir and then in each of UserConfigDirs. It returns the path of
+// the first one that exists.
+//
+// If the file is not found, the error is a *PathError satisfying
+// IsNotExist.
+func FindUserConfigFile(name string) (string, error) {
+	dir, _ := UserConfigDir()
+	return findUserFile(name, dir, UserConfigDirs())
+}
+
+// FindUserDataFile is like FindUserConfigFile but searches UserDataDir
+// and then UserDataDirs.
+func FindUserDataFile(name string) (string, error) {
+	dir, _ := UserDataDir()
+	return findUserFile(name, dir, UserDataDirs())
+}
+
+// findUserFile returns the first of home and dirs that contains name.
+// An empty home, as returned when it cannot be determined, is skipped.
+func findUserFile(name, home string, dirs []string) (string, error) {
+	if name == "" || IsPathSeparator(name[0]) {
+		return "", &PathError{"find", name, ErrInvalid}
+	}
+	if home != "" {
+		dirs = append([]string{home}, dirs...)
+	}
+	for _, dir := range dirs {
+		path := dir + string(PathSeparator) + name
+		if _, err := Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", &PathError{"find", name, ErrNotExist}
+}
+
+// xdgEnv returns the value of the XDG base directory variable key.
+// The specification requires relative paths to be treated as invalid
+// and ignored, so in that case it returns "" as though key were unset.
+func xdgEnv(key string) string {
+	dir := Getenv(key)
+	if dir == "" || dir[0] != '/' {
+		return ""
+	}
+	return dir
+}
+
+// xdgEnvList splits the colon-separated XDG search path in key, dropping
+// relative entries. If key is empty, def is used instead.
+func xdgEnvList(key, def string) []string {
+	list := Getenv(key)
+	if list == "" {
+		list = def
+	}
+	var dirs []string
+	for i := 0; i <= len(list); {
+		j := i
+		for j < len(list) && list[j] != ':' {
+			j++
+		}
+		if dir := list[i:j]; dir != "" && dir[0] == '/' {
+			dirs = append(dirs, dir)
+		}
+		i = j + 1
+	}
+	return dirs
+}
+
+func nonEmpty(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	return []string{dir}
+}
+
 // UserHomeDir returns the current user's home directory.
 //
 // On Unix, including macOS, it returns the $HOME environment variable.
@@ -0,0 +1,400 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+import (
+	"internal/poll"
+	"runtime"
+	"syscall"
+)
+
+// open(2)在Linux上能直接处理粘滞位，并且支持O_CLOEXEC。
+const (
+	supportsCreateWithStickyBit = true
+	supportsCloseOnExec         = true
+)
+
+// fixLongPath 在非Windows平台上不做任何处理。
+func fixLongPath(path string) string {
+	return path
+}
+
+// file 是*File的真实表示。
+// 额外的间接级别确保os的任何客户端都不能覆盖此数据，这可能导致终结器关闭错误的文件描述符。
+type file struct {
+	pfd         poll.FD  // 注：文件描述符
+	name        string   // 注：文件名
+	dirinfo     *dirInfo // nil，除非正在读取目录，注：记录目录信息
+	nonblock    bool     // 是否由我们设置了非阻塞模式
+	stdoutOrErr bool     // 是否为标准输出或标准错误
+	appendMode  bool     // 是否打开文件进行追加，注：是否可以追加数据
+}
+
+// Fd 返回引用打开文件的整数Unix文件描述符。
+// 只有在调用f.Close或f被垃圾回收之前，该文件描述符才有效。
+// 如果f被加入了运行时的网络轮询器，Fd会将其恢复为阻塞模式，这将导致SetDeadline方法停止工作。
+func (f *File) Fd() uintptr { // 注：获取f的文件描述符
+	if f == nil { // 注：如果f为空，返回无效的文件描述符
+		return ^(uintptr(0))
+	}
+
+	// 如果我们将文件描述符置为了非阻塞模式，则在返回之前将其恢复为阻塞模式，
+	// 因为从历史上看，我们总是返回以阻塞模式打开的文件描述符。
+	// File将继续工作，但是任何阻塞操作都将占用一个线程。
+	if f.nonblock {
+		f.pfd.SetBlocking()
+	}
+
+	return uintptr(f.pfd.Sysfd)
+}
+
+// NewFile 返回具有给定文件描述符和名称的新File。 如果fd不是有效的文件描述符，则返回的值为nil。
+// 如果fd已经处于非阻塞模式，NewFile会尝试将它加入运行时的网络轮询器，使SetDeadline可以工作。
+func NewFile(fd uintptr, name string) *File {
+	fdi := int(fd)
+	if fdi < 0 {
+		return nil
+	}
+	kind := kindNewFile
+	if nb, err := isNonblock(fdi); err == nil && nb {
+		kind = kindNonBlock
+	}
+	return newFile(fdi, name, kind)
+}
+
+// newFileKind 描述传递给newFile的文件的来源。
+type newFileKind int
+
+const (
+	kindNewFile  newFileKind = iota // 通过NewFile传入的文件描述符
+	kindOpenFile                    // 通过Open、Create或OpenFile打开的文件描述符
+	kindPipe                        // 通过Pipe创建的文件描述符
+	kindNonBlock                    // 通过NewFile传入的、已经处于非阻塞模式的文件描述符
+	kindNoPoll                      // 已知不能轮询的文件描述符，例如目录
+)
+
+// newFile 与NewFile类似，但如果文件来自OpenFile或Pipe（由kind参数指定），
+// 并且是管道、FIFO、字符设备（例如终端）、套接字或eventfd这类可以轮询的文件，
+// 则将其置为非阻塞模式并加入运行时的网络轮询器，此后Read和Write会遵守截止时间。
+func newFile(fd int, name string, kind newFileKind) *File { // 注：#
+	f := &File{&file{
+		pfd: poll.FD{
+			Sysfd:         fd,
+			IsStream:      true,
+			ZeroReadIsEOF: true,
+		},
+		name:        name,
+		stdoutOrErr: fd == 1 || fd == 2,
+	}}
+
+	pollable := kind == kindPipe || kind == kindNonBlock
+	if kind == kindOpenFile {
+		pollable = isPollableType(fd)
+	}
+
+	if kind == kindPipe || (kind == kindOpenFile && pollable) {
+		if err := syscall.SetNonblock(fd, true); err == nil {
+			f.nonblock = true
+		} else {
+			pollable = false
+		}
+	}
+
+	// 这里的错误表示无法注册到轮询器，例如epoll不支持的文件（如/dev/null）。
+	// 此时恢复阻塞模式；任何真正的错误都会在之后的I/O中出现。
+	if pollErr := f.pfd.Init("file", pollable); pollErr != nil && f.nonblock {
+		if err := syscall.SetNonblock(fd, false); err == nil {
+			f.nonblock = false
+		}
+	}
+
+	runtime.SetFinalizer(f.file, (*file).close)
+	return f
+}
+
+// isPollableType 报告fd是否是可能被epoll支持的文件类型：
+// 管道与FIFO、字符设备、套接字，以及eventfd、timerfd这类没有文件类型位的匿名inode文件。
+// 常规文件、目录与块设备总是就绪的，不加入轮询器。
+func isPollableType(fd int) bool { // 注：#
+	var st syscall.Stat_t
+	err := ignoringEINTR(func() error {
+		return syscall.Fstat(fd, &st)
+	})
+	if err != nil {
+		return false
+	}
+	switch st.Mode & syscall.S_IFMT {
+	case syscall.S_IFIFO, syscall.S_IFCHR, syscall.S_IFSOCK, 0:
+		return true
+	}
+	return false
+}
+
+// isNonblock 报告fd是否处于非阻塞模式。
+func isNonblock(fd int) (bool, error) {
+	flags, _, e := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFL, 0)
+	if e != 0 {
+		return false, e
+	}
+	return flags&syscall.O_NONBLOCK != 0, nil
+}
+
+// epipecheck 如果在标准输出或标准错误上遇到EPIPE错误，则触发SIGPIPE。
+func epipecheck(file *File, e error) {
+	if e == syscall.EPIPE && file.stdoutOrErr {
+		sigpipe()
+	}
+}
+
+// DevNull 是操作系统的“空设备”的名称。
+// 在类Unix系统上，它是"/dev/null"；在Windows上，它是"NUL"。
+const DevNull = "/dev/null"
+
+func (f *file) isdir() bool { return f != nil && f.dirinfo != nil }
+
+// openFileNolog 是OpenFile的Unix实现。
+func openFileNolog(name string, flag int, perm FileMode) (*File, error) { // 注：#
+	var r int
+	e := ignoringEINTR(func() error { // 注：open(2)可能在打开FIFO等慢速设备时被信号中断
+		var err error
+		r, err = syscall.Open(name, flag|syscall.O_CLOEXEC, syscallMode(perm))
+		return err
+	})
+	if e != nil {
+		return nil, &PathError{"open", name, e}
+	}
+	return newFile(r, name, kindOpenFile), nil
+}
+
+// Close 关闭文件，使其无法用于I/O。
+// 在支持SetDeadline的文件上，所有挂起的I/O操作都将被取消并立即返回错误。
+// 如果已经调用过Close，则返回错误。
+func (f *File) Close() error {
+	if f == nil {
+		return ErrInvalid
+	}
+	return f.file.close()
+}
+
+func (file *file) close() error {
+	if file == nil {
+		return syscall.EINVAL
+	}
+	if file.dirinfo != nil {
+		file.dirinfo.close()
+		file.dirinfo = nil
+	}
+	var err error
+	if e := file.pfd.Close(); e != nil {
+		if e == poll.ErrFileClosing {
+			e = ErrClosed
+		}
+		err = &PathError{"close", file.name, e}
+	}
+
+	// 不再需要终结器
+	runtime.SetFinalizer(file, nil)
+	return err
+}
+
+// read 从文件中读取多达len(b)个字节。
+// 返回读取的字节数和错误（如果有）。
+func (f *File) read(b []byte) (n int, err error) {
+	err = ignoringEINTR(func() error {
+		n, err = f.pfd.Read(b)
+		return err
+	})
+	runtime.KeepAlive(f)
+	return n, err
+}
+
+// pread 使用pread(2)从字节偏移量off开始读取len(b)个字节。
+// 返回读取的字节数和错误（如果有）。
+// EOF由计数为零且err为nil表示。
+func (f *File) pread(b []byte, off int64) (n int, err error) {
+	err = ignoringEINTR(func() error {
+		n, err = f.pfd.Pread(b, off)
+		return err
+	})
+	runtime.KeepAlive(f)
+	return n, err
+}
+
+// write 将len(b)个字节写入文件。
+// 返回写入的字节数和错误（如果有）。
+func (f *File) write(b []byte) (n int, err error) {
+	for {
+		m, e := f.pfd.Write(b[n:])
+		n += m
+		if e != syscall.EINTR || n == len(b) { // 注：被信号中断时从已写入的位置继续
+			err = e
+			break
+		}
+	}
+	runtime.KeepAlive(f)
+	return n, err
+}
+
+// pwrite 使用pwrite(2)从字节偏移量off开始将len(b)个字节写入文件。
+// 返回写入的字节数和错误（如果有）。
+func (f *File) pwrite(b []byte, off int64) (n int, err error) {
+	err = ignoringEINTR(func() error {
+		n, err = f.pfd.Pwrite(b, off)
+		return err
+	})
+	runtime.KeepAlive(f)
+	return n, err
+}
+
+// seek 将文件上下一次Read或Write的偏移量设置为offset，并根据whence进行解释：
+// 0表示相对于文件的起始位置，1表示相对于当前偏移量，2表示相对于文件末尾。
+// 它返回新的偏移量和错误（如果有）。
+func (f *File) seek(offset int64, whence int) (ret int64, err error) {
+	if f.dirinfo != nil {
+		// 释放缓存的目录信息，再次作为目录读取时会重新分配
+		f.dirinfo.close()
+		f.dirinfo = nil
+	}
+	ret, err = f.pfd.Seek(offset, whence)
+	runtime.KeepAlive(f)
+	return ret, err
+}
+
+// ignoringEINTR 调用fn，如果它返回EINTR错误则重复调用。
+func ignoringEINTR(fn func() error) error { // 注：#
+	for {
+		err := fn()
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// Truncate 更改命名文件的大小。
+// 如果文件是符号链接，它将更改链接目标的大小。
+// 如果有错误，它将是*PathError类型。
+func Truncate(name string, size int64) error {
+	e := ignoringEINTR(func() error {
+		return syscall.Truncate(name, size)
+	})
+	if e != nil {
+		return &PathError{"truncate", name, e}
+	}
+	return nil
+}
+
+// Remove 删除命名文件或（空）目录。
+// 如果有错误，它将是*PathError类型。
+func Remove(name string) error {
+	// 系统调用接口要求我们知道name是文件还是目录。
+	// 两者都尝试：平均而言，这比先执行Stat再选择正确的调用更便宜。
+	e := ignoringEINTR(func() error {
+		return syscall.Unlink(name)
+	})
+	if e == nil {
+		return nil
+	}
+	e1 := ignoringEINTR(func() error {
+		return syscall.Rmdir(name)
+	})
+	if e1 == nil {
+		return nil
+	}
+
+	// 都失败了：找出应该返回哪个错误。
+	// rmdir(file)返回ENOTDIR，据此判断哪个错误是真实的。
+	if e1 != syscall.ENOTDIR {
+		e = e1
+	}
+	return &PathError{"remove", name, e}
+}
+
+func rename(oldname, newname string) error {
+	fi, err := Lstat(newname)
+	if err == nil && fi.IsDir() {
+		// newname是已存在的目录时rename(2)可能成功（空目录），
+		// 但os.Rename的语义不允许替换目录。
+		if ofi, err := Lstat(oldname); err != nil {
+			if pe, ok := err.(*PathError); ok {
+				err = pe.Err
+			}
+			return &LinkError{"rename", oldname, newname, err}
+		} else if newname == oldname || !SameFile(fi, ofi) {
+			return &LinkError{"rename", oldname, newname, syscall.EEXIST}
+		}
+	}
+	err = ignoringEINTR(func() error {
+		return syscall.Rename(oldname, newname)
+	})
+	if err != nil {
+		return &LinkError{"rename", oldname, newname, err}
+	}
+	return nil
+}
+
+// Pipe 返回一对相连的文件；从r读取的是写入w的字节。
+// 它返回这两个文件和错误（如果有）。
+func Pipe() (r *File, w *File, err error) {
+	var p [2]int
+	e := syscall.Pipe2(p[0:], syscall.O_CLOEXEC)
+	if e != nil {
+		return nil, nil, NewSyscallError("pipe2", e)
+	}
+	return newFile(p[0], "|0", kindPipe), newFile(p[1], "|1", kindPipe), nil
+}
+
+func tempDir() string {
+	dir := Getenv("TMPDIR")
+	if dir == "" {
+		dir = "/tmp"
+	}
+	return dir
+}
+
+// Link 创建newname作为指向oldname文件的硬链接。
+// 如果有错误，它将是*LinkError类型。
+func Link(oldname, newname string) error {
+	e := ignoringEINTR(func() error {
+		return syscall.Link(oldname, newname)
+	})
+	if e != nil {
+		return &LinkError{"link", oldname, newname, e}
+	}
+	return nil
+}
+
+// Symlink 创建newname作为指向oldname的符号链接。
+// 如果有错误，它将是*LinkError类型。
+func Symlink(oldname, newname string) error {
+	e := ignoringEINTR(func() error {
+		return syscall.Symlink(oldname, newname)
+	})
+	if e != nil {
+		return &LinkError{"symlink", oldname, newname, e}
+	}
+	return nil
+}
+
+// Readlink 返回命名符号链接的目标。
+// 如果有错误，它将是*PathError类型。
+func Readlink(name string) (string, error) {
+	for len := 128; ; len *= 2 {
+		b := make([]byte, len)
+		var n int
+		e := ignoringEINTR(func() error {
+			var err error
+			n, err = fixCount(syscall.Readlink(name, b))
+			return err
+		})
+		if e != nil {
+			return "", &PathError{"readlink", name, e}
+		}
+		if n < len {
+			return string(b[0:n]), nil
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "os-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// removeAll removes path and any children it contains, ignoring errors.
+func removeAll(path string) {
+	fi, err := Lstat(path)
+	if err != nil {
+		return
+	}
+	if fi.IsDir() {
+		Chmod(path, 0700)
+		if f, err := Open(path); err == nil {
+			names, _ := f.Readdirnames(-1)
+			f.Close()
+			for _, name := range names {
+				removeAll(filepath.Join(path, name))
+			}
+		}
+	}
+	Remove(path)
+}
+
+func TestFileReadWriteSeek(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+
+	f, err := OpenFile(name, O_RDWR|O_CREATE|O_EXCL, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := OpenFile(name, O_RDWR|O_CREATE|O_EXCL, 0644); !IsExist(err) {
+		t.Errorf("OpenFile(O_EXCL) on existing file: got %v, want IsExist", err)
+	}
+	if n, err := f.Write([]byte("hello, world")); n != 12 || err != nil {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	if off, err := f.Seek(7, io.SeekStart); off != 7 || err != nil {
+		t.Fatalf("Seek() = %d, %v", off, err)
+	}
+	b := make([]byte, 10)
+	n, err := f.Read(b)
+	if string(b[:n]) != "world" || err != nil {
+		t.Errorf("Read() = %q, %v, want %q", b[:n], err, "world")
+	}
+	if n, err := f.Read(b); n != 0 || err != io.EOF {
+		t.Errorf("Read() at end = %d, %v, want 0, EOF", n, err)
+	}
+	if n, err := f.ReadAt(b[:4], 0); string(b[:n]) != "hell" || err != nil {
+		t.Errorf("ReadAt() = %q, %v", b[:n], err)
+	}
+	if err := f.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := f.Stat(); err != nil || fi.Size() != 5 {
+		t.Errorf("Stat() after Truncate(5) = %v, %v", fi, err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err == nil {
+		t.Error("second Close() = nil, want error")
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestRemoveAndTruncate(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Truncate(name, 3); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := Stat(name); err != nil || fi.Size() != 3 {
+		t.Errorf("Stat() after Truncate = %v, %v", fi, err)
+	}
+
+	sub := filepath.Join(dir, "sub")
+	if err := Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{name, sub} {
+		if err := Remove(p); err != nil {
+			t.Errorf("Remove(%q) = %v", p, err)
+		}
+		if _, err := Lstat(p); !IsNotExist(err) {
+			t.Errorf("Lstat(%q) after Remove = %v, want IsNotExist", p, err)
+		}
+	}
+	err := Remove(name)
+	if pe, ok := err.(*PathError); !ok || pe.Op != "remove" || !IsNotExist(err) {
+		t.Errorf("Remove(missing) = %v, want *PathError with IsNotExist", err)
+	}
+}
+
+func TestLinkSymlinkReadlink(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hard := filepath.Join(dir, "hard")
+	if err := Link(name, hard); err != nil {
+		t.Fatal(err)
+	}
+	fi1, _ := Stat(name)
+	fi2, _ := Stat(hard)
+	if !SameFile(fi1, fi2) {
+		t.Errorf("Link(): %q and %q are not the same file", name, hard)
+	}
+	if err := Link(name, hard); err == nil {
+		t.Error("Link() onto existing name = nil, want error")
+	} else if _, ok := err.(*LinkError); !ok {
+		t.Errorf("Link() error is %T, want *LinkError", err)
+	}
+
+	sym := filepath.Join(dir, "sym")
+	if err := Symlink("file", sym); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := Readlink(sym); target != "file" || err != nil {
+		t.Errorf("Readlink() = %q, %v, want %q", target, err, "file")
+	}
+	long := strings.Repeat("x/", 200) + "end"
+	if err := Symlink(long, filepath.Join(dir, "long")); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := Readlink(filepath.Join(dir, "long")); target != long || err != nil {
+		t.Errorf("Readlink() of long target = %d bytes, %v, want %d bytes", len(target), err, len(long))
+	}
+	if _, err := Readlink(name); err == nil {
+		t.Errorf("Readlink(%q) of regular file = nil, want error", name)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go func() {
+		w.Write([]byte("through the pipe"))
+		w.Close()
+	}()
+	b, err := ioutil.ReadAll(r)
+	if string(b) != "through the pipe" || err != nil {
+		t.Errorf("ReadAll(pipe) = %q, %v", b, err)
+	}
+}
+
+func TestReaddir(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	var want []string
+	for i := 0; i < 300; i++ {
+		name := strings.Repeat("n", i%50+1) + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		want = append(want, name)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(want)
+
+	f, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []string
+	for {
+		names, err := f.Readdirnames(7)
+		got = append(got, names...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Readdirnames() returned %d names, want %d", len(got), len(want))
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil || len(fis) != len(want) {
+		t.Errorf("ReadDir() = %d entries, %v, want %d", len(fis), err, len(want))
+	}
+	f2, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	all, err := f2.Readdir(-1)
+	if err != nil || len(all) != len(want) {
+		t.Errorf("Readdir(-1) = %d entries, %v, want %d", len(all), err, len(want))
+	}
+	for _, fi := range all {
+		if fi.IsDir() || fi.Size() != 0 {
+			t.Errorf("Readdir(): %s: got IsDir %v, size %d", fi.Name(), fi.IsDir(), fi.Size())
+		}
+	}
+}
+
+func TestGetwd(t *testing.T) {
+	old, err := Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Chdir(old)
+	dir := tempDir(t)
+	defer removeAll(dir)
+	if err := Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(wd); got != want {
+		t.Errorf("Getwd() = %q, want %q", wd, dir)
+	}
+}
+
+func TestSyscallConn(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	rc, err := w.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fd uintptr
+	if err := rc.Control(func(s uintptr) { fd = s }); err != nil {
+		t.Fatal(err)
+	}
+	if fd != w.Fd() {
+		t.Errorf("Control() got fd %d, want %d", fd, w.Fd())
+	}
+	var n int
+	err = rc.Write(func(s uintptr) bool {
+		n, err = syscall.Write(int(s), []byte("raw"))
+		return err != syscall.EAGAIN
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("RawConn.Write() = %d, %v", n, err)
+	}
+	b := make([]byte, 3)
+	if _, err := io.ReadFull(r, b); err != nil || string(b) != "raw" {
+		t.Errorf("read back %q, %v", b, err)
+	}
+	w.Close()
+	if err := rc.Control(func(uintptr) {}); err == nil {
+		t.Error("Control() after Close = nil, want error")
+	}
+}
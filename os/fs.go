@@ -0,0 +1,151 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"io"
+	"time"
+)
+
+// FS 是文件系统的抽象，其方法与本包中的同名函数具有相同的语义。
+// 代码通过FS而不是直接调用Open、Mkdir等函数访问文件，就可以在测试中改用MemFS或OverlayFS。
+//
+// 所有实现返回的错误都与本包函数返回的错误类型相同（*PathError或*LinkError），
+// 因此IsNotExist、IsExist、IsPermission等检查可以照常使用。
+type FS interface {
+	Open(name string) (FSFile, error)
+	Create(name string) (FSFile, error)
+	OpenFile(name string, flag int, perm FileMode) (FSFile, error)
+	Mkdir(name string, perm FileMode) error
+	Remove(name string) error
+	Rename(oldpath, newpath string) error
+	Stat(name string) (FileInfo, error)
+	Lstat(name string) (FileInfo, error)
+	Chmod(name string, mode FileMode) error
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+	Truncate(name string, size int64) error
+	ReadDir(name string) ([]DirEntry, error)
+}
+
+// FSFile 是FS打开的文件，其方法与*File的同名方法具有相同的语义。*File实现了FSFile。
+type FSFile interface {
+	io.Reader
+	io.ReaderAt
+	io.Writer
+	io.WriterAt
+	io.Seeker
+	io.Closer
+	Name() string
+	Stat() (FileInfo, error)
+	Readdir(n int) ([]FileInfo, error)
+	Readdirnames(n int) ([]string, error)
+	ReadDir(n int) ([]DirEntry, error)
+	Chmod(mode FileMode) error
+	Truncate(size int64) error
+	Sync() error
+	WriteString(s string) (int, error)
+}
+
+// OSFS 是由操作系统提供的FS，它的每个方法都直接调用本包的同名函数。
+type OSFS struct{}
+
+var _ FS = OSFS{}
+
+func (OSFS) Open(name string) (FSFile, error) { return fsFile(Open(name)) }
+
+func (OSFS) Create(name string) (FSFile, error) { return fsFile(Create(name)) }
+
+func (OSFS) OpenFile(name string, flag int, perm FileMode) (FSFile, error) {
+	return fsFile(OpenFile(name, flag, perm))
+}
+
+func (OSFS) Mkdir(name string, perm FileMode) error  { return Mkdir(name, perm) }
+func (OSFS) Remove(name string) error                { return Remove(name) }
+func (OSFS) Rename(oldpath, newpath string) error    { return Rename(oldpath, newpath) }
+func (OSFS) Stat(name string) (FileInfo, error)      { return Stat(name) }
+func (OSFS) Lstat(name string) (FileInfo, error)     { return Lstat(name) }
+func (OSFS) Chmod(name string, mode FileMode) error  { return Chmod(name, mode) }
+func (OSFS) Truncate(name string, size int64) error  { return Truncate(name, size) }
+func (OSFS) ReadDir(name string) ([]DirEntry, error) { return ReadDir(name) }
+
+func (OSFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return Chtimes(name, atime, mtime)
+}
+
+// fsFile 将Open等函数的结果转换为FSFile，避免出错时返回包含nil *File的非nil接口。
+func fsFile(f *File, err error) (FSFile, error) {
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// cleanFSPath 返回与name等价的最短路径，规则与path.Clean相同：
+// 合并多个斜杠，去掉"."，并在可能时消去".."。空路径变为"."。
+func cleanFSPath(name string) string { // 注：例："a//b/../c/" => "a/c"
+	rooted := name != "" && name[0] == '/'
+	s := ""
+	for i, p := range fsPathParts(name, rooted) {
+		if i > 0 {
+			s += "/"
+		}
+		s += p
+	}
+	if rooted {
+		return "/" + s
+	}
+	if s == "" {
+		return "."
+	}
+	return s
+}
+
+// fsPathParts 将name拆分为路径元素，去掉空元素和"."并消去".."。
+// rooted为true时，位于根目录的".."被丢弃。
+func fsPathParts(name string, rooted bool) []string {
+	var parts []string
+	for i := 0; i < len(name); {
+		j := i
+		for j < len(name) && name[j] != '/' {
+			j++
+		}
+		switch p := name[i:j]; {
+		case p == "" || p == ".":
+		case p == ".." && len(parts) > 0 && parts[len(parts)-1] != "..":
+			parts = parts[:len(parts)-1]
+		case p == ".." && rooted:
+		default:
+			parts = append(parts, p)
+		}
+		i = j + 1
+	}
+	return parts
+}
+
+// fsPathDir 返回已清理路径name的父目录。根目录和"."的父目录是它们自身。
+func fsPathDir(name string) string { // 注：例："a/b" => "a"，"/a" => "/"，"a" => "."
+	i := len(name) - 1
+	for i >= 0 && name[i] != '/' {
+		i--
+	}
+	switch {
+	case i < 0:
+		return "."
+	case i == 0:
+		return "/"
+	}
+	return name[:i]
+}
+
+// fsPathJoin 连接已清理的目录路径dir和名称name。
+func fsPathJoin(dir, name string) string {
+	switch {
+	case dir == ".":
+		return name
+	case dir[len(dir)-1] == '/':
+		return dir + name
+	}
+	return dir + "/" + name
+}
@@ -0,0 +1,607 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS 是完全保存在内存中的FS，主要用于测试。
+//
+// 所有路径都是以斜杠分隔的，并相对于MemFS的根目录解析，"/a"和"a"指同一个文件。
+// 文件和目录记录FileMode和ModTime：创建时使用perm作为权限位（不应用umask），
+// 写入和截断文件会更新它的修改时间，在目录中创建、删除或重命名项会更新目录的修改时间。
+// 打开已存在的文件时检查所有者的读写权限位，目录的权限位不参与检查。
+// MemFS不支持符号链接，Lstat与Stat相同。
+//
+// MemFS可以被多个goroutine同时使用。
+type MemFS struct {
+	mu   sync.Mutex
+	root *memNode
+}
+
+var _ FS = (*MemFS)(nil)
+
+// memNode 是MemFS中的一个文件或目录。
+type memNode struct {
+	name     string
+	mode     FileMode
+	modTime  time.Time
+	data     []byte
+	children map[string]*memNode // 仅用于目录
+}
+
+// memPermBits 是Chmod和创建文件时可以设置的模式位。
+const memPermBits = ModePerm | ModeSetuid | ModeSetgid | ModeSticky
+
+// NewMemFS 返回只包含一个空的根目录的MemFS。
+func NewMemFS() *MemFS {
+	return &MemFS{root: newMemDir("/", 0755)}
+}
+
+func newMemDir(name string, perm FileMode) *memNode {
+	return &memNode{
+		name:     name,
+		mode:     ModeDir | perm&memPermBits,
+		modTime:  time.Now(),
+		children: make(map[string]*memNode),
+	}
+}
+
+func (n *memNode) info() FileInfo {
+	return &memFileInfo{
+		name:    n.name,
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+// memFileInfo 是MemFS中文件在某一时刻的FileInfo快照。
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    FileMode
+	modTime time.Time
+}
+
+func (fi *memFileInfo) Name() string       { return fi.name }
+func (fi *memFileInfo) Size() int64        { return fi.size }
+func (fi *memFileInfo) Mode() FileMode     { return fi.mode }
+func (fi *memFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *memFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *memFileInfo) Sys() interface{}   { return nil }
+
+// lookup 返回名为name的节点。调用者必须持有fs.mu。
+func (fs *MemFS) lookup(name string) (*memNode, error) {
+	n := fs.root
+	for _, p := range fsPathParts(name, true) {
+		if !n.mode.IsDir() {
+			return nil, syscall.ENOTDIR
+		}
+		n = n.children[p]
+		if n == nil {
+			return nil, syscall.ENOENT
+		}
+	}
+	return n, nil
+}
+
+// lookupParent 返回name的父目录节点以及name的最后一个元素。
+// name是根目录时base为空。调用者必须持有fs.mu。
+func (fs *MemFS) lookupParent(name string) (dir *memNode, base string, err error) { // 注：例："a/b/c" => (a/b的节点, "c")
+	parts := fsPathParts(name, true)
+	if len(parts) == 0 {
+		return nil, "", nil
+	}
+	dir = fs.root
+	for _, p := range parts[:len(parts)-1] {
+		dir = dir.children[p]
+		if dir == nil {
+			return nil, "", syscall.ENOENT
+		}
+		if !dir.mode.IsDir() {
+			return nil, "", syscall.ENOTDIR
+		}
+	}
+	return dir, parts[len(parts)-1], nil
+}
+
+// Open 打开命名文件以进行读取。
+func (fs *MemFS) Open(name string) (FSFile, error) {
+	return fs.OpenFile(name, O_RDONLY, 0)
+}
+
+// Create 创建或截断命名文件，它的权限位为0666。
+func (fs *MemFS) Create(name string) (FSFile, error) {
+	return fs.OpenFile(name, O_RDWR|O_CREATE|O_TRUNC, 0666)
+}
+
+// OpenFile 使用指定的标志（O_RDONLY等）打开命名文件。
+// 如果文件不存在并且传递了O_CREATE，则以权限位perm创建文件。
+func (fs *MemFS) OpenFile(name string, flag int, perm FileMode) (FSFile, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dir, base, err := fs.lookupParent(name)
+	if err != nil {
+		return nil, &PathError{"open", name, err}
+	}
+	n := fs.root
+	if dir != nil {
+		n = dir.children[base]
+	}
+	access := flag & (O_RDONLY | O_WRONLY | O_RDWR)
+	switch {
+	case n == nil:
+		if flag&O_CREATE == 0 {
+			return nil, &PathError{"open", name, syscall.ENOENT}
+		}
+		n = &memNode{name: base, mode: perm & memPermBits, modTime: time.Now()}
+		dir.children[base] = n
+		dir.modTime = n.modTime
+	case flag&(O_CREATE|O_EXCL) == O_CREATE|O_EXCL:
+		return nil, &PathError{"open", name, syscall.EEXIST}
+	case n.mode.IsDir() && access != O_RDONLY:
+		return nil, &PathError{"open", name, syscall.EISDIR}
+	case access != O_WRONLY && n.mode&0400 == 0,
+		access != O_RDONLY && n.mode&0200 == 0:
+		return nil, &PathError{"open", name, syscall.EACCES}
+	case flag&O_TRUNC != 0 && access != O_RDONLY:
+		n.data = nil
+		n.modTime = time.Now()
+	}
+	return &memFile{fs: fs, node: n, name: name, flag: flag}, nil
+}
+
+// Mkdir 以权限位perm创建一个新目录。
+func (fs *MemFS) Mkdir(name string, perm FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dir, base, err := fs.lookupParent(name)
+	if err != nil {
+		return &PathError{"mkdir", name, err}
+	}
+	if dir == nil || dir.children[base] != nil {
+		return &PathError{"mkdir", name, syscall.EEXIST}
+	}
+	n := newMemDir(base, perm)
+	dir.children[base] = n
+	dir.modTime = n.modTime
+	return nil
+}
+
+// Remove 删除命名文件或空目录。
+func (fs *MemFS) Remove(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	dir, base, err := fs.lookupParent(name)
+	if err != nil {
+		return &PathError{"remove", name, err}
+	}
+	if dir == nil {
+		return &PathError{"remove", name, syscall.EBUSY}
+	}
+	n := dir.children[base]
+	if n == nil {
+		return &PathError{"remove", name, syscall.ENOENT}
+	}
+	if len(n.children) > 0 {
+		return &PathError{"remove", name, syscall.ENOTEMPTY}
+	}
+	delete(dir.children, base)
+	dir.modTime = time.Now()
+	return nil
+}
+
+// Rename 将oldpath重命名为newpath。如果newpath已经存在并且不是目录，则Rename替换它。
+func (fs *MemFS) Rename(oldpath, newpath string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	odir, obase, err := fs.lookupParent(oldpath)
+	if err == nil && odir == nil {
+		err = syscall.EBUSY
+	}
+	var n *memNode
+	if err == nil {
+		if n = odir.children[obase]; n == nil {
+			err = syscall.ENOENT
+		}
+	}
+	if err != nil {
+		return &LinkError{"rename", oldpath, newpath, err}
+	}
+	ndir, nbase, err := fs.lookupParent(newpath)
+	if err == nil && ndir == nil {
+		err = syscall.EBUSY
+	}
+	if err != nil {
+		return &LinkError{"rename", oldpath, newpath, err}
+	}
+	if old := ndir.children[nbase]; old != nil {
+		if old == n {
+			return nil
+		}
+		// 与Rename相同，不允许替换已存在的目录。
+		if old.mode.IsDir() {
+			return &LinkError{"rename", oldpath, newpath, syscall.EEXIST}
+		}
+		if n.mode.IsDir() {
+			return &LinkError{"rename", oldpath, newpath, syscall.ENOTDIR}
+		}
+	}
+	// 不能将目录移动到它自己的子目录中。
+	if n.mode.IsDir() && hasFSPathPrefix(fsPathParts(newpath, true), fsPathParts(oldpath, true)) {
+		return &LinkError{"rename", oldpath, newpath, syscall.EINVAL}
+	}
+
+	now := time.Now()
+	delete(odir.children, obase)
+	n.name = nbase
+	ndir.children[nbase] = n
+	odir.modTime = now
+	ndir.modTime = now
+	return nil
+}
+
+// hasFSPathPrefix 报告路径元素列表path是否以prefix开头。
+func hasFSPathPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if path[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// Stat 返回描述命名文件的FileInfo。
+func (fs *MemFS) Stat(name string) (FileInfo, error) {
+	return fs.stat("stat", name)
+}
+
+// Lstat 与Stat相同，因为MemFS不支持符号链接。
+func (fs *MemFS) Lstat(name string) (FileInfo, error) {
+	return fs.stat("lstat", name)
+}
+
+func (fs *MemFS) stat(op, name string) (FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	n, err := fs.lookup(name)
+	if err != nil {
+		return nil, &PathError{op, name, err}
+	}
+	return n.info(), nil
+}
+
+// Chmod 将命名文件的模式更改为mode。只使用mode中的权限位以及ModeSetuid、ModeSetgid和ModeSticky。
+func (fs *MemFS) Chmod(name string, mode FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	n, err := fs.lookup(name)
+	if err != nil {
+		return &PathError{"chmod", name, err}
+	}
+	n.mode = n.mode&^memPermBits | mode&memPermBits
+	return nil
+}
+
+// Chtimes 更改命名文件的修改时间。MemFS不记录访问时间，atime被忽略。
+func (fs *MemFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	n, err := fs.lookup(name)
+	if err != nil {
+		return &PathError{"chtimes", name, err}
+	}
+	n.modTime = mtime
+	return nil
+}
+
+// Truncate 更改命名文件的大小。
+func (fs *MemFS) Truncate(name string, size int64) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	n, err := fs.lookup(name)
+	if err == nil {
+		err = n.truncate(size)
+	}
+	if err != nil {
+		return &PathError{"truncate", name, err}
+	}
+	return nil
+}
+
+// ReadDir 读取命名目录，返回按文件名排序的所有目录项。
+func (fs *MemFS) ReadDir(name string) ([]DirEntry, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.ReadDir(-1)
+}
+
+// truncate 将n的数据调整为size字节。调用者必须持有fs.mu。
+func (n *memNode) truncate(size int64) error {
+	if n.mode.IsDir() {
+		return syscall.EISDIR
+	}
+	if size < 0 {
+		return syscall.EINVAL
+	}
+	if size <= int64(len(n.data)) {
+		n.data = n.data[:size]
+	} else {
+		n.data = append(n.data, make([]byte, size-int64(len(n.data)))...)
+	}
+	n.modTime = time.Now()
+	return nil
+}
+
+// memFile 是MemFS.OpenFile返回的FSFile。
+type memFile struct {
+	fs     *MemFS
+	node   *memNode
+	name   string
+	flag   int
+	offset int64
+	closed bool
+
+	dirNames []string // 第一次读取目录时的文件名快照
+	dirPos   int
+}
+
+// check 检查f是否可以执行op。调用者必须持有f.fs.mu。
+func (f *memFile) check(op string, write bool) error {
+	if f.closed {
+		return &PathError{op, f.name, ErrClosed}
+	}
+	access := f.flag & (O_RDONLY | O_WRONLY | O_RDWR)
+	if write && access == O_RDONLY || !write && access == O_WRONLY {
+		return &PathError{op, f.name, syscall.EBADF}
+	}
+	if f.node.mode.IsDir() && (op == "read" || op == "write") {
+		return &PathError{op, f.name, syscall.EISDIR}
+	}
+	return nil
+}
+
+func (f *memFile) Name() string { return f.name }
+
+func (f *memFile) Read(b []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+	n, err := f.readAt(b, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *memFile) ReadAt(b []byte, off int64) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, &PathError{"readat", f.name, errors.New("negative offset")}
+	}
+	n, err := f.readAt(b, off)
+	if err == nil && n < len(b) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (f *memFile) readAt(b []byte, off int64) (int, error) {
+	if off >= int64(len(f.node.data)) {
+		if len(b) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	return copy(b, f.node.data[off:]), nil
+}
+
+func (f *memFile) Write(b []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if f.flag&O_APPEND != 0 {
+		f.offset = int64(len(f.node.data))
+	}
+	f.writeAt(b, f.offset)
+	f.offset += int64(len(b))
+	return len(b), nil
+}
+
+func (f *memFile) WriteAt(b []byte, off int64) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if f.flag&O_APPEND != 0 {
+		return 0, errWriteAtInAppendMode
+	}
+	if off < 0 {
+		return 0, &PathError{"writeat", f.name, errors.New("negative offset")}
+	}
+	f.writeAt(b, off)
+	return len(b), nil
+}
+
+func (f *memFile) writeAt(b []byte, off int64) {
+	n := f.node
+	if end := off + int64(len(b)); end > int64(len(n.data)) {
+		n.data = append(n.data, make([]byte, end-int64(len(n.data)))...)
+	}
+	copy(n.data[off:], b)
+	n.modTime = time.Now()
+}
+
+func (f *memFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return 0, &PathError{"seek", f.name, ErrClosed}
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.node.data))
+	default:
+		return 0, &PathError{"seek", f.name, syscall.EINVAL}
+	}
+	if offset < 0 {
+		return 0, &PathError{"seek", f.name, syscall.EINVAL}
+	}
+	f.offset = offset
+	if f.node.mode.IsDir() && offset == 0 {
+		f.dirNames, f.dirPos = nil, 0
+	}
+	return offset, nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return &PathError{"close", f.name, ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memFile) Stat() (FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return nil, &PathError{"stat", f.name, ErrClosed}
+	}
+	return f.node.info(), nil
+}
+
+func (f *memFile) Chmod(mode FileMode) error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return &PathError{"chmod", f.name, ErrClosed}
+	}
+	f.node.mode = f.node.mode&^memPermBits | mode&memPermBits
+	return nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if err := f.check("truncate", true); err != nil {
+		return err
+	}
+	if err := f.node.truncate(size); err != nil {
+		return &PathError{"truncate", f.name, err}
+	}
+	return nil
+}
+
+func (f *memFile) Sync() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return &PathError{"sync", f.name, ErrClosed}
+	}
+	return nil
+}
+
+func (f *memFile) Readdir(n int) ([]FileInfo, error) {
+	return f.readdir(n)
+}
+
+func (f *memFile) Readdirnames(n int) ([]string, error) {
+	infos, err := f.readdir(n)
+	names := make([]string, len(infos))
+	for i, fi := range infos {
+		names[i] = fi.Name()
+	}
+	return names, err
+}
+
+func (f *memFile) ReadDir(n int) ([]DirEntry, error) {
+	infos, err := f.readdir(n)
+	dirents := make([]DirEntry, len(infos))
+	for i, fi := range infos {
+		dirents[i] = &statDirEntry{fi}
+	}
+	return dirents, err
+}
+
+// readdir 按文件名顺序返回目录中接下来最多n项的FileInfo，n <= 0时返回剩余的所有项。
+// 读取开始后被删除的项会被跳过。
+func (f *memFile) readdir(n int) ([]FileInfo, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.closed {
+		return nil, &PathError{"readdirent", f.name, ErrClosed}
+	}
+	if !f.node.mode.IsDir() {
+		return nil, &PathError{"readdirent", f.name, syscall.ENOTDIR}
+	}
+	if f.dirNames == nil {
+		f.dirNames = make([]string, 0, len(f.node.children))
+		for name := range f.node.children {
+			f.dirNames = append(f.dirNames, name)
+		}
+		sort.Strings(f.dirNames)
+	}
+	var infos []FileInfo
+	for f.dirPos < len(f.dirNames) && (n <= 0 || len(infos) < n) {
+		c := f.node.children[f.dirNames[f.dirPos]]
+		f.dirPos++
+		if c != nil {
+			infos = append(infos, c.info())
+		}
+	}
+	if n > 0 && len(infos) == 0 {
+		return nil, io.EOF
+	}
+	return infos, nil
+}
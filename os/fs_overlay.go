@@ -0,0 +1,483 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// OverlayFS 是将一个可写的FS（上层）叠加在另一个FS（下层）之上的写时复制文件系统。
+//
+// 读取时先查找上层，上层不存在时再查找下层；目录的内容是两层的并集，同名项以上层为准。
+// 下层永远不会被修改：写入、截断、Chmod或Chtimes下层的文件时，先将它连同缺少的父目录复制到上层；
+// 删除或重命名只存在于下层的项时，OverlayFS记录该路径已被删除，此后下层中该路径及其下的所有项都不可见。
+// 重命名下层的目录时，会将整个目录树复制到上层。
+//
+// 路径在比较前会被清理（见path.Clean），因此"a/b"和"a//b/"指同一个文件；
+// 但相对路径与绝对路径不会被视为相同，见removedKey。
+// 典型的用法是将MemFS叠加在OSFS之上，让测试可以修改真实目录树的副本而不影响磁盘。
+type OverlayFS struct {
+	upper, lower FS
+
+	mu      sync.Mutex
+	removed map[string]bool // 下层中已被删除或覆盖的路径，键由removedKey生成
+}
+
+var _ FS = (*OverlayFS)(nil)
+
+// NewOverlayFS 返回将upper叠加在lower之上的OverlayFS。所有修改都只写入upper。
+func NewOverlayFS(upper, lower FS) *OverlayFS {
+	return &OverlayFS{
+		upper:   upper,
+		lower:   lower,
+		removed: make(map[string]bool),
+	}
+}
+
+// removedKey 返回name在o.removed中的键，即清理后的name。
+// 相对路径不会被转换为绝对路径：对OSFS而言"a"相对于当前目录，与"/a"是不同的文件，
+// 删除"etc"不能隐藏"/etc"。因此同一个文件应当始终以同一种写法（都用相对路径或都用绝对路径）访问。
+func removedKey(name string) string { // 注：例："a//b/" => "a/b"，"/a/../b" => "/b"
+	return cleanFSPath(name)
+}
+
+// hidden 报告下层中的name是否因为它自身或某个父目录被删除而不可见。调用者必须持有o.mu。
+func (o *OverlayFS) hidden(name string) bool {
+	name = removedKey(name)
+	for {
+		if o.removed[name] {
+			return true
+		}
+		dir := fsPathDir(name)
+		if dir == name {
+			return false
+		}
+		name = dir
+	}
+}
+
+// inUpper 报告name是否存在于上层。
+func (o *OverlayFS) inUpper(name string) bool {
+	_, err := o.upper.Lstat(name)
+	return err == nil
+}
+
+// inLower 报告name是否存在于下层并且可见。调用者必须持有o.mu。
+func (o *OverlayFS) inLower(name string) bool {
+	if o.hidden(name) {
+		return false
+	}
+	_, err := o.lower.Lstat(name)
+	return err == nil
+}
+
+// stat 返回合并视图中name的FileInfo。调用者必须持有o.mu。
+func (o *OverlayFS) stat(op, name string) (FileInfo, error) {
+	statFn := FS.Stat
+	if op == "lstat" {
+		statFn = FS.Lstat
+	}
+	fi, err := statFn(o.upper, name)
+	if err == nil || !IsNotExist(err) {
+		return fi, err
+	}
+	if o.hidden(name) {
+		return nil, &PathError{op, name, syscall.ENOENT}
+	}
+	return statFn(o.lower, name)
+}
+
+// readDir 返回合并视图中目录name的内容，按文件名排序。调用者必须持有o.mu。
+func (o *OverlayFS) readDir(name string) ([]DirEntry, error) {
+	upper, err := o.upper.ReadDir(name)
+	if err != nil && !IsNotExist(err) {
+		return nil, err
+	}
+	if o.hidden(name) {
+		if err != nil {
+			return nil, &PathError{"open", name, syscall.ENOENT}
+		}
+		return upper, nil
+	}
+	lower, lerr := o.lower.ReadDir(name)
+	if err != nil {
+		// 目录只存在于下层。
+		if lerr != nil {
+			return nil, lerr
+		}
+		upper = nil
+	}
+
+	seen := make(map[string]bool, len(upper))
+	dirents := upper
+	for _, d := range upper {
+		seen[d.Name()] = true
+	}
+	for _, d := range lower {
+		if !seen[d.Name()] && !o.removed[removedKey(fsPathJoin(name, d.Name()))] {
+			dirents = append(dirents, d)
+		}
+	}
+	sort.Slice(dirents, func(i, j int) bool { return dirents[i].Name() < dirents[j].Name() })
+	return dirents, nil
+}
+
+// copyUp 将下层的name复制到上层，使之后的修改只作用于上层。
+// name已在上层时什么也不做。如果tree为true，目录的全部内容也会被复制。
+// 调用者必须持有o.mu。
+func (o *OverlayFS) copyUp(op, name string, tree bool) error {
+	if o.inUpper(name) {
+		if !tree {
+			return nil
+		}
+		fi, err := o.upper.Stat(name)
+		if err != nil || !fi.IsDir() {
+			return err
+		}
+		return o.copyChildrenUp(op, name)
+	}
+	fi, err := o.stat("stat", name)
+	if err != nil {
+		return err
+	}
+	if err := o.ensureUpperDir(op, fsPathDir(name)); err != nil {
+		return err
+	}
+	switch {
+	case fi.IsDir():
+		// 先以可写的权限创建目录，复制完内容后再设置原来的模式。
+		err = o.upper.Mkdir(name, 0700)
+		if err == nil && tree {
+			err = o.copyChildrenUp(op, name)
+		}
+	case fi.Mode().IsRegular():
+		err = o.copyFileUp(name)
+	default:
+		err = &PathError{op, name, syscall.EXDEV}
+	}
+	if err == nil {
+		err = o.upper.Chmod(name, fi.Mode())
+	}
+	if err == nil {
+		err = o.upper.Chtimes(name, fi.ModTime(), fi.ModTime())
+	}
+	return err
+}
+
+// copyChildrenUp 将合并视图中目录dir的全部内容复制到上层。调用者必须持有o.mu。
+func (o *OverlayFS) copyChildrenUp(op, dir string) error {
+	dirents, err := o.readDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirents {
+		if err := o.copyUp(op, fsPathJoin(dir, d.Name()), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFileUp 将下层的普通文件name的内容复制到上层。
+func (o *OverlayFS) copyFileUp(name string) error {
+	src, err := o.lower.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := o.upper.OpenFile(name, O_WRONLY|O_CREATE|O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// ensureUpperDir 确保目录dir存在于上层，必要时从下层复制它和它的父目录。
+// 如果dir在合并视图中不存在或不是目录，则返回操作为op的*PathError。
+// 调用者必须持有o.mu。
+func (o *OverlayFS) ensureUpperDir(op, dir string) error {
+	if o.inUpper(dir) {
+		return nil
+	}
+	fi, err := o.stat("stat", dir)
+	if err != nil {
+		return &PathError{op, dir, underlyingError(err)}
+	}
+	if !fi.IsDir() {
+		return &PathError{op, dir, syscall.ENOTDIR}
+	}
+	return o.copyUp(op, dir, false)
+}
+
+// Open 打开命名文件以进行读取。
+func (o *OverlayFS) Open(name string) (FSFile, error) {
+	return o.OpenFile(name, O_RDONLY, 0)
+}
+
+// Create 在上层创建或截断命名文件，它的权限位为0666（在umask之前）。
+func (o *OverlayFS) Create(name string) (FSFile, error) {
+	return o.OpenFile(name, O_RDWR|O_CREATE|O_TRUNC, 0666)
+}
+
+// OpenFile 使用指定的标志（O_RDONLY等）打开命名文件。
+// 只读打开时，文件来自上层或下层；以其他方式打开时，文件先被复制到上层。
+func (o *OverlayFS) OpenFile(name string, flag int, perm FileMode) (FSFile, error) {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var f FSFile
+	var err error
+	if flag&(O_WRONLY|O_RDWR|O_CREATE|O_TRUNC) == 0 {
+		f, err = o.upper.OpenFile(name, flag, perm)
+		if err != nil && IsNotExist(err) {
+			if o.hidden(name) {
+				return nil, &PathError{"open", name, syscall.ENOENT}
+			}
+			f, err = o.lower.OpenFile(name, flag, perm)
+		}
+	} else {
+		_, err = o.stat("open", name)
+		switch {
+		case err == nil:
+			if flag&(O_CREATE|O_EXCL) == O_CREATE|O_EXCL {
+				return nil, &PathError{"open", name, syscall.EEXIST}
+			}
+			err = o.copyUp("open", name, false)
+		case IsNotExist(err) && flag&O_CREATE != 0:
+			err = o.ensureUpperDir("open", fsPathDir(name))
+		default:
+			err = &PathError{"open", name, underlyingError(err)}
+		}
+		if err == nil {
+			f, err = o.upper.OpenFile(name, flag, perm)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil && fi.IsDir() {
+		return &overlayDir{FSFile: f, fs: o, name: name}, nil
+	}
+	return f, nil
+}
+
+// Mkdir 在上层以权限位perm创建一个新目录。
+func (o *OverlayFS) Mkdir(name string, perm FileMode) error {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.stat("mkdir", name); err == nil {
+		return &PathError{"mkdir", name, syscall.EEXIST}
+	}
+	if err := o.ensureUpperDir("mkdir", fsPathDir(name)); err != nil {
+		return err
+	}
+	return o.upper.Mkdir(name, perm)
+}
+
+// Remove 删除命名文件或空目录。只存在于下层的项被记录为已删除。
+func (o *OverlayFS) Remove(name string) error {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	fi, err := o.stat("remove", name)
+	if err != nil {
+		return &PathError{"remove", name, underlyingError(err)}
+	}
+	if fi.IsDir() {
+		dirents, err := o.readDir(name)
+		if err != nil {
+			return &PathError{"remove", name, underlyingError(err)}
+		}
+		if len(dirents) > 0 {
+			return &PathError{"remove", name, syscall.ENOTEMPTY}
+		}
+	}
+	if o.inUpper(name) {
+		if err := o.upper.Remove(name); err != nil {
+			return err
+		}
+	}
+	if o.inLower(name) {
+		o.removed[removedKey(name)] = true
+	}
+	return nil
+}
+
+// Rename 将oldpath重命名为newpath。oldpath先被复制到上层，目录会被整个复制。
+func (o *OverlayFS) Rename(oldpath, newpath string) error {
+	oldpath, newpath = cleanFSPath(oldpath), cleanFSPath(newpath)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	linkErr := func(err error) error {
+		if err == nil {
+			return nil
+		}
+		return &LinkError{"rename", oldpath, newpath, underlyingError(err)}
+	}
+	if oldpath == newpath {
+		_, err := o.stat("rename", oldpath)
+		return linkErr(err)
+	}
+	if _, err := o.stat("rename", oldpath); err != nil {
+		return linkErr(err)
+	}
+	// 与Rename相同，不允许替换已存在的目录。
+	if fi, err := o.stat("rename", newpath); err == nil && fi.IsDir() {
+		return linkErr(syscall.EEXIST)
+	}
+	if err := o.copyUp("rename", oldpath, true); err != nil {
+		return linkErr(err)
+	}
+	if err := o.ensureUpperDir("rename", fsPathDir(newpath)); err != nil {
+		return linkErr(err)
+	}
+	if err := o.upper.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	if o.inLower(oldpath) {
+		o.removed[removedKey(oldpath)] = true
+	}
+	if o.inLower(newpath) {
+		o.removed[removedKey(newpath)] = true
+	}
+	return nil
+}
+
+// Stat 返回描述命名文件的FileInfo，来自上层或下层。
+func (o *OverlayFS) Stat(name string) (FileInfo, error) {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stat("stat", name)
+}
+
+// Lstat 与Stat类似，但不跟随符号链接。
+func (o *OverlayFS) Lstat(name string) (FileInfo, error) {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stat("lstat", name)
+}
+
+// Chmod 将命名文件复制到上层后更改它的模式。
+func (o *OverlayFS) Chmod(name string, mode FileMode) error {
+	return o.modify("chmod", name, func(name string) error {
+		return o.upper.Chmod(name, mode)
+	})
+}
+
+// Chtimes 将命名文件复制到上层后更改它的访问和修改时间。
+func (o *OverlayFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return o.modify("chtimes", name, func(name string) error {
+		return o.upper.Chtimes(name, atime, mtime)
+	})
+}
+
+// Truncate 将命名文件复制到上层后更改它的大小。
+func (o *OverlayFS) Truncate(name string, size int64) error {
+	return o.modify("truncate", name, func(name string) error {
+		return o.upper.Truncate(name, size)
+	})
+}
+
+// modify 将name复制到上层后对上层调用fn。
+func (o *OverlayFS) modify(op, name string, fn func(name string) error) error {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.stat(op, name); err != nil {
+		return &PathError{op, name, underlyingError(err)}
+	}
+	if err := o.copyUp(op, name, false); err != nil {
+		return err
+	}
+	return fn(name)
+}
+
+// ReadDir 读取命名目录，返回两层内容合并后按文件名排序的目录项。
+func (o *OverlayFS) ReadDir(name string) ([]DirEntry, error) {
+	name = cleanFSPath(name)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.readDir(name)
+}
+
+// overlayDir 是OverlayFS中打开的目录，它的读取目录方法返回两层合并后的内容。
+type overlayDir struct {
+	FSFile
+	fs   *OverlayFS
+	name string
+
+	dirents []DirEntry // 第一次读取目录时的内容快照
+	read    bool
+}
+
+func (d *overlayDir) Seek(offset int64, whence int) (int64, error) {
+	ret, err := d.FSFile.Seek(offset, whence)
+	if err == nil && offset == 0 && whence == io.SeekStart {
+		d.dirents, d.read = nil, false
+	}
+	return ret, err
+}
+
+func (d *overlayDir) ReadDir(n int) ([]DirEntry, error) {
+	if !d.read {
+		if _, err := d.FSFile.Stat(); err != nil {
+			return nil, err
+		}
+		dirents, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, &PathError{"readdirent", d.FSFile.Name(), underlyingError(err)}
+		}
+		d.dirents, d.read = dirents, true
+	}
+	if n <= 0 {
+		n = len(d.dirents)
+	} else if len(d.dirents) == 0 {
+		return nil, io.EOF
+	} else if n > len(d.dirents) {
+		n = len(d.dirents)
+	}
+	dirents := d.dirents[:n]
+	d.dirents = d.dirents[n:]
+	return dirents, nil
+}
+
+func (d *overlayDir) Readdir(n int) ([]FileInfo, error) {
+	dirents, err := d.ReadDir(n)
+	infos := make([]FileInfo, 0, len(dirents))
+	for _, de := range dirents {
+		fi, ierr := de.Info()
+		if ierr != nil {
+			// 读取目录后被删除的项。
+			continue
+		}
+		infos = append(infos, fi)
+	}
+	return infos, err
+}
+
+func (d *overlayDir) Readdirnames(n int) ([]string, error) {
+	dirents, err := d.ReadDir(n)
+	names := make([]string, len(dirents))
+	for i, de := range dirents {
+		names[i] = de.Name()
+	}
+	return names, err
+}
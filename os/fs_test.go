@@ -0,0 +1,575 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func writeFS(t *testing.T, fsys FS, name, data string) {
+	t.Helper()
+	f, err := fsys.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFS(t *testing.T, fsys FS, name string) string {
+	t.Helper()
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func dirNames(t *testing.T, fsys FS, name string) []string {
+	t.Helper()
+	dirents, err := fsys.ReadDir(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, d := range dirents {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+func TestMemFSModes(t *testing.T) {
+	fsys := NewMemFS()
+	if err := fsys.Mkdir("dir", 0750|ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fsys.OpenFile("dir/file", O_WRONLY|O_CREATE, 0640|ModeSymlink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, tt := range []struct {
+		name string
+		mode FileMode
+	}{
+		{"/", ModeDir | 0755},
+		{"dir", ModeDir | ModeSetgid | 0750},
+		{"dir/file", 0640},
+	} {
+		fi, err := fsys.Stat(tt.name)
+		if err != nil || fi.Mode() != tt.mode {
+			t.Errorf("Stat(%q).Mode() = %v, %v, want %v", tt.name, fi.Mode(), err, tt.mode)
+		}
+	}
+
+	if err := fsys.Chmod("dir/file", 0400|ModeDir); err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := fsys.Stat("dir/file"); fi.Mode() != 0400 {
+		t.Errorf("Chmod(0400|ModeDir) gave mode %v, want %v", fi.Mode(), FileMode(0400))
+	}
+	if _, err := fsys.OpenFile("dir/file", O_WRONLY, 0); !IsPermission(err) {
+		t.Errorf("OpenFile(read-only file, O_WRONLY) = %v, want IsPermission", err)
+	}
+	if _, err := fsys.OpenFile("dir", O_RDWR, 0); !errors.Is(err, syscall.EISDIR) {
+		t.Errorf("OpenFile(dir, O_RDWR) = %v, want EISDIR", err)
+	}
+}
+
+func TestMemFSModTime(t *testing.T) {
+	fsys := NewMemFS()
+	if err := fsys.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Unix(1000000000, 0)
+	if err := fsys.Chtimes("dir", old, old); err != nil {
+		t.Fatal(err)
+	}
+	modTime := func(name string) time.Time {
+		fi, err := fsys.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.ModTime()
+	}
+
+	writeFS(t, fsys, "dir/file", "data")
+	if !modTime("dir").After(old) {
+		t.Error("creating a file did not update the directory's ModTime")
+	}
+	fsys.Chtimes("dir", old, old)
+	fsys.Chtimes("dir/file", old, old)
+	if !modTime("dir/file").Equal(old) {
+		t.Errorf("Chtimes() gave ModTime %v, want %v", modTime("dir/file"), old)
+	}
+
+	f, err := fsys.OpenFile("dir/file", O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("x"))
+	f.Close()
+	if !modTime("dir/file").After(old) {
+		t.Error("Write() did not update ModTime")
+	}
+	if !modTime("dir").Equal(old) {
+		t.Error("writing a file updated the directory's ModTime")
+	}
+
+	fsys.Chtimes("dir/file", old, old)
+	if err := fsys.Truncate("dir/file", 1); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime("dir/file").After(old) {
+		t.Error("Truncate() did not update ModTime")
+	}
+
+	if err := fsys.Remove("dir/file"); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime("dir").After(old) {
+		t.Error("Remove() did not update the directory's ModTime")
+	}
+}
+
+func TestMemFSErrors(t *testing.T) {
+	fsys := NewMemFS()
+	if err := fsys.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFS(t, fsys, "dir/file", "data")
+
+	if _, err := fsys.OpenFile("dir/file", O_RDWR|O_CREATE|O_EXCL, 0644); !IsExist(err) {
+		t.Errorf("OpenFile(O_EXCL) of existing file = %v, want IsExist", err)
+	}
+	if _, err := fsys.OpenFile("dir/new", O_RDWR|O_CREATE|O_EXCL, 0644); err != nil {
+		t.Errorf("OpenFile(O_EXCL) of new file = %v", err)
+	}
+	if err := fsys.Mkdir("dir", 0755); !IsExist(err) {
+		t.Errorf("Mkdir(existing) = %v, want IsExist", err)
+	}
+	if err := fsys.Mkdir("/", 0755); !IsExist(err) {
+		t.Errorf("Mkdir(/) = %v, want IsExist", err)
+	}
+	if err := fsys.Remove("dir"); !errors.Is(err, syscall.ENOTEMPTY) {
+		t.Errorf("Remove(non-empty dir) = %v, want ENOTEMPTY", err)
+	}
+	if err := fsys.Mkdir("dir/file/sub", 0755); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("Mkdir(under file) = %v, want ENOTDIR", err)
+	}
+
+	for _, name := range []string{"missing", "dir/missing", "missing/file"} {
+		if _, err := fsys.Stat(name); !IsNotExist(err) {
+			t.Errorf("Stat(%q) = %v, want IsNotExist", name, err)
+		}
+		if _, err := fsys.Open(name); !IsNotExist(err) {
+			t.Errorf("Open(%q) = %v, want IsNotExist", name, err)
+		}
+		if err := fsys.Remove(name); !IsNotExist(err) {
+			t.Errorf("Remove(%q) = %v, want IsNotExist", name, err)
+		}
+		if err := fsys.Chmod(name, 0644); !IsNotExist(err) {
+			t.Errorf("Chmod(%q) = %v, want IsNotExist", name, err)
+		}
+	}
+	_, err := fsys.Stat("missing")
+	if pe, ok := err.(*PathError); !ok || pe.Op != "stat" || pe.Path != "missing" {
+		t.Errorf("Stat(missing) = %#v, want *PathError", err)
+	}
+}
+
+func TestMemFSRename(t *testing.T) {
+	fsys := NewMemFS()
+	for _, d := range []string{"a", "a/b", "c"} {
+		if err := fsys.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFS(t, fsys, "a/b/file", "data")
+	writeFS(t, fsys, "other", "other")
+
+	for _, tt := range []struct {
+		old, new string
+		err      error
+	}{
+		{"a", "a/b/c", syscall.EINVAL},
+		{"a", "a/x", syscall.EINVAL},
+		{"a", "c", syscall.EEXIST},
+		{"a", "other", syscall.ENOTDIR},
+		{"missing", "x", syscall.ENOENT},
+		{"/", "x", syscall.EBUSY},
+	} {
+		err := fsys.Rename(tt.old, tt.new)
+		if _, ok := err.(*LinkError); !ok || !errors.Is(err, tt.err) {
+			t.Errorf("Rename(%q, %q) = %v, want *LinkError with %v", tt.old, tt.new, err, tt.err)
+		}
+	}
+
+	if err := fsys.Rename("a", "/c/a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFS(t, fsys, "c/a/b/file"); got != "data" {
+		t.Errorf("file after Rename = %q, want %q", got, "data")
+	}
+	if fi, err := fsys.Stat("c/a"); err != nil || fi.Name() != "a" {
+		t.Errorf("Stat(c/a) = %v, %v", fi, err)
+	}
+	if err := fsys.Rename("other", "c/a/b/file"); err != nil {
+		t.Errorf("Rename() over a file = %v", err)
+	}
+	if got := readFS(t, fsys, "c/a/b/file"); got != "other" {
+		t.Errorf("replaced file = %q, want %q", got, "other")
+	}
+}
+
+func TestMemFSFile(t *testing.T) {
+	fsys := NewMemFS()
+	f, err := fsys.Create("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("hello, world")
+	if _, err := f.Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 10)
+	if n, err := f.Read(b); string(b[:n]) != "world" || err != nil {
+		t.Errorf("Read() = %q, %v", b[:n], err)
+	}
+	if _, err := f.WriteAt([]byte("W"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(8); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := readFS(t, fsys, "file"); got != "hello, W" {
+		t.Errorf("file = %q, want %q", got, "hello, W")
+	}
+	if _, err := f.Read(b); !errors.Is(err, ErrClosed) {
+		t.Errorf("Read() after Close = %v, want ErrClosed", err)
+	}
+
+	r, _ := fsys.Open("file")
+	defer r.Close()
+	if _, err := r.Write([]byte("x")); err == nil {
+		t.Error("Write() to read-only file = nil, want error")
+	}
+}
+
+// newOverlay returns an OverlayFS of an empty MemFS over a MemFS lower
+// layer containing:
+//
+//	a/
+//	a/file     "lower a"
+//	a/sub/
+//	a/sub/deep "deep"
+//	b          "lower b"
+func newOverlay(t *testing.T) (o *OverlayFS, upper, lower *MemFS) {
+	t.Helper()
+	lower = NewMemFS()
+	for _, d := range []string{"a", "a/sub"} {
+		if err := lower.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFS(t, lower, "a/file", "lower a")
+	writeFS(t, lower, "a/sub/deep", "deep")
+	writeFS(t, lower, "b", "lower b")
+	upper = NewMemFS()
+	return NewOverlayFS(upper, lower), upper, lower
+}
+
+func TestOverlayCopyUp(t *testing.T) {
+	o, upper, lower := newOverlay(t)
+
+	old := time.Unix(1000000000, 0)
+	lower.Chmod("a/file", 0600)
+	lower.Chtimes("a/file", old, old)
+
+	f, err := o.OpenFile("a/file", O_WRONLY|O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(0, io.SeekEnd)
+	f.WriteString(" upper")
+	f.Close()
+
+	if got := readFS(t, o, "a/file"); got != "lower a upper" {
+		t.Errorf("overlay a/file = %q, want %q", got, "lower a upper")
+	}
+	if got := readFS(t, lower, "a/file"); got != "lower a" {
+		t.Errorf("lower a/file = %q, modified by the overlay", got)
+	}
+	if fi, err := upper.Stat("a/file"); err != nil || fi.Mode() != 0600 {
+		t.Errorf("upper a/file = %v, %v, want copied up with mode 0600", fi, err)
+	}
+	if fi, err := upper.Stat("a"); err != nil || !fi.IsDir() {
+		t.Errorf("upper a = %v, %v, want parent directory copied up", fi, err)
+	}
+	if _, err := upper.Stat("a/sub"); !IsNotExist(err) {
+		t.Errorf("upper a/sub = %v, want siblings left in the lower layer", err)
+	}
+
+	for name, fn := range map[string]func() error{
+		"Chmod":    func() error { return o.Chmod("b", 0444) },
+		"Truncate": func() error { return o.Truncate("a/sub/deep", 2) },
+		"Chtimes":  func() error { return o.Chtimes("a/sub", old, old) },
+	} {
+		if err := fn(); err != nil {
+			t.Errorf("%s() = %v", name, err)
+		}
+	}
+	if fi, _ := lower.Stat("b"); fi.Mode() != 0666 {
+		t.Errorf("Chmod() changed the lower layer: mode %v", fi.Mode())
+	}
+	if got := readFS(t, o, "a/sub/deep"); got != "de" {
+		t.Errorf("overlay a/sub/deep = %q after Truncate, want %q", got, "de")
+	}
+	if got := readFS(t, lower, "a/sub/deep"); got != "deep" {
+		t.Errorf("Truncate() changed the lower layer: %q", got)
+	}
+	if fi, _ := o.Stat("a/sub"); !fi.ModTime().Equal(old) {
+		t.Errorf("overlay a/sub ModTime = %v, want %v", fi.ModTime(), old)
+	}
+}
+
+func TestOverlayRemove(t *testing.T) {
+	o, _, lower := newOverlay(t)
+
+	if err := o.Remove("a"); !errors.Is(err, syscall.ENOTEMPTY) {
+		t.Errorf("Remove(a) = %v, want ENOTEMPTY", err)
+	}
+	for _, name := range []string{"a/sub/deep", "a/sub", "b"} {
+		if err := o.Remove(name); err != nil {
+			t.Fatalf("Remove(%q) = %v", name, err)
+		}
+		if _, err := o.Stat(name); !IsNotExist(err) {
+			t.Errorf("Stat(%q) after Remove = %v, want IsNotExist", name, err)
+		}
+		if _, err := o.Open(name); !IsNotExist(err) {
+			t.Errorf("Open(%q) after Remove = %v, want IsNotExist", name, err)
+		}
+		if _, err := lower.Stat(name); err != nil {
+			t.Errorf("Remove(%q) removed it from the lower layer: %v", name, err)
+		}
+	}
+	if got := dirNames(t, o, "a"); !reflect.DeepEqual(got, []string{"file"}) {
+		t.Errorf("ReadDir(a) = %q, want [file]", got)
+	}
+	if err := o.Remove("b"); !IsNotExist(err) {
+		t.Errorf("second Remove(b) = %v, want IsNotExist", err)
+	}
+
+	// A new file at a removed path does not resurrect the lower one.
+	writeFS(t, o, "b", "new b")
+	if got := readFS(t, o, "b"); got != "new b" {
+		t.Errorf("recreated b = %q, want %q", got, "new b")
+	}
+	if err := o.Mkdir("a/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := dirNames(t, o, "a/sub"); len(got) != 0 {
+		t.Errorf("recreated a/sub contains %q, want empty", got)
+	}
+}
+
+func TestOverlayRemoveClean(t *testing.T) {
+	o, _, _ := newOverlay(t)
+	if err := o.Remove("./a/../b/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Stat("b"); !IsNotExist(err) {
+		t.Errorf("Stat(b) after Remove(./a/../b/) = %v, want IsNotExist", err)
+	}
+	if got := dirNames(t, o, "."); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("ReadDir(.) after Remove(./a/../b/) = %q, want [a]", got)
+	}
+}
+
+func TestOverlayRemoveRelative(t *testing.T) {
+	// With an OSFS lower layer, relative names are relative to the
+	// current directory and must not hide the same name at the root.
+	dir := tempDir(t)
+	defer removeAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+	sub := filepath.Join(dir, "sub")
+	if err := Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{filepath.Join(dir, "x"), filepath.Join(sub, "x"), filepath.Join(sub, "tmp")} {
+		if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, err := Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Chdir(old)
+	if err := Chdir(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewOverlayFS(NewMemFS(), OSFS{})
+	if err := o.Remove("x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Stat("x"); !IsNotExist(err) {
+		t.Errorf("Stat(x) after Remove(x) = %v, want IsNotExist", err)
+	}
+	if _, err := o.Stat("../x"); err != nil {
+		t.Errorf("Remove(x) hid ../x: %v", err)
+	}
+	if err := o.Remove("tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Stat("/tmp"); err != nil {
+		t.Errorf("Remove(tmp) in %s hid /tmp: %v", sub, err)
+	}
+	if names := dirNames(t, o, dir); !reflect.DeepEqual(names, []string{"sub", "x"}) {
+		t.Errorf("ReadDir(%q) after Remove(x) = %q, want [sub x]", dir, names)
+	}
+
+	if err := o.Remove("../x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Stat("../x"); !IsNotExist(err) {
+		t.Errorf("Stat(../x) after Remove(../x) = %v, want IsNotExist", err)
+	}
+	if _, err := Stat(filepath.Join(dir, "x")); err != nil {
+		t.Errorf("Remove(../x) removed the file on disk: %v", err)
+	}
+}
+
+func TestOverlayMerge(t *testing.T) {
+	o, upper, _ := newOverlay(t)
+	if err := upper.Mkdir("a", 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFS(t, upper, "a/file", "upper a")
+	writeFS(t, upper, "a/upper-only", "u")
+	writeFS(t, upper, "c", "c")
+
+	if got, want := dirNames(t, o, "a"), []string{"file", "sub", "upper-only"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir(a) = %q, want %q", got, want)
+	}
+	if got, want := dirNames(t, o, "/"), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir(/) = %q, want %q", got, want)
+	}
+	if got := readFS(t, o, "a/file"); got != "upper a" {
+		t.Errorf("a/file = %q, want the upper layer's %q", got, "upper a")
+	}
+	if fi, _ := o.Stat("a"); fi.Mode() != ModeDir|0700 {
+		t.Errorf("Stat(a).Mode() = %v, want the upper layer's", fi.Mode())
+	}
+
+	d, err := o.Open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	var names []string
+	for {
+		dirents, err := d.ReadDir(2)
+		for _, de := range dirents {
+			names = append(names, de.Name())
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"file", "sub", "upper-only"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("File.ReadDir(2) returned %q, want %q", names, want)
+	}
+}
+
+func TestOverlayRenameAndErrors(t *testing.T) {
+	o, _, lower := newOverlay(t)
+
+	if err := o.Rename("a", "moved"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFS(t, o, "moved/sub/deep"); got != "deep" {
+		t.Errorf("moved/sub/deep = %q, want %q", got, "deep")
+	}
+	if _, err := o.Stat("a/file"); !IsNotExist(err) {
+		t.Errorf("Stat(a/file) after Rename = %v, want IsNotExist", err)
+	}
+	if _, err := lower.Stat("a/file"); err != nil {
+		t.Errorf("Rename() changed the lower layer: %v", err)
+	}
+
+	if err := o.Mkdir("moved", 0755); !IsExist(err) {
+		t.Errorf("Mkdir(existing) = %v, want IsExist", err)
+	}
+	if _, err := o.OpenFile("b", O_RDWR|O_CREATE|O_EXCL, 0644); !IsExist(err) {
+		t.Errorf("OpenFile(O_EXCL) of lower file = %v, want IsExist", err)
+	}
+	if err := o.Rename("b", "moved"); !IsExist(err) {
+		t.Errorf("Rename() onto a directory = %v, want IsExist", err)
+	}
+	for _, name := range []string{"missing", "a", "moved/missing"} {
+		if _, err := o.Stat(name); !IsNotExist(err) {
+			t.Errorf("Stat(%q) = %v, want IsNotExist", name, err)
+		}
+		if err := o.Chmod(name, 0644); !IsNotExist(err) {
+			t.Errorf("Chmod(%q) = %v, want IsNotExist", name, err)
+		}
+		if err := o.Remove(name); !IsNotExist(err) {
+			t.Errorf("Remove(%q) = %v, want IsNotExist", name, err)
+		}
+	}
+	if _, err := o.Create("missing/file"); !IsNotExist(err) {
+		t.Errorf("Create(missing/file) = %v, want IsNotExist", err)
+	}
+}
+
+func TestOverlayOSFS(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, []byte("disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o := NewOverlayFS(NewMemFS(), OSFS{})
+	writeFS(t, o, name, "memory")
+	if got := readFS(t, o, name); got != "memory" {
+		t.Errorf("overlay file = %q, want %q", got, "memory")
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "disk" {
+		t.Errorf("file on disk = %q, modified by the overlay", b)
+	}
+	if err := o.Remove(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Stat(name); !IsNotExist(err) {
+		t.Errorf("Stat() after Remove = %v, want IsNotExist", err)
+	}
+	if _, err := Stat(name); err != nil {
+		t.Errorf("Remove() through the overlay removed the file on disk: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+)
+
+var getwdCache struct { // 注：缓存上一次计算出的工作目录
+	sync.Mutex
+	dir string
+}
+
+// Getwd 返回与当前目录对应的根路径名。
+// 如果可以通过多个路径（由于符号链接）到达当前目录，Getwd可能返回其中的任何一个。
+func Getwd() (dir string, err error) { // 注：#
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		return syscall.Getwd()
+	}
+
+	// 笨拙但广泛使用的技巧：
+	// 如果设置了$PWD并且它是当前目录的名称，则使用它。
+	dot, err := statNolog(".")
+	if err != nil {
+		return "", err
+	}
+	dir = Getenv("PWD")
+	if len(dir) > 0 && dir[0] == '/' {
+		d, err := statNolog(dir)
+		if err == nil && SameFile(dot, d) {
+			return dir, nil
+		}
+	}
+
+	// 如果操作系统提供Getwd调用，则使用它。
+	// 否则，我们尝试找到回到"."的路径。
+	if syscall.ImplementsGetwd {
+		var (
+			s string
+			e error
+		)
+		for {
+			s, e = syscall.Getwd()
+			if e != syscall.EINTR {
+				break
+			}
+		}
+		return s, NewSyscallError("getwd", e)
+	}
+
+	// 对缓存的目录而不是$PWD应用相同的技巧。
+	getwdCache.Lock()
+	dir = getwdCache.dir
+	getwdCache.Unlock()
+	if len(dir) > 0 {
+		d, err := statNolog(dir)
+		if err == nil && SameFile(dot, d) {
+			return dir, nil
+		}
+	}
+
+	// 根目录是一个特例，因为它没有父目录并且以斜杠结尾。
+	root, err := statNolog("/")
+	if err != nil {
+		// 无法stat根目录，没有希望了。
+		return "", err
+	}
+	if SameFile(root, dot) {
+		return "/", nil
+	}
+
+	// 一般算法：在父目录中找到名称，然后找到父目录的名称。
+	// 每次迭代都将/name添加到dir的开头。
+	dir = ""
+	for parent := ".."; ; parent = "../" + parent {
+		if len(parent) >= 1024 { // 健全性检查
+			return "", syscall.ENAMETOOLONG
+		}
+		fd, err := openFileNolog(parent, O_RDONLY, 0)
+		if err != nil {
+			return "", err
+		}
+
+		for {
+			names, err := fd.Readdirnames(100)
+			if err != nil {
+				fd.Close()
+				return "", err
+			}
+			for _, name := range names {
+				d, _ := lstatNolog(parent + "/" + name)
+				if SameFile(d, dot) {
+					dir = "/" + name + dir
+					goto Found
+				}
+			}
+		}
+
+	Found:
+		pd, err := fd.Stat()
+		fd.Close()
+		if err != nil {
+			return "", err
+		}
+		if SameFile(pd, root) {
+			break
+		}
+		// 为下一轮做准备。
+		dot = pd
+	}
+
+	// 保存答案作为提示，以避免下次走代价高昂的路径。
+	getwdCache.Lock()
+	getwdCache.dir = dir
+	getwdCache.Unlock()
+
+	return dir, nil
+}
@@ -0,0 +1,111 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import "time"
+
+// 文件锁是建议性的：它们只约束同样使用文件锁的程序，不会阻止对文件的读写。
+//
+// 在Linux上，锁是打开文件描述（open file description）锁（F_OFD_SETLK），
+// 它属于打开的*File而不是进程：同一进程中两个独立打开的*File会相互排斥，
+// 并且只有在持有锁的*File关闭（或显式Unlock）时锁才会被释放。
+// 内核不支持OFD锁时，整个文件的锁退回到flock(2)，字节范围锁则报告错误。
+
+// Lock 对整个文件加独占（写）锁，必要时阻塞直到获得锁。
+// 如果有错误，它将是*PathError类型。
+func (f *File) Lock() error {
+	return f.LockRange(0, 0, true, time.Time{})
+}
+
+// RLock 对整个文件加共享（读）锁，必要时阻塞直到获得锁。
+// 如果有错误，它将是*PathError类型。
+func (f *File) RLock() error {
+	return f.LockRange(0, 0, false, time.Time{})
+}
+
+// TryLock 尝试对整个文件加独占锁而不阻塞，并报告是否成功。
+// 锁被其他人持有时返回false和nil错误。
+func (f *File) TryLock() (bool, error) {
+	return f.TryLockRange(0, 0, true)
+}
+
+// TryRLock 尝试对整个文件加共享锁而不阻塞，并报告是否成功。
+// 锁被其他人以独占方式持有时返回false和nil错误。
+func (f *File) TryRLock() (bool, error) {
+	return f.TryLockRange(0, 0, false)
+}
+
+// Unlock 释放f在整个文件上持有的锁。
+func (f *File) Unlock() error {
+	return f.UnlockRange(0, 0)
+}
+
+// LockRange 对文件中从offset开始、长度为length字节的范围加锁。
+// length为0表示锁定到文件末尾（包括之后追加的数据）。
+// exclusive为true时加独占（写）锁，否则加共享（读）锁。
+//
+// 如果deadline为零值，LockRange会一直阻塞直到获得锁；
+// 否则在deadline之后放弃，并返回一个满足IsTimeout的*PathError。
+func (f *File) LockRange(offset, length int64, exclusive bool, deadline time.Time) error { // 注：#
+	if err := f.checkValid("lock"); err != nil {
+		return err
+	}
+	if offset < 0 || length < 0 {
+		return &PathError{"lock", f.name, ErrInvalid}
+	}
+	if deadline.IsZero() {
+		return f.wrapErr("lock", f.lock(offset, length, exclusive, true))
+	}
+
+	// 锁系统调用无法被截止时间打断，因此在截止时间之前反复尝试非阻塞加锁。
+	const maxDelay = 100 * time.Millisecond
+	delay := time.Millisecond
+	for {
+		err := f.lock(offset, length, exclusive, false)
+		if err != errLockHeld {
+			return f.wrapErr("lock", err)
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return f.wrapErr("lock", errLockTimeout())
+		}
+		if delay > left {
+			delay = left
+		}
+		time.Sleep(delay)
+		if delay *= 2; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// TryLockRange 与LockRange类似，但不会阻塞：锁被其他人持有时返回false和nil错误。
+func (f *File) TryLockRange(offset, length int64, exclusive bool) (bool, error) { // 注：#
+	if err := f.checkValid("lock"); err != nil {
+		return false, err
+	}
+	if offset < 0 || length < 0 {
+		return false, &PathError{"lock", f.name, ErrInvalid}
+	}
+	err := f.lock(offset, length, exclusive, false)
+	if err == errLockHeld {
+		return false, nil
+	}
+	if err != nil {
+		return false, f.wrapErr("lock", err)
+	}
+	return true, nil
+}
+
+// UnlockRange 释放f在从offset开始、长度为length字节的范围上持有的锁。
+func (f *File) UnlockRange(offset, length int64) error { // 注：#
+	if err := f.checkValid("unlock"); err != nil {
+		return err
+	}
+	if offset < 0 || length < 0 {
+		return &PathError{"unlock", f.name, ErrInvalid}
+	}
+	return f.wrapErr("unlock", f.unlock(offset, length))
+}
@@ -0,0 +1,131 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"internal/poll"
+	"sync"
+	"syscall"
+)
+
+// 打开文件描述锁的fcntl(2)命令（Linux 3.15+）。
+const (
+	_F_OFD_GETLK  = 36
+	_F_OFD_SETLK  = 37
+	_F_OFD_SETLKW = 38
+)
+
+// errLockHeld 是非阻塞加锁时锁被其他人持有的内部标记，不会返回给调用者。
+var errLockHeld = errors.New("os: lock held")
+
+// errLockTimeout 返回加锁超过截止时间时的错误，它满足IsTimeout。
+func errLockTimeout() error { return poll.ErrTimeout }
+
+var (
+	ofdOnce      sync.Once
+	ofdSupported bool
+)
+
+// ofdWorks 报告内核是否支持OFD锁，fd必须是一个有效的文件描述符。
+// 探测只在第一次加锁时进行一次：对整个文件的F_OFD_GETLK不会因为范围无效而失败，
+// 因此EINVAL只能说明内核不认识该命令。之后加锁返回的EINVAL（例如范围超出off_t）直接报告给调用者。
+func ofdWorks(fd int) bool { // 注：#
+	ofdOnce.Do(func() {
+		lk := syscall.Flock_t{Type: syscall.F_RDLCK} // 注：Start与Len为0表示整个文件
+		err := ignoringEINTR(func() error {
+			return syscall.FcntlFlock(uintptr(fd), _F_OFD_GETLK, &lk)
+		})
+		ofdSupported = err != syscall.EINVAL
+	})
+	return ofdSupported
+}
+
+// lock 对f的指定范围加锁。
+// wait为false且锁被其他人持有时返回errLockHeld。
+func (f *File) lock(offset, length int64, exclusive, wait bool) error { // 注：#
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) {
+		if ofdWorks(int(fd)) {
+			typ := int16(syscall.F_RDLCK)
+			if exclusive {
+				typ = syscall.F_WRLCK
+			}
+			err = ofdLock(int(fd), typ, offset, length, wait)
+			return
+		}
+		err = flockFallback(int(fd), offset, length, exclusive, wait)
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// unlock 释放f在指定范围上持有的锁。
+func (f *File) unlock(offset, length int64) error { // 注：#
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) {
+		if ofdWorks(int(fd)) {
+			err = ofdLock(int(fd), syscall.F_UNLCK, offset, length, false)
+			return
+		}
+		if offset != 0 || length != 0 {
+			err = syscall.ENOTSUP
+			return
+		}
+		err = ignoringEINTR(func() error {
+			return syscall.Flock(int(fd), syscall.LOCK_UN)
+		})
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// ofdLock 使用F_OFD_SETLK或F_OFD_SETLKW设置类型为typ的锁。
+func ofdLock(fd int, typ int16, offset, length int64, wait bool) error { // 注：#
+	lk := syscall.Flock_t{
+		Type:   typ,
+		Whence: 0, // io.SeekStart
+		Start:  offset,
+		Len:    length,
+		Pid:    0, // OFD锁要求Pid为0
+	}
+	cmd := _F_OFD_SETLK
+	if wait {
+		cmd = _F_OFD_SETLKW
+	}
+	err := ignoringEINTR(func() error {
+		return syscall.FcntlFlock(uintptr(fd), cmd, &lk)
+	})
+	if err == syscall.EAGAIN || err == syscall.EACCES { // 注：锁被其他人持有
+		return errLockHeld
+	}
+	return err
+}
+
+// flockFallback 在内核不支持OFD锁时使用flock(2)锁定整个文件。
+// flock(2)只能锁定整个文件，因此字节范围锁报告ENOTSUP。
+func flockFallback(fd int, offset, length int64, exclusive, wait bool) error { // 注：#
+	if offset != 0 || length != 0 {
+		return syscall.ENOTSUP
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	err := ignoringEINTR(func() error {
+		return syscall.Flock(fd, how)
+	})
+	if err == syscall.EWOULDBLOCK {
+		return errLockHeld
+	}
+	return err
+}
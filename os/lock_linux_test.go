@@ -0,0 +1,159 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	. "os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// openTwice opens the same file twice, giving two independent
+// open file descriptions that contend for locks.
+func openTwice(t *testing.T) (f1, f2 *File, cleanup func()) {
+	t.Helper()
+	dir := tempDir(t)
+	name := filepath.Join(dir, "lock")
+	if err := ioutil.WriteFile(name, make([]byte, 100), 0644); err != nil {
+		removeAll(dir)
+		t.Fatal(err)
+	}
+	f1, err := OpenFile(name, O_RDWR, 0)
+	if err != nil {
+		removeAll(dir)
+		t.Fatal(err)
+	}
+	f2, err = OpenFile(name, O_RDWR, 0)
+	if err != nil {
+		f1.Close()
+		removeAll(dir)
+		t.Fatal(err)
+	}
+	return f1, f2, func() {
+		f1.Close()
+		f2.Close()
+		removeAll(dir)
+	}
+}
+
+func TestLockExclusive(t *testing.T) {
+	f1, f2, cleanup := openTwice(t)
+	defer cleanup()
+
+	if err := f1.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := f2.TryLock(); ok || err != nil {
+		t.Errorf("TryLock() while locked = %v, %v, want false, nil", ok, err)
+	}
+	if ok, err := f2.TryRLock(); ok || err != nil {
+		t.Errorf("TryRLock() while locked = %v, %v, want false, nil", ok, err)
+	}
+	if err := f1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := f2.TryLock(); !ok || err != nil {
+		t.Errorf("TryLock() after Unlock = %v, %v, want true, nil", ok, err)
+	}
+}
+
+func TestLockShared(t *testing.T) {
+	f1, f2, cleanup := openTwice(t)
+	defer cleanup()
+
+	if err := f1.RLock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := f2.TryRLock(); !ok || err != nil {
+		t.Errorf("TryRLock() while read-locked = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := f2.TryLock(); ok || err != nil {
+		t.Errorf("TryLock() while read-locked = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestLockRange(t *testing.T) {
+	f1, f2, cleanup := openTwice(t)
+	defer cleanup()
+
+	if err := f1.LockRange(0, 10, true, time.Time{}); err != nil {
+		t.Skipf("byte-range locks not supported: %v", err)
+	}
+	if ok, err := f2.TryLockRange(10, 10, true); !ok || err != nil {
+		t.Errorf("TryLockRange(10, 10) = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := f2.TryLockRange(5, 10, true); ok || err != nil {
+		t.Errorf("TryLockRange(5, 10) = %v, %v, want false, nil", ok, err)
+	}
+	if err := f1.UnlockRange(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := f2.TryLockRange(5, 10, true); !ok || err != nil {
+		t.Errorf("TryLockRange(5, 10) after UnlockRange = %v, %v, want true, nil", ok, err)
+	}
+}
+
+func TestLockRangeInvalid(t *testing.T) {
+	f1, f2, cleanup := openTwice(t)
+	defer cleanup()
+
+	if err := f1.LockRange(-1, 10, true, time.Time{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("LockRange(-1, 10) = %v, want ErrInvalid", err)
+	}
+	if err := f1.LockRange(0, 10, true, time.Time{}); err != nil {
+		t.Skipf("byte-range locks not supported: %v", err)
+	}
+
+	// A range that overflows off_t makes the kernel return EINVAL
+	// (EOVERFLOW on newer kernels). That must be reported to the caller,
+	// not taken to mean that the kernel lacks OFD locks.
+	err := f1.LockRange(math.MaxInt64, 10, true, time.Time{})
+	if _, ok := err.(*PathError); !ok || !(errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.EOVERFLOW)) {
+		t.Errorf("LockRange(MaxInt64, 10) = %v, want *PathError with EINVAL or EOVERFLOW", err)
+	}
+	if ok, err := f2.TryLockRange(5, 10, true); ok || err != nil {
+		t.Errorf("TryLockRange(5, 10) after EINVAL = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestLockDeadline(t *testing.T) {
+	f1, f2, cleanup := openTwice(t)
+	defer cleanup()
+
+	if err := f1.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	err := f2.LockRange(0, 0, true, start.Add(50*time.Millisecond))
+	if !IsTimeout(err) {
+		t.Fatalf("LockRange() with deadline = %v, want timeout", err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("LockRange() returned after %v, before the deadline", d)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		f1.Unlock()
+	}()
+	if err := f2.LockRange(0, 0, true, time.Now().Add(5*time.Second)); err != nil {
+		t.Errorf("LockRange() after Unlock = %v", err)
+	}
+}
+
+func TestLockClosed(t *testing.T) {
+	f1, _, cleanup := openTwice(t)
+	defer cleanup()
+
+	f1.Close()
+	if err := f1.Lock(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Lock() on closed file = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,190 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// MmapMode 描述映射的保护方式与共享方式。
+type MmapMode int
+
+const (
+	// MmapReadOnly 以只读方式共享映射文件，写入映射会引发SIGSEGV。
+	MmapReadOnly MmapMode = iota
+	// MmapShared 以可读写方式共享映射文件，对映射的写入会写回文件并对其他映射可见。
+	// 文件必须以O_RDWR打开。
+	MmapShared
+	// MmapPrivate 以写时复制方式映射文件，对映射的写入不会写回文件。
+	MmapPrivate
+)
+
+// Mmap 是文件一部分的内存映射，使用File.Mmap创建。
+//
+// Bytes返回的切片直接引用映射的内存，Close之后不能再使用它，否则会引发SIGSEGV。
+// Mmap的其他方法在Close之后返回ErrClosed，可以安全地与Close并发调用。
+type Mmap struct {
+	mu   sync.RWMutex
+	name string   // 被映射文件的名称，用于错误
+	mode MmapMode // 映射方式
+	mem  []byte   // 整个映射，起点按页对齐；Close之后为nil
+	data []byte   // mem中调用者请求的部分
+}
+
+// Mmap 将f中从offset开始的length个字节映射到内存中。
+// offset不需要按页对齐，Mmap会在内部按Getpagesize对齐。
+// length为0表示映射从offset到文件末尾的所有数据。
+//
+// 访问映射中超出文件末尾的页会引发SIGBUS，因此MmapReadOnly映射不能超出文件末尾。
+// 可写的映射允许超出文件末尾，以便先映射再用Truncate扩展文件；
+// 调用者必须保证访问的部分在访问时位于文件之内。
+// 如果有错误，它将是*PathError类型。
+func (f *File) Mmap(offset int64, length int, mode MmapMode) (*Mmap, error) { // 注：#
+	if err := f.checkValid("mmap"); err != nil {
+		return nil, err
+	}
+	if offset < 0 || length < 0 {
+		return nil, &PathError{"mmap", f.name, ErrInvalid}
+	}
+	if length == 0 || mode == MmapReadOnly {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		size := fi.Size()
+		switch {
+		case length != 0:
+			if offset > size || int64(length) > size-offset {
+				return nil, &PathError{"mmap", f.name, errors.New("mapping extends past end of file")}
+			}
+		case size <= offset:
+			return nil, &PathError{"mmap", f.name, errors.New("nothing to map after offset")}
+		default:
+			length = int(size - offset)
+			if int64(length) != size-offset {
+				return nil, &PathError{"mmap", f.name, errors.New("file too large to map")}
+			}
+		}
+	}
+
+	prot, flags := syscall.PROT_READ, syscall.MAP_SHARED
+	switch mode {
+	case MmapReadOnly:
+	case MmapShared:
+		prot |= syscall.PROT_WRITE
+	case MmapPrivate:
+		prot |= syscall.PROT_WRITE
+		flags = syscall.MAP_PRIVATE
+	default:
+		return nil, &PathError{"mmap", f.name, ErrInvalid}
+	}
+
+	// mmap(2)要求偏移量按页对齐：从所在页的起点开始映射，再截取调用者请求的部分
+	pagesize := int64(Getpagesize())
+	delta := int(offset % pagesize)
+	var mem []byte
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) {
+		mem, err = syscall.Mmap(int(fd), offset-int64(delta), delta+length, prot, flags)
+	})
+	if cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, f.wrapErr("mmap", err)
+	}
+	return &Mmap{
+		name: f.name,
+		mode: mode,
+		mem:  mem,
+		data: mem[delta : delta+length],
+	}, nil
+}
+
+// Bytes 返回映射的内存。
+// 只读映射的切片不能被修改。Close之后返回nil。
+func (m *Mmap) Bytes() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data
+}
+
+// Len 返回映射的字节数。Close之后返回0。
+func (m *Mmap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// ReadAt 实现io.ReaderAt，从映射中偏移量off处复制数据到b。
+func (m *Mmap) ReadAt(b []byte, off int64) (n int, err error) { // 注：#
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.data == nil {
+		return 0, &PathError{"readat", m.name, ErrClosed}
+	}
+	if off < 0 {
+		return 0, &PathError{"readat", m.name, errors.New("negative offset")}
+	}
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n = copy(b, m.data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Sync 使用msync(2)将共享映射中被修改的页同步写回文件，并等待写入完成。
+func (m *Mmap) Sync() error { // 注：#
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.mem == nil {
+		return &PathError{"msync", m.name, ErrClosed}
+	}
+	_, _, e := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.mem[0])), uintptr(len(m.mem)), _MS_SYNC)
+	if e != 0 {
+		return &PathError{"msync", m.name, e}
+	}
+	return nil
+}
+
+const _MS_SYNC = 0x4
+
+// Advise 使用madvise(2)告知内核映射将被如何访问，
+// advice是syscall.MADV_SEQUENTIAL、syscall.MADV_RANDOM、syscall.MADV_WILLNEED等值。
+func (m *Mmap) Advise(advice int) error { // 注：#
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.mem == nil {
+		return &PathError{"madvise", m.name, ErrClosed}
+	}
+	if err := syscall.Madvise(m.mem, advice); err != nil {
+		return &PathError{"madvise", m.name, err}
+	}
+	return nil
+}
+
+// Close 解除映射。共享的可写映射中尚未写回的修改仍会由内核写回文件，
+// 需要确保数据落盘时应先调用Sync。
+// 重复调用Close返回ErrClosed。
+func (m *Mmap) Close() error { // 注：#
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mem == nil {
+		return &PathError{"munmap", m.name, ErrClosed}
+	}
+	mem := m.mem
+	m.mem, m.data = nil, nil
+	if err := syscall.Munmap(mem); err != nil {
+		return &PathError{"munmap", m.name, err}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestMmapReadOnly(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	f := createTemp(t, dir, "file", data)
+	defer f.Close()
+
+	tests := []struct {
+		offset int64
+		length int
+		want   []byte
+	}{
+		{0, 0, data},
+		{0, 10, data[:10]},
+		{4097, 0, data[4097:]},
+		{4097, 100, data[4097:4197]},
+		{int64(len(data)) - 1, 1, data[len(data)-1:]},
+	}
+	for _, tt := range tests {
+		m, err := f.Mmap(tt.offset, tt.length, MmapReadOnly)
+		if err != nil {
+			t.Errorf("Mmap(%d, %d) = %v", tt.offset, tt.length, err)
+			continue
+		}
+		if !bytes.Equal(m.Bytes(), tt.want) || m.Len() != len(tt.want) {
+			t.Errorf("Mmap(%d, %d): got %d bytes, want %d", tt.offset, tt.length, m.Len(), len(tt.want))
+		}
+		if err := m.Close(); err != nil {
+			t.Errorf("Close() = %v", err)
+		}
+	}
+}
+
+func TestMmapBounds(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	f := createTemp(t, dir, "file", make([]byte, 100))
+	defer f.Close()
+
+	for _, tt := range []struct {
+		offset int64
+		length int
+	}{
+		{-1, 10},
+		{0, -1},
+		{100, 0},
+		{0, 101},
+		{50, 51},
+		{200, 1},
+		{1 << 62, 1 << 62},
+	} {
+		m, err := f.Mmap(tt.offset, tt.length, MmapReadOnly)
+		if err == nil {
+			m.Close()
+			t.Errorf("Mmap(%d, %d, MmapReadOnly) = nil, want error", tt.offset, tt.length)
+		} else if _, ok := err.(*PathError); !ok {
+			t.Errorf("Mmap(%d, %d, MmapReadOnly) = %T, want *PathError", tt.offset, tt.length, err)
+		}
+	}
+	if _, err := f.Mmap(0, 10, MmapMode(-1)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Mmap() with invalid mode = %v, want ErrInvalid", err)
+	}
+}
+
+func TestMmapShared(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+	f := createTemp(t, dir, "file", make([]byte, 100))
+	defer f.Close()
+
+	// A writable mapping may extend past the end of the file
+	// as long as the file is grown before the pages are touched.
+	m, err := f.Mmap(0, 8192, MmapShared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(8192); err != nil {
+		t.Fatal(err)
+	}
+	copy(m.Bytes()[5000:], "shared")
+	if err := m.Sync(); err != nil {
+		t.Errorf("Sync() = %v", err)
+	}
+	if err := m.Advise(syscall.MADV_SEQUENTIAL); err != nil {
+		t.Errorf("Advise() = %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(name)
+	if string(b[5000:5006]) != "shared" {
+		t.Errorf("file contains %q after shared write, want %q", b[5000:5006], "shared")
+	}
+
+	p, err := f.Mmap(0, 0, MmapPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(p.Bytes()[5000:], "privat")
+	p.Close()
+	b, _ = ioutil.ReadFile(name)
+	if string(b[5000:5006]) != "shared" {
+		t.Errorf("file contains %q after private write, want %q", b[5000:5006], "shared")
+	}
+}
+
+func TestMmapReadAt(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	f := createTemp(t, dir, "file", []byte("hello, world"))
+	defer f.Close()
+	m, err := f.Mmap(7, 0, MmapReadOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := make([]byte, 3)
+	if n, err := m.ReadAt(b, 1); n != 3 || err != nil || string(b) != "orl" {
+		t.Errorf("ReadAt(1) = %d, %v, %q", n, err, b[:n])
+	}
+	if n, err := m.ReadAt(b, 3); n != 2 || err != io.EOF || string(b[:n]) != "ld" {
+		t.Errorf("ReadAt(3) = %d, %v, %q, want 2, EOF, %q", n, err, b[:n], "ld")
+	}
+	if n, err := m.ReadAt(b, 5); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt(5) = %d, %v, want 0, EOF", n, err)
+	}
+	if _, err := m.ReadAt(b, -1); err == nil {
+		t.Error("ReadAt(-1) = nil, want error")
+	} else if pe, ok := err.(*PathError); !ok || pe.Op != "readat" {
+		t.Errorf("ReadAt(-1) = %v, want *PathError with op readat", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Bytes() != nil || m.Len() != 0 {
+		t.Errorf("after Close: Bytes() = %v, Len() = %d", m.Bytes(), m.Len())
+	}
+	for name, err := range map[string]error{
+		"Close": m.Close(),
+		"Sync":  m.Sync(),
+		"ReadAt": func() error {
+			_, err := m.ReadAt(b, 0)
+			return err
+		}(),
+	} {
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("%s() after Close = %v, want ErrClosed", name, err)
+		}
+	}
+	if _, err := m.ReadAt(b, 0); err.(*PathError).Op != "readat" {
+		t.Errorf("ReadAt() after Close = %v, want op readat", err)
+	}
+}
@@ -0,0 +1,35 @@
+// 版权所有2011 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+const (
+	PathSeparator     = '/' // 操作系统特定的路径分隔符
+	PathListSeparator = ':' // 操作系统特定的路径列表分隔符
+)
+
+// IsPathSeparator 报告c是否为目录分隔符。
+func IsPathSeparator(c uint8) bool {
+	return PathSeparator == c
+}
+
+// basename 删除尾部的斜杠和前导目录名。
+func basename(name string) string { // 注：例："/a/b/c/" => "c"
+	i := len(name) - 1
+	// 删除尾部的斜杠
+	for ; i > 0 && name[i] == '/'; i-- {
+		name = name[:i]
+	}
+	// 删除前导目录名
+	for i--; i >= 0; i-- {
+		if name[i] == '/' {
+			name = name[i+1:]
+			break
+		}
+	}
+
+	return name
+}
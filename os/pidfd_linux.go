@@ -0,0 +1,155 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// pidfd（Linux 5.3+）是引用一个进程的文件描述符。
+// 与PID不同，进程被回收后pidfd不会指向新的进程，因此通过它等待和发送信号不会与PID的重用竞争。
+
+package os
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// 所有架构上pidfd相关系统调用的编号相同。
+const (
+	sysPIDFD_SEND_SIGNAL = 424
+	sysPIDFD_OPEN        = 434
+
+	_P_PIDFD = 3 // waitid(2)的idtype
+)
+
+// pidfdUnsupported 在内核第一次对pidfd_open(2)返回ENOSYS后被置为1。
+var pidfdUnsupported int32
+
+var (
+	pidfdOnce   sync.Once
+	pidfdUsable bool
+)
+
+// pidfdWorks 报告os包用到的pidfd操作是否全部可用。
+// pidfd_open(2)在Linux 5.3中加入，而waitid(P_PIDFD)直到5.4才被支持，
+// 因此只有pidfd_open(2)成功并不足以使用pidfd等待进程。
+func pidfdWorks() bool { // 注：#
+	pidfdOnce.Do(func() {
+		pidfdUsable = checkPidfd() == nil
+	})
+	return pidfdUsable
+}
+
+// checkPidfd 在当前进程自身的pidfd上探测pidfd_open(2)、waitid(P_PIDFD)与pidfd_send_signal(2)是否可用。
+func checkPidfd() error { // 注：#
+	fd, _, e := syscall.Syscall(sysPIDFD_OPEN, uintptr(syscall.Getpid()), 0, 0)
+	if e != 0 {
+		return e
+	}
+	defer syscall.Close(int(fd))
+
+	// 当前进程不是自己的子进程，支持P_PIDFD的内核返回ECHILD，不支持的内核返回EINVAL。
+	err := ignoringEINTR(func() error {
+		_, _, e := syscall.Syscall6(syscall.SYS_WAITID, _P_PIDFD, fd, 0, syscall.WEXITED|syscall.WNOHANG, 0, 0)
+		if e != 0 {
+			return e
+		}
+		return nil
+	})
+	if err != syscall.ECHILD {
+		return NewSyscallError("pidfd_wait", err)
+	}
+
+	// 信号0只做权限检查，不会真正发送信号。
+	if err := pidfdSendSignal(int(fd), 0); err != nil {
+		return NewSyscallError("pidfd_send_signal", err)
+	}
+	return nil
+}
+
+// pidfdOpen 返回引用进程pid的pidfd。
+// 内核不支持pidfd的全部操作时返回ENOSYS，调用方应退回到基于PID的操作。
+func pidfdOpen(pid int) (int, error) { // 注：#
+	if atomic.LoadInt32(&pidfdUnsupported) != 0 || !pidfdWorks() {
+		return -1, syscall.ENOSYS
+	}
+	fd, _, e := syscall.Syscall(sysPIDFD_OPEN, uintptr(pid), 0, 0)
+	if e != 0 {
+		if e == syscall.ENOSYS {
+			atomic.StoreInt32(&pidfdUnsupported, 1)
+		}
+		return -1, e
+	}
+	syscall.CloseOnExec(int(fd)) // 注：pidfd_open(2)总是设置O_CLOEXEC，这里只是防御
+	return int(fd), nil
+}
+
+// pidfdSendSignal 通过pidfd向进程发送信号sig。
+func pidfdSendSignal(pidfd int, sig syscall.Signal) error { // 注：#
+	_, _, e := syscall.Syscall6(sysPIDFD_SEND_SIGNAL, uintptr(pidfd), uintptr(sig), 0, 0, 0, 0)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// siginfoChild 是64位架构上waitid(2)为SIGCHLD填充的siginfo_t。
+type siginfoChild struct {
+	Signo  int32
+	Errno  int32
+	Code   int32
+	_      int32
+	Pid    int32
+	Uid    uint32
+	Status int32
+	_      [128 - 7*4]byte
+}
+
+// siginfo_t.si_code对SIGCHLD的取值。
+const (
+	_CLD_EXITED    = 1
+	_CLD_KILLED    = 2
+	_CLD_DUMPED    = 3
+	_CLD_TRAPPED   = 4
+	_CLD_STOPPED   = 5
+	_CLD_CONTINUED = 6
+)
+
+// waitStatus 将siginfoChild转换为wait4(2)形式的状态。
+func (si *siginfoChild) waitStatus() syscall.WaitStatus { // 注：#
+	const (
+		core      = 0x80
+		stopped   = 0x7f
+		continued = 0xffff
+	)
+	var ws syscall.WaitStatus
+	switch si.Code {
+	case _CLD_EXITED:
+		ws = syscall.WaitStatus(si.Status << 8)
+	case _CLD_DUMPED:
+		ws = syscall.WaitStatus(si.Status) | core
+	case _CLD_KILLED:
+		ws = syscall.WaitStatus(si.Status)
+	case _CLD_TRAPPED, _CLD_STOPPED:
+		ws = syscall.WaitStatus(si.Status<<8) | stopped
+	case _CLD_CONTINUED:
+		ws = continued
+	}
+	return ws
+}
+
+// pidfdWait 使用waitid(P_PIDFD)等待pidfd引用的进程退出，并收集它的资源使用情况。
+func pidfdWait(pidfd int, rusage *syscall.Rusage) (pid int, status syscall.WaitStatus, err error) { // 注：#
+	var info siginfoChild
+	err = ignoringEINTR(func() error {
+		_, _, e := syscall.Syscall6(syscall.SYS_WAITID, _P_PIDFD, uintptr(pidfd), uintptr(unsafe.Pointer(&info)), syscall.WEXITED, uintptr(unsafe.Pointer(rusage)), 0)
+		if e != 0 {
+			return e
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(info.Pid), info.waitStatus(), nil
+}
@@ -0,0 +1,47 @@
+// 版权所有2018 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build !js,!plan9
+
+package os
+
+import (
+	"runtime"
+)
+
+// rawConn 实现syscall.RawConn。
+type rawConn struct {
+	file *File
+}
+
+func (c *rawConn) Control(f func(uintptr)) error { // 注：以文件描述符调用f，期间文件不会被关闭
+	if err := c.file.checkValid("SyscallConn.Control"); err != nil {
+		return err
+	}
+	err := c.file.pfd.RawControl(f)
+	runtime.KeepAlive(c.file)
+	return err
+}
+
+func (c *rawConn) Read(f func(uintptr) bool) error { // 注：以文件描述符调用f，直至f返回true，期间等待文件可读
+	if err := c.file.checkValid("SyscallConn.Read"); err != nil {
+		return err
+	}
+	err := c.file.pfd.RawRead(f)
+	runtime.KeepAlive(c.file)
+	return err
+}
+
+func (c *rawConn) Write(f func(uintptr) bool) error { // 注：以文件描述符调用f，直至f返回true，期间等待文件可写
+	if err := c.file.checkValid("SyscallConn.Write"); err != nil {
+		return err
+	}
+	err := c.file.pfd.RawWrite(f)
+	runtime.KeepAlive(c.file)
+	return err
+}
+
+func newRawConn(file *File) (*rawConn, error) { // 注：工厂函数
+	return &rawConn{file: file}, nil
+}
@@ -15,6 +15,7 @@
 		IsExist(err error) bool								获取err的底层错误是否为"文件已存在"
 		IsNotExist(err error) bool							获取err的底层错误是否为"文件不存在"
 		IsPermission(err error) bool 						获取err的底层错误是否为"没有权限"
+		IsNoXattr(err error) bool 							获取err的底层错误是否为"扩展属性不存在"
 		IsTimeout(err error) bool 							获取err的底层错误是否超时
 		underlyingErrorIs(err, target error) bool 			获取err的底层错误是否为target
 		underlyingError(err error) error					获取err的底层错误
@@ -25,6 +26,7 @@
 		errNotExist() error   		返回错误："文件不存在
 		errClosed() error    		返回错误："文件已关闭"
 		errNoDeadline() error 		返回错误："文件类型不支持截止日期"
+		errNoXattr() error 			返回错误："扩展属性不存在"
 
 
 
@@ -0,0 +1,119 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"internal/testlog"
+)
+
+// Root 可以用来只访问单个目录树中的文件。
+//
+// Root上的方法只能访问位于根目录之下的文件。
+// 如果名称的任何部分（包括符号链接的目标）指向根目录之外，方法返回错误。
+// 例如，"../file"和指向根目录之外的符号链接都会被拒绝。
+//
+// 在Linux上，路径使用openat2(2)的RESOLVE_BENEATH解析；
+// 内核不支持openat2(2)时，Root逐个分量地使用openat(2)解析路径并自行展开符号链接。
+//
+// Root上的方法返回的错误是*PathError类型，越过根目录的尝试的Err是一个描述该问题的错误。
+type Root struct {
+	root *root
+}
+
+// errPathEscapes 表示路径越过了Root的根目录。
+var errPathEscapes = errors.New("path escapes from parent")
+
+// OpenRoot 打开命名目录用作Root。
+// 如果有错误，它将是*PathError类型。
+func OpenRoot(name string) (*Root, error) { // 注：#
+	testlog.Open(name)
+	return openRootNolog(name)
+}
+
+// Name 返回传递给OpenRoot的目录名称。
+func (r *Root) Name() string {
+	return r.root.name
+}
+
+// Close 关闭Root。
+// Close之后，Root上的方法返回错误；已经从Root打开的文件不受影响。
+func (r *Root) Close() error {
+	return r.root.Close()
+}
+
+// Open 打开根目录下的命名文件以供读取。
+// 详情请参阅Open。
+func (r *Root) Open(name string) (*File, error) {
+	return r.OpenFile(name, O_RDONLY, 0)
+}
+
+// Create 在根目录下创建或截断命名文件。
+// 详情请参阅Create。
+func (r *Root) Create(name string) (*File, error) {
+	return r.OpenFile(name, O_RDWR|O_CREATE|O_TRUNC, 0666)
+}
+
+// OpenFile 在根目录下打开文件。
+// 详情请参阅OpenFile。
+//
+// 如果name中的任何部分（包括符号链接的目标）位于根目录之外，则返回错误。
+func (r *Root) OpenFile(name string, flag int, perm FileMode) (*File, error) { // 注：#
+	if perm&^ModePerm != 0 {
+		return nil, &PathError{"openat", name, errors.New("unsupported file mode")}
+	}
+	r.logOpen(name)
+	f, err := rootOpenFileNolog(r, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	f.appendMode = flag&O_APPEND != 0
+	return f, nil
+}
+
+// Mkdir 在根目录下创建一个新目录。
+// 详情请参阅Mkdir。
+//
+// 如果perm包含权限位以外的位，Mkdir返回错误。
+func (r *Root) Mkdir(name string, perm FileMode) error {
+	if perm&^ModePerm != 0 {
+		return &PathError{"mkdirat", name, errors.New("unsupported file mode")}
+	}
+	return rootMkdir(r, name, perm)
+}
+
+// Remove 删除根目录下的命名文件或（空）目录。
+// 详情请参阅Remove。
+func (r *Root) Remove(name string) error {
+	return rootRemove(r, name)
+}
+
+// Stat 返回描述根目录下命名文件的FileInfo。
+// 详情请参阅Stat。
+func (r *Root) Stat(name string) (FileInfo, error) {
+	r.logStat(name)
+	return rootStat(r, name, false)
+}
+
+// Lstat 返回描述根目录下命名文件的FileInfo。
+// 如果文件是符号链接，则返回的FileInfo描述该符号链接。
+// 详情请参阅Lstat。
+func (r *Root) Lstat(name string) (FileInfo, error) {
+	r.logStat(name)
+	return rootStat(r, name, true)
+}
+
+func (r *Root) logOpen(name string) {
+	if log := testlog.Logger(); log != nil {
+		// 这里不检查错误：这只是记录根目录下的名称
+		log.Open(joinPath(r.root.name, name))
+	}
+}
+
+func (r *Root) logStat(name string) {
+	if log := testlog.Logger(); log != nil {
+		log.Stat(joinPath(r.root.name, name))
+	}
+}
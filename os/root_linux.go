@@ -0,0 +1,434 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// root 是Root在Linux上的实现，持有根目录的文件描述符。
+type root struct {
+	name string
+	dir  *File
+}
+
+func (r *root) Close() error {
+	return r.dir.Close()
+}
+
+// do 使用根目录的文件描述符调用fn。
+// 在fn执行期间持有文件描述符的引用，使并发的Close不会关闭它。
+func (r *root) do(fn func(rootfd int) error) error { // 注：#
+	var err error
+	cerr := r.dir.pfd.RawControl(func(fd uintptr) {
+		err = fn(int(fd))
+	})
+	if cerr != nil {
+		return ErrClosed
+	}
+	return err
+}
+
+func openRootNolog(name string) (*Root, error) { // 注：#
+	var fd int
+	err := ignoringEINTR(func() error {
+		var err error
+		fd, err = syscall.Open(name, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+		return err
+	})
+	if err != nil {
+		return nil, &PathError{"open", name, err}
+	}
+	return &Root{&root{name: name, dir: newFile(fd, name, kindNoPoll)}}, nil
+}
+
+func rootOpenFileNolog(r *Root, name string, flag int, perm FileMode) (*File, error) { // 注：#
+	var fd int
+	err := r.root.do(func(rootfd int) error {
+		flags := flag | syscall.O_CLOEXEC
+		var err error
+		fd, err = openat2Beneath(rootfd, name, flags, syscallMode(perm))
+		if err != syscall.ENOSYS {
+			return err
+		}
+		return walkInRoot(rootfd, name, func(parent int, base string) error {
+			fd, err = ignoringEINTROpenat(parent, base, flags|syscall.O_NOFOLLOW, syscallMode(perm))
+			if err == syscall.ELOOP && flag&syscall.O_NOFOLLOW == 0 {
+				return errFollowSymlink
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, &PathError{"openat", name, err}
+	}
+	return newFile(fd, joinPath(r.root.name, name), kindOpenFile), nil
+}
+
+func rootMkdir(r *Root, name string, perm FileMode) error { // 注：#
+	err := r.root.do(func(rootfd int) error {
+		return doInRootParent(rootfd, name, func(parent int, base string) error {
+			return ignoringEINTR(func() error {
+				return syscall.Mkdirat(parent, base, syscallMode(perm))
+			})
+		})
+	})
+	if err != nil {
+		return &PathError{"mkdirat", name, err}
+	}
+	return nil
+}
+
+func rootRemove(r *Root, name string) error { // 注：#
+	err := r.root.do(func(rootfd int) error {
+		return doInRootParent(rootfd, name, func(parent int, base string) error {
+			// 与Remove相同，两种方式都尝试
+			e := ignoringEINTR(func() error {
+				return unlinkat(parent, base, 0)
+			})
+			if e == nil {
+				return nil
+			}
+			e1 := ignoringEINTR(func() error {
+				return unlinkat(parent, base, _AT_REMOVEDIR)
+			})
+			if e1 == nil {
+				return nil
+			}
+			if e1 != syscall.ENOTDIR {
+				e = e1
+			}
+			return e
+		})
+	})
+	if err != nil {
+		return &PathError{"unlinkat", name, err}
+	}
+	return nil
+}
+
+func rootStat(r *Root, name string, lstat bool) (FileInfo, error) { // 注：#
+	var fs fileStat
+	err := r.root.do(func(rootfd int) error {
+		// 以O_PATH打开文件并对得到的文件描述符调用fstat(2)。
+		flags := syscall.O_CLOEXEC | _O_PATH
+		if lstat {
+			flags |= syscall.O_NOFOLLOW
+		}
+		fd, err := openat2Beneath(rootfd, name, flags, 0)
+		if err == nil {
+			defer syscall.Close(fd)
+			return ignoringEINTR(func() error {
+				return syscall.Fstat(fd, &fs.sys.Stat_t)
+			})
+		}
+		if err != syscall.ENOSYS {
+			return err
+		}
+		return walkInRoot(rootfd, name, func(parent int, base string) error {
+			err := ignoringEINTR(func() error {
+				return fstatat(parent, base, &fs.sys.Stat_t, _AT_SYMLINK_NOFOLLOW)
+			})
+			if err == nil && !lstat && fs.sys.Mode&syscall.S_IFMT == syscall.S_IFLNK {
+				return errFollowSymlink
+			}
+			return err
+		})
+	})
+	if err != nil {
+		op := "statat"
+		if lstat {
+			op = "lstatat"
+		}
+		return nil, &PathError{op, name, err}
+	}
+	fillFileStatFromSys(&fs, name)
+	return &fs, nil
+}
+
+// doInRootParent 解析name的父目录并对它和name的最后一个分量调用op，最后一个分量不跟随符号链接。
+// 父目录与OpenFile一样通过openat2(2)或walkInRoot按实际的目录树解析，
+// 因此"link/../f"中的".."相对于link指向的目录，而不是按词法抵消link。
+// 最后一个分量为".."时返回errPathEscapes，否则op会作用于父目录的上一级，
+// 对"..""a/.."这样的名字而言可能是根目录之外的目录。
+func doInRootParent(rootfd int, name string, op func(parent int, base string) error) error { // 注：#
+	if name == "" {
+		return syscall.ENOENT
+	}
+	dir, base := splitRootPath(name)
+	if base == ".." {
+		return errPathEscapes
+	}
+	pfd, err := openat2Beneath(rootfd, dir, syscall.O_CLOEXEC|syscall.O_DIRECTORY|_O_PATH, 0)
+	if err == nil {
+		defer syscall.Close(pfd)
+		return op(pfd, base)
+	}
+	if err != syscall.ENOSYS {
+		return err
+	}
+	return walkInRoot(rootfd, name, op)
+}
+
+// splitRootPath 将name拆分为目录与最后一个分量，例："a/b/c" => "a/b", "c"。
+func splitRootPath(name string) (dir, base string) { // 注：#
+	i := len(name) - 1
+	for i > 0 && name[i] == '/' { // 删除尾部的斜杠
+		i--
+	}
+	name = name[:i+1]
+	j := len(name) - 1
+	for j >= 0 && name[j] != '/' {
+		j--
+	}
+	dir, base = name[:j+1], name[j+1:]
+	if dir == "" {
+		dir = "."
+	}
+	if base == "" {
+		base = "."
+	}
+	return dir, base
+}
+
+// openat2(2)的系统调用编号与参数，所有架构上相同。
+const (
+	sysOPENAT2 = 437
+
+	_RESOLVE_NO_MAGICLINKS = 0x02
+	_RESOLVE_BENEATH       = 0x08
+
+	_O_PATH       = 0x200000
+	_O_TMPFILE    = 0x400000 // 内核的__O_TMPFILE，即不含O_DIRECTORY的O_TMPFILE
+	_AT_REMOVEDIR = 0x200
+)
+
+// openHow 对应内核的struct open_how。
+type openHow struct {
+	Flags   uint64
+	Mode    uint64
+	Resolve uint64
+}
+
+// openat2Unsupported 在内核第一次对openat2(2)返回ENOSYS后被置为1。
+var openat2Unsupported int32
+
+// openat2Beneath 使用openat2(2)打开rootfd之下的name，路径解析不能越过rootfd。
+// 内核不支持openat2(2)时返回ENOSYS，调用者应退回到walkInRoot。
+func openat2Beneath(rootfd int, name string, flags int, mode uint32) (int, error) { // 注：#
+	if atomic.LoadInt32(&openat2Unsupported) != 0 {
+		return -1, syscall.ENOSYS
+	}
+	if name == "" {
+		return -1, syscall.ENOENT
+	}
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return -1, err
+	}
+	if flags&(syscall.O_CREAT|_O_TMPFILE) == 0 {
+		// 不创建文件时openat2(2)要求mode为0，否则返回EINVAL。
+		mode = 0
+	}
+	how := openHow{
+		Flags:   uint64(flags),
+		Mode:    uint64(mode),
+		Resolve: _RESOLVE_BENEATH | _RESOLVE_NO_MAGICLINKS,
+	}
+	for {
+		fd, _, e := syscall.Syscall6(sysOPENAT2, uintptr(rootfd), uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&how)), unsafe.Sizeof(how), 0, 0)
+		runtime.KeepAlive(p)
+		switch e {
+		case 0:
+			return int(fd), nil
+		case syscall.EINTR:
+			continue
+		case syscall.EXDEV: // 注：路径越过了rootfd
+			return -1, errPathEscapes
+		case syscall.EAGAIN:
+			// 解析与并发的重命名发生竞争，交给逐个分量的解析处理
+			return -1, syscall.ENOSYS
+		case syscall.ENOSYS:
+			atomic.StoreInt32(&openat2Unsupported, 1)
+		}
+		return -1, e
+	}
+}
+
+// errFollowSymlink 由walkInRoot的op返回，表示最后一个分量是需要跟随的符号链接。
+var errFollowSymlink = errors.New("follow symlink")
+
+// maxSymlinks 是解析一个路径时最多跟随的符号链接数，与内核的限制相同。
+const maxSymlinks = 40
+
+// walkInRoot 在rootfd之下逐个分量地解析name，对最后一个分量调用op(parent, base)。
+// 中间分量使用O_NOFOLLOW打开，遇到符号链接时读取其目标并在当前目录中继续解析；
+// ".."只能回到已经打开的目录，越过rootfd或绝对路径的符号链接返回errPathEscapes。
+// op返回errFollowSymlink时，最后一个分量被当作符号链接展开。
+func walkInRoot(rootfd int, name string, op func(parent int, base string) error) error { // 注：#
+	if name == "" {
+		return syscall.ENOENT
+	}
+	if name[0] == '/' {
+		return errPathEscapes
+	}
+	parts := rootPathParts(name)
+
+	var dirs []int // 已打开的目录栈，为空时当前目录是rootfd
+	defer func() {
+		for _, fd := range dirs {
+			syscall.Close(fd)
+		}
+	}()
+	cur := func() int {
+		if len(dirs) == 0 {
+			return rootfd
+		}
+		return dirs[len(dirs)-1]
+	}
+
+	symlinks := 0
+	for i := 0; ; {
+		part := parts[i]
+		last := i == len(parts)-1
+		if part == ".." {
+			if len(dirs) == 0 {
+				return errPathEscapes
+			}
+			syscall.Close(dirs[len(dirs)-1])
+			dirs = dirs[:len(dirs)-1]
+			if last {
+				return op(cur(), ".")
+			}
+			i++
+			continue
+		}
+
+		var err error
+		if last {
+			err = op(cur(), part)
+			if err != errFollowSymlink {
+				return err
+			}
+		} else {
+			var fd int
+			fd, err = ignoringEINTROpenat(cur(), part, syscall.O_CLOEXEC|syscall.O_DIRECTORY|syscall.O_NOFOLLOW|_O_PATH, 0)
+			if err == nil {
+				dirs = append(dirs, fd)
+				i++
+				continue
+			}
+			if err != syscall.ELOOP && err != syscall.ENOTDIR {
+				return err
+			}
+		}
+
+		// part可能是符号链接：展开它
+		link, lerr := readlinkat(cur(), part)
+		if lerr != nil {
+			if err == errFollowSymlink {
+				err = syscall.ELOOP
+			}
+			return err
+		}
+		symlinks++
+		if symlinks > maxSymlinks {
+			return syscall.ELOOP
+		}
+		if link == "" {
+			return syscall.ENOENT
+		}
+		if link[0] == '/' {
+			return errPathEscapes
+		}
+		parts = append(rootPathParts(link), parts[i+1:]...)
+		i = 0
+	}
+}
+
+// rootPathParts 将相对路径拆分为分量，删除空分量与"."。
+// 结果至少包含一个分量。
+func rootPathParts(name string) []string { // 注：例："a//./b/" => ["a", "b"]
+	var parts []string
+	for len(name) > 0 {
+		i := 0
+		for i < len(name) && name[i] != '/' {
+			i++
+		}
+		if part := name[:i]; part != "" && part != "." {
+			parts = append(parts, part)
+		}
+		if i == len(name) {
+			break
+		}
+		name = name[i+1:]
+	}
+	if len(parts) == 0 {
+		parts = []string{"."}
+	}
+	return parts
+}
+
+func ignoringEINTROpenat(dirfd int, name string, flags int, mode uint32) (int, error) {
+	var fd int
+	err := ignoringEINTR(func() error {
+		var err error
+		fd, err = syscall.Openat(dirfd, name, flags, mode)
+		return err
+	})
+	return fd, err
+}
+
+// readlinkat 返回dirfd中符号链接name的目标。
+func readlinkat(dirfd int, name string) (string, error) { // 注：#
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+	for size := 128; ; size *= 2 {
+		b := make([]byte, size)
+		n, _, e := syscall.Syscall6(syscall.SYS_READLINKAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), 0, 0)
+		if e == syscall.EINTR {
+			size /= 2
+			continue
+		}
+		if e != 0 {
+			return "", e
+		}
+		if int(n) < size {
+			return string(b[:n]), nil
+		}
+	}
+}
+
+// unlinkat 调用unlinkat(2)。
+func unlinkat(dirfd int, name string, flags int) error {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return err
+	}
+	_, _, e := syscall.Syscall(syscall.SYS_UNLINKAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(flags))
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// fstatat 调用fstatat(2)。
+func fstatat(dirfd int, name string, st *syscall.Stat_t, flags int) error {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return err
+	}
+	_, _, e := syscall.Syscall6(sysFSTATAT, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(st)), uintptr(flags), 0, 0)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+// testRoot runs f with openat2 enabled and with the openat fallback
+// that resolves paths one component at a time.
+func testRoot(t *testing.T, f func(t *testing.T, root *Root, outer string)) {
+	for _, fallback := range []bool{false, true} {
+		name := "openat2"
+		if fallback {
+			name = "openat"
+		}
+		t.Run(name, func(t *testing.T) {
+			old := atomic.LoadInt32(Openat2Unsupported)
+			if fallback {
+				atomic.StoreInt32(Openat2Unsupported, 1)
+			}
+			defer atomic.StoreInt32(Openat2Unsupported, old)
+
+			outer := tempDir(t)
+			defer removeAll(outer)
+			dir := filepath.Join(outer, "root")
+			if err := Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			root, err := OpenRoot(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer root.Close()
+			f(t, root, outer)
+		})
+	}
+}
+
+func TestRootOpenFile(t *testing.T) {
+	testRoot(t, func(t *testing.T, root *Root, outer string) {
+		f, err := root.Create("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte("data"))
+		f.Close()
+
+		// openat2 rejects a non-zero mode without O_CREATE.
+		f, err = root.OpenFile("file", O_RDWR, 0644)
+		if err != nil {
+			t.Fatalf("OpenFile(O_RDWR, 0644) = %v", err)
+		}
+		b, err := ioutil.ReadAll(f)
+		f.Close()
+		if string(b) != "data" || err != nil {
+			t.Errorf("ReadAll() = %q, %v, want %q", b, err, "data")
+		}
+
+		if err := root.Mkdir("dir", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := Symlink("../file", filepath.Join(root.Name(), "dir", "link")); err != nil {
+			t.Fatal(err)
+		}
+		f, err = root.Open("dir/link")
+		if err != nil {
+			t.Fatalf("Open(dir/link) = %v", err)
+		}
+		f.Close()
+		if _, err := root.OpenFile("dir/link", O_RDONLY|syscall.O_NOFOLLOW, 0); err == nil {
+			t.Error("OpenFile(dir/link, O_NOFOLLOW) = nil, want error")
+		}
+		if _, err := root.Open("missing"); !IsNotExist(err) {
+			t.Errorf("Open(missing) = %v, want IsNotExist", err)
+		}
+	})
+}
+
+func TestRootEscapes(t *testing.T) {
+	testRoot(t, func(t *testing.T, root *Root, outer string) {
+		if err := ioutil.WriteFile(filepath.Join(outer, "secret"), []byte("secret"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dir := root.Name()
+		if err := Symlink("..", filepath.Join(dir, "up")); err != nil {
+			t.Fatal(err)
+		}
+		if err := Symlink(filepath.Join(outer, "secret"), filepath.Join(dir, "abs")); err != nil {
+			t.Fatal(err)
+		}
+		if err := Mkdir(filepath.Join(dir, "a"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{
+			"../secret",
+			"a/../../secret",
+			"up/secret",
+			"abs",
+			filepath.Join(outer, "secret"),
+		} {
+			if f, err := root.Open(name); err == nil {
+				f.Close()
+				t.Errorf("Open(%q) = nil, want error", name)
+			} else if _, ok := err.(*PathError); !ok {
+				t.Errorf("Open(%q) = %T, want *PathError", name, err)
+			}
+			if _, err := root.Stat(name); err == nil {
+				t.Errorf("Stat(%q) = nil, want error", name)
+			}
+		}
+
+		for _, name := range []string{"..", "../x", "a/../..", "a/../../x", "/x"} {
+			if err := root.Mkdir(name, 0755); !errors.Is(err, ErrPathEscapes) {
+				t.Errorf("Mkdir(%q) = %v, want path escape error", name, err)
+			}
+			if err := root.Remove(name); !errors.Is(err, ErrPathEscapes) {
+				t.Errorf("Remove(%q) = %v, want path escape error", name, err)
+			}
+		}
+		if _, err := Stat(filepath.Join(outer, "secret")); err != nil {
+			t.Errorf("file outside the root: %v", err)
+		}
+		if _, err := Stat(filepath.Join(outer, "x")); !IsNotExist(err) {
+			t.Errorf("Mkdir created a directory outside the root: %v", err)
+		}
+	})
+}
+
+func TestRootMkdirRemove(t *testing.T) {
+	testRoot(t, func(t *testing.T, root *Root, outer string) {
+		if err := root.Mkdir("a", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := root.Mkdir("a", 0755); !IsExist(err) {
+			t.Errorf("Mkdir(a) twice = %v, want IsExist", err)
+		}
+		// ".." that stays inside the root is allowed.
+		if err := root.Mkdir("a/../b", 0755); err != nil {
+			t.Errorf("Mkdir(a/../b) = %v", err)
+		}
+		if fi, err := root.Stat("b"); err != nil || !fi.IsDir() {
+			t.Errorf("Stat(b) = %v, %v, want directory", fi, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(root.Name(), "a", "f"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := root.Remove("a"); err == nil {
+			t.Error("Remove(non-empty a) = nil, want error")
+		}
+		for _, name := range []string{"a/f", "a/", "b/../b"} {
+			if err := root.Remove(name); err != nil {
+				t.Errorf("Remove(%q) = %v", name, err)
+			}
+		}
+		if _, err := root.Lstat("a"); !IsNotExist(err) {
+			t.Errorf("Lstat(a) after Remove = %v, want IsNotExist", err)
+		}
+		if _, err := Stat(root.Name()); err != nil {
+			t.Errorf("root directory: %v", err)
+		}
+	})
+}
+
+func TestRootDotDotAfterSymlink(t *testing.T) {
+	// With link -> sub/deep, "link/.." is sub, not the root: Mkdir and
+	// Remove must resolve ".." the same way Open and Stat do.
+	testRoot(t, func(t *testing.T, root *Root, outer string) {
+		dir := root.Name()
+		if err := Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := Mkdir(filepath.Join(dir, "sub", "deep"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := Symlink("sub/deep", filepath.Join(dir, "link")); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{filepath.Join(dir, "f"), filepath.Join(dir, "sub", "f")} {
+			if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		f, err := root.Open("link/../f")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := make([]byte, 100)
+		n, _ := f.Read(b)
+		f.Close()
+		if want := filepath.Join(dir, "sub", "f"); string(b[:n]) != want {
+			t.Fatalf("Open(link/../f) read %q, want %q", b[:n], want)
+		}
+
+		if err := root.Remove("link/../f"); err != nil {
+			t.Fatalf("Remove(link/../f) = %v", err)
+		}
+		if _, err := Lstat(filepath.Join(dir, "sub", "f")); !IsNotExist(err) {
+			t.Errorf("Remove(link/../f) left sub/f: %v", err)
+		}
+		if _, err := Lstat(filepath.Join(dir, "f")); err != nil {
+			t.Errorf("Remove(link/../f) removed the root-level f: %v", err)
+		}
+
+		if err := root.Mkdir("link/../d", 0755); err != nil {
+			t.Fatalf("Mkdir(link/../d) = %v", err)
+		}
+		if fi, err := Stat(filepath.Join(dir, "sub", "d")); err != nil || !fi.IsDir() {
+			t.Errorf("Mkdir(link/../d) did not create sub/d: %v", err)
+		}
+		if _, err := Lstat(filepath.Join(dir, "d")); !IsNotExist(err) {
+			t.Errorf("Mkdir(link/../d) created d at the root: %v", err)
+		}
+	})
+}
+
+func TestRootStat(t *testing.T) {
+	testRoot(t, func(t *testing.T, root *Root, outer string) {
+		if err := ioutil.WriteFile(filepath.Join(root.Name(), "file"), []byte("12345"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Symlink("file", filepath.Join(root.Name(), "link")); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := root.Stat("link")
+		if err != nil || fi.Size() != 5 || !fi.Mode().IsRegular() {
+			t.Errorf("Stat(link) = %v, %v, want regular file of 5 bytes", fi, err)
+		}
+		fi, err = root.Lstat("link")
+		if err != nil || fi.Mode()&ModeSymlink == 0 {
+			t.Errorf("Lstat(link) = %v, %v, want symlink", fi, err)
+		}
+	})
+}
+
+func TestRootClosed(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	root, err := OpenRoot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Close()
+	if _, err := root.Open("."); err == nil {
+		t.Error("Open() after Close = nil, want error")
+	}
+	if _, err := OpenRoot(filepath.Join(dir, "missing")); !IsNotExist(err) {
+		t.Errorf("OpenRoot(missing) = %v, want IsNotExist", err)
+	}
+}
@@ -0,0 +1,50 @@
+// 版权所有2015 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+/*
+Package signal 实现对传入信号的访问。
+
+信号主要用于类Unix系统。
+
+Go程序中信号的默认行为
+
+默认情况下，同步信号会转换为运行时panic。
+SIGHUP、SIGINT或SIGTERM信号会导致程序退出。
+SIGQUIT、SIGILL、SIGTRAP、SIGABRT、SIGSTKFLT、SIGEMT或SIGSYS信号会导致程序退出并打印堆栈信息。
+SIGTSTP、SIGTTIN或SIGTTOU信号使用系统的默认行为（通常是暂停进程）。
+SIGPROF信号由Go运行时直接处理以实现runtime.CPUProfile。
+其他信号会被捕获，但不做任何处理。
+
+改变Go程序中信号的行为
+
+本包中的函数允许程序改变Go程序处理信号的方式。
+
+Notify禁用一组异步信号的默认行为，改为通过一个或多个注册的通道传递它们。
+具体来说，它适用于SIGHUP、SIGINT、SIGQUIT、SIGABRT和SIGTERM信号，
+以及作业控制信号SIGTSTP、SIGTTIN和SIGTTOU，此时系统默认行为不会发生。
+它也适用于某些原本没有行为的信号：SIGUSR1、SIGUSR2、SIGPIPE、SIGALRM、SIGCHLD、SIGCONT、
+SIGURG、SIGXCPU、SIGXFSZ、SIGVTALRM、SIGWINCH、SIGIO、SIGPWR、SIGINFO、SIGTHR、SIGWAITING、
+SIGLWP、SIGFREEZE、SIGTHAW、SIGLOST、SIGXRES、SIGJVM1、SIGJVM2以及系统上使用的所有实时信号。
+请注意，并非所有这些信号在所有系统上都可用。
+
+如果程序启动时SIGHUP或SIGINT被忽略，并且对任何一个信号调用了Notify，则会为该信号安装信号处理程序，
+它将不再被忽略。如果程序稍后对该信号调用Reset或Ignore，或者对传递给Notify的所有通道调用Stop，
+该信号将再次被忽略。Reset将恢复系统对信号的默认行为，而Ignore将使系统完全忽略该信号。
+
+NotifyContext返回一个在收到指定信号之一时被取消的context.Context，
+这是实现守护进程优雅退出的常用方式：
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+SIGPIPE
+
+当Go程序写入断开的管道或网络连接时，操作系统会产生SIGPIPE信号。
+如果写入发生在文件描述符1或2（标准输出或标准错误）上，SIGPIPE信号将导致程序退出。
+如果写入发生在其他文件描述符上，不会对SIGPIPE做任何处理，写入将以EPIPE错误失败。
+如果程序调用Notify接收SIGPIPE信号，文件描述符编号无关紧要，SIGPIPE信号将被传递到通道，
+写入将以EPIPE错误失败。
+*/
+package signal
@@ -0,0 +1,297 @@
+// 版权所有2012 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package signal
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+var handlers struct {
+	sync.Mutex
+	// 将通道映射到应发送给它的信号。
+	m map[chan<- os.Signal]*handler
+	// 将信号映射到接收它的通道数。
+	ref [numSig]int64
+	// 正在停止的通道及其信号，见Stop。
+	stopping []stopping
+}
+
+type stopping struct {
+	c chan<- os.Signal
+	h *handler
+}
+
+// handler 是一个通道想要接收的信号的位图。
+type handler struct {
+	mask [(numSig + 31) / 32]uint32
+}
+
+func (h *handler) want(sig int) bool {
+	return (h.mask[sig/32]>>uint(sig&31))&1 != 0
+}
+
+func (h *handler) set(sig int) {
+	h.mask[sig/32] |= 1 << uint(sig&31)
+}
+
+func (h *handler) clear(sig int) {
+	h.mask[sig/32] &^= 1 << uint(sig&31)
+}
+
+// cancel 停止将sigs中的信号（为空时为所有信号）传递给任何通道，并对每个信号调用action。
+func cancel(sigs []os.Signal, action func(int)) {
+	handlers.Lock()
+	defer handlers.Unlock()
+
+	remove := func(n int) {
+		if n < 0 {
+			return
+		}
+		var zerohandler handler
+
+		for c, h := range handlers.m {
+			if h.want(n) {
+				handlers.ref[n]--
+				h.clear(n)
+				if h.mask == zerohandler.mask {
+					delete(handlers.m, c)
+				}
+			}
+		}
+
+		action(n)
+	}
+
+	if len(sigs) == 0 {
+		for n := 0; n < numSig; n++ {
+			remove(n)
+		}
+	} else {
+		for _, s := range sigs {
+			remove(signum(s))
+		}
+	}
+}
+
+// Ignore 使提供的信号被忽略。如果程序接收到它们，将不会发生任何事情。
+// Ignore撤消之前对提供的信号调用Notify的效果。
+// 如果未提供任何信号，则所有传入信号都将被忽略。
+func Ignore(sig ...os.Signal) {
+	cancel(sig, ignoreSignal)
+}
+
+// Ignored 报告sig当前是否被忽略。
+func Ignored(sig os.Signal) bool {
+	sn := signum(sig)
+	return sn >= 0 && signalIgnored(sn)
+}
+
+// Notify 使包signal将传入信号中继到c。
+// 如果未提供任何信号，则所有传入信号都将被中继到c。否则，只会中继提供的信号。
+//
+// 包signal不会阻塞发送到c：调用者必须确保c有足够的缓冲空间来跟上预期的信号速率。
+// 对于仅用于通知一个信号值的通道，大小为1的缓冲区就足够了。
+//
+// 允许使用同一通道多次调用Notify：每次调用都会扩展发送到该通道的信号集。
+// 从集合中删除信号的唯一方法是调用Stop。
+//
+// 允许使用不同的通道和相同的信号多次调用Notify：每个通道都独立接收传入信号的副本。
+func Notify(c chan<- os.Signal, sig ...os.Signal) {
+	if c == nil {
+		panic("os/signal: Notify using nil channel")
+	}
+
+	handlers.Lock()
+	defer handlers.Unlock()
+
+	h := handlers.m[c]
+	if h == nil {
+		if handlers.m == nil {
+			handlers.m = make(map[chan<- os.Signal]*handler)
+		}
+		h = new(handler)
+		handlers.m[c] = h
+	}
+
+	add := func(n int) {
+		if n < 0 {
+			return
+		}
+		if !h.want(n) {
+			h.set(n)
+			if handlers.ref[n] == 0 {
+				enableSignal(n)
+			}
+			handlers.ref[n]++
+		}
+	}
+
+	if len(sig) == 0 {
+		for n := 0; n < numSig; n++ {
+			add(n)
+		}
+	} else {
+		for _, s := range sig {
+			add(signum(s))
+		}
+	}
+}
+
+// Reset 撤消之前对提供的信号调用Notify的效果，恢复这些信号的默认行为。
+// 如果未提供任何信号，则所有信号处理程序都将被重置。
+func Reset(sig ...os.Signal) {
+	cancel(sig, disableSignal)
+}
+
+// Stop 使包signal停止将传入信号中继到c。
+// 它撤消之前所有使用c调用Notify的效果。
+// Stop返回后，可以保证c不会再收到任何信号。
+func Stop(c chan<- os.Signal) {
+	handlers.Lock()
+
+	h := handlers.m[c]
+	if h == nil {
+		handlers.Unlock()
+		return
+	}
+	delete(handlers.m, c)
+
+	for n := 0; n < numSig; n++ {
+		if h.want(n) {
+			handlers.ref[n]--
+			if handlers.ref[n] == 0 {
+				disableSignal(n)
+			}
+		}
+	}
+
+	// 信号将不再传递给该通道。
+	// 对于SIGINT这样的信号，我们要避免竞争：它应该要么被传递到通道，要么程序执行默认行为（即退出）。
+	// 为了避免信号已经被信号处理程序接收，但在下面的process函数将它发送到通道之前Stop就注销了通道，
+	// 先将通道放入正在停止的通道列表中，等待信号传递停止后再将它从列表中删除。
+	handlers.stopping = append(handlers.stopping, stopping{c, h})
+
+	handlers.Unlock()
+
+	signalWaitUntilIdle()
+
+	handlers.Lock()
+
+	for i, s := range handlers.stopping {
+		if s.c == c {
+			handlers.stopping = append(handlers.stopping[:i], handlers.stopping[i+1:]...)
+			break
+		}
+	}
+
+	handlers.Unlock()
+}
+
+// signalWaitUntilIdle 等待直到没有等待传递的信号。
+// 由runtime包定义。
+func signalWaitUntilIdle()
+
+// process 将信号sig发送给所有想要接收它的通道，不会阻塞。
+func process(sig os.Signal) {
+	n := signum(sig)
+	if n < 0 {
+		return
+	}
+
+	handlers.Lock()
+	defer handlers.Unlock()
+
+	for c, h := range handlers.m {
+		if h.want(n) {
+			// 发送但不阻塞
+			select {
+			case c <- sig:
+			default:
+			}
+		}
+	}
+
+	// 避免Stop中提到的竞争。
+	for _, d := range handlers.stopping {
+		if d.h.want(n) {
+			select {
+			case d.c <- sig:
+			default:
+			}
+		}
+	}
+}
+
+// NotifyContext 返回parent的一个副本，当列出的信号之一到达、返回的stop函数被调用
+// 或parent的Done通道被关闭时（以先发生者为准），该副本被标记为完成（其Done通道被关闭）。
+// 如果未提供任何信号，则任何信号都会使上下文完成。
+//
+// stop函数注销信号行为，与Reset一样，这可能会恢复给定信号的默认行为。
+// 例如，如果Go程序收到os.Interrupt，默认行为是退出。
+// 调用NotifyContext(parent, os.Interrupt)会将行为更改为取消返回的上下文，
+// 之后再收到的中断将不会触发默认的（退出）行为，直到调用返回的stop函数。
+//
+// stop函数会释放与其关联的资源，因此代码应在该上下文中运行的操作完成且不再需要将信号转移到上下文后立即调用stop。
+func NotifyContext(parent context.Context, signals ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := &signalCtx{
+		Context: ctx,
+		cancel:  cancel,
+		signals: signals,
+	}
+	c.ch = make(chan os.Signal, 1)
+	Notify(c.ch, c.signals...)
+	if ctx.Err() == nil {
+		go func() {
+			select {
+			case <-c.ch:
+				c.cancel()
+			case <-c.Done():
+			}
+		}()
+	}
+	return c, c.stop
+}
+
+// signalCtx 是NotifyContext返回的上下文。
+type signalCtx struct {
+	context.Context
+
+	cancel  context.CancelFunc
+	signals []os.Signal
+	ch      chan os.Signal
+}
+
+func (c *signalCtx) stop() {
+	c.cancel()
+	Stop(c.ch)
+}
+
+type stringer interface {
+	String() string
+}
+
+func (c *signalCtx) String() string {
+	var buf []byte
+	name := "context.Context"
+	if s, ok := c.Context.(stringer); ok {
+		name = s.String()
+	}
+	buf = append(buf, "signal.NotifyContext("+name...)
+	if len(c.signals) != 0 {
+		buf = append(buf, ", ["...)
+		for i, s := range c.signals {
+			buf = append(buf, s.String()...)
+			if i != len(c.signals)-1 {
+				buf = append(buf, ' ')
+			}
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, ')')
+	return string(buf)
+}
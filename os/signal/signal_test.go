@@ -0,0 +1,194 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+
+package signal
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const settleTime = 100 * time.Millisecond
+
+func waitSig(t *testing.T, c <-chan os.Signal, sig os.Signal) {
+	t.Helper()
+	select {
+	case s := <-c:
+		if s != sig {
+			t.Fatalf("signal was %v, want %v", s, sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for %v", sig)
+	}
+}
+
+func quiesce(t *testing.T, c <-chan os.Signal) {
+	t.Helper()
+	select {
+	case s := <-c:
+		t.Fatalf("unexpected signal %v", s)
+	case <-time.After(settleTime):
+	}
+}
+
+func TestNotifyStop(t *testing.T) {
+	c := make(chan os.Signal, 1)
+	Notify(c, syscall.SIGHUP)
+	defer Stop(c)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	waitSig(t, c, syscall.SIGHUP)
+
+	// Signals not passed to Notify are not delivered to c.
+	Ignore(syscall.SIGUSR2)
+	defer Reset(syscall.SIGUSR2)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	quiesce(t, c)
+
+	// A second Notify extends the set of signals.
+	Notify(c, syscall.SIGWINCH)
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	waitSig(t, c, syscall.SIGWINCH)
+
+	// After Stop, nothing more is delivered. Keep SIGHUP handled on a
+	// second channel so the process is not killed.
+	c2 := make(chan os.Signal, 1)
+	Notify(c2, syscall.SIGHUP)
+	defer Stop(c2)
+	Stop(c)
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	waitSig(t, c2, syscall.SIGHUP)
+	quiesce(t, c)
+}
+
+func TestNotifyMultipleChannels(t *testing.T) {
+	c1 := make(chan os.Signal, 1)
+	c2 := make(chan os.Signal, 1)
+	Notify(c1, syscall.SIGUSR1)
+	Notify(c2) // all signals
+	defer Stop(c1)
+	defer Stop(c2)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitSig(t, c1, syscall.SIGUSR1)
+	waitSig(t, c2, syscall.SIGUSR1)
+}
+
+func TestNotifyNilChannel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Notify(nil) did not panic")
+		}
+	}()
+	Notify(nil, syscall.SIGUSR1)
+}
+
+func TestIgnore(t *testing.T) {
+	c := make(chan os.Signal, 1)
+	Notify(c, syscall.SIGUSR1)
+	defer Stop(c)
+
+	Ignore(syscall.SIGUSR1)
+	if !Ignored(syscall.SIGUSR1) {
+		t.Error("Ignored(SIGUSR1) = false after Ignore")
+	}
+	// Ignore undoes the earlier Notify.
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	quiesce(t, c)
+
+	// Notify again takes the signal back from the ignored state.
+	Notify(c, syscall.SIGUSR1)
+	if Ignored(syscall.SIGUSR1) {
+		t.Error("Ignored(SIGUSR1) = true after Notify")
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitSig(t, c, syscall.SIGUSR1)
+
+	if Ignored(os.Signal(nil)) {
+		t.Error("Ignored(nil) = true")
+	}
+}
+
+func TestReset(t *testing.T) {
+	c := make(chan os.Signal, 1)
+	Notify(c, syscall.SIGUSR2, syscall.SIGWINCH)
+	defer Stop(c)
+
+	Reset(syscall.SIGUSR2)
+	handlers.Lock()
+	h := handlers.m[c]
+	ok := h != nil && !h.want(int(syscall.SIGUSR2)) && h.want(int(syscall.SIGWINCH))
+	handlers.Unlock()
+	if !ok {
+		t.Fatal("Reset(SIGUSR2) did not remove only SIGUSR2 from the handler")
+	}
+	// SIGWINCH is ignored by default, so it is safe to send after Reset.
+	Reset(syscall.SIGWINCH)
+	handlers.Lock()
+	_, ok = handlers.m[c]
+	handlers.Unlock()
+	if ok {
+		t.Error("channel still registered after all its signals were reset")
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	quiesce(t, c)
+}
+
+func TestNotifyContext(t *testing.T) {
+	ctx, stop := NotifyContext(context.Background(), syscall.SIGINT)
+	defer stop()
+
+	if want := "signal.NotifyContext(context.Background.WithCancel, [interrupt])"; ctx.(interface{ String() string }).String() != want {
+		t.Errorf("String() = %q, want %q", ctx, want)
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	select {
+	case <-ctx.Done():
+		if err := ctx.Err(); err != context.Canceled {
+			t.Errorf("ctx.Err() = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for SIGINT to cancel the context")
+	}
+}
+
+func TestNotifyContextStop(t *testing.T) {
+	ctx, stop := NotifyContext(context.Background(), syscall.SIGHUP)
+	c := make(chan os.Signal, 1)
+	Notify(c, syscall.SIGHUP)
+	defer Stop(c)
+
+	stop()
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("context not done after stop")
+	}
+	handlers.Lock()
+	_, ok := handlers.m[ctx.(*signalCtx).ch]
+	handlers.Unlock()
+	if ok {
+		t.Error("stop did not unregister the context's channel")
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	waitSig(t, c, syscall.SIGHUP)
+}
+
+func TestNotifyContextParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, stop := NotifyContext(parent, syscall.SIGTERM)
+	defer stop()
+
+	cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not done after the parent was canceled")
+	}
+}
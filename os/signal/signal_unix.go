@@ -0,0 +1,65 @@
+// 版权所有2012 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package signal
+
+import (
+	"os"
+	"syscall"
+)
+
+// 由runtime包通过go:linkname定义，见runtime/sigqueue.go。
+func signal_disable(uint32)
+func signal_enable(uint32)
+func signal_ignore(uint32)
+func signal_ignored(uint32) bool
+func signal_recv() uint32
+
+// loop 从runtime的信号队列中逐个取出信号并分发给注册的通道。
+func loop() {
+	for {
+		process(syscall.Signal(signal_recv()))
+	}
+}
+
+func init() {
+	signal_enable(0) // 第一次调用只用于初始化runtime的信号队列
+	go loop()
+}
+
+const (
+	numSig = 65 // 包括实时信号在内的最大信号编号加一
+)
+
+// signum 返回sig对应的信号编号，sig不是有效的syscall.Signal时返回-1。
+func signum(sig os.Signal) int {
+	switch sig := sig.(type) {
+	case syscall.Signal:
+		i := int(sig)
+		if i < 0 || i >= numSig {
+			return -1
+		}
+		return i
+	default:
+		return -1
+	}
+}
+
+func enableSignal(sig int) {
+	signal_enable(uint32(sig))
+}
+
+func disableSignal(sig int) {
+	signal_disable(uint32(sig))
+}
+
+func ignoreSignal(sig int) {
+	signal_ignore(uint32(sig))
+}
+
+func signalIgnored(sig int) bool {
+	return signal_ignored(uint32(sig))
+}
@@ -0,0 +1,22 @@
+// 版权所有2017 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import "internal/testlog"
+
+// Stat 返回描述命名文件的FileInfo。
+// 如果有错误，它将是*PathError类型。
+func Stat(name string) (FileInfo, error) { // 注：#
+	testlog.Stat(name)
+	return statNolog(name)
+}
+
+// Lstat 返回描述命名文件的FileInfo。
+// 如果文件是符号链接，则返回的FileInfo描述该符号链接，Lstat不会跟随该链接。
+// 如果有错误，它将是*PathError类型。
+func Lstat(name string) (FileInfo, error) { // 注：#
+	testlog.Stat(name)
+	return lstatNolog(name)
+}
@@ -0,0 +1,224 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	_AT_FDCWD            = -0x64
+	_AT_SYMLINK_NOFOLLOW = 0x100
+	_AT_EMPTY_PATH       = 0x1000
+
+	_AT_STATX_SYNC_AS_STAT = 0x0
+
+	_STATX_BASIC_STATS = 0x7ff
+	_STATX_ALL         = _STATX_BASIC_STATS | STATX_BTIME | STATX_MNT_ID
+)
+
+// statxTimestamp 对应内核的struct statx_timestamp。
+type statxTimestamp struct {
+	Sec  int64
+	Nsec uint32
+	_    int32
+}
+
+// statxT 对应内核的struct statx，共256字节。
+type statxT struct {
+	Mask           uint32
+	Blksize        uint32
+	Attributes     uint64
+	Nlink          uint32
+	Uid            uint32
+	Gid            uint32
+	Mode           uint16
+	_              uint16
+	Ino            uint64
+	Size           uint64
+	Blocks         uint64
+	AttributesMask uint64
+	Atime          statxTimestamp
+	Btime          statxTimestamp
+	Ctime          statxTimestamp
+	Mtime          statxTimestamp
+	RdevMajor      uint32
+	RdevMinor      uint32
+	DevMajor       uint32
+	DevMinor       uint32
+	MntID          uint64
+	_              [13]uint64
+}
+
+// statxUnsupported 在statx(2)第一次返回ENOSYS或EPERM后被置为1，之后的调用直接使用stat系列调用。
+var statxUnsupported int32
+
+// statx 调用statx(2)。
+func statx(dirfd int, path string, flags int, stx *statxT) error { // 注：#
+	if atomic.LoadInt32(&statxUnsupported) != 0 {
+		return syscall.ENOSYS
+	}
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	_, _, e := syscall.Syscall6(sysSTATX, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(flags), _STATX_ALL, uintptr(unsafe.Pointer(stx)), 0)
+	if e != 0 {
+		// statx(2)本身从不返回EPERM；EPERM表示它被seccomp过滤器拒绝，
+		// 这在不认识statx的容器运行时中很常见，此时与内核不支持同样处理。
+		if e == syscall.ENOSYS || e == syscall.EPERM {
+			atomic.StoreInt32(&statxUnsupported, 1)
+			return syscall.ENOSYS
+		}
+		return e
+	}
+	return nil
+}
+
+// statxFileStat 使用statx(2)填充fs，statx不可用（内核不支持或被过滤）时退回到fallback。
+func statxFileStat(fs *fileStat, dirfd int, path string, flags int, fallback func(*syscall.Stat_t) error) error { // 注：#
+	var stx statxT
+	err := ignoringEINTR(func() error {
+		return statx(dirfd, path, flags|_AT_STATX_SYNC_AS_STAT, &stx)
+	})
+	if err == syscall.ENOSYS {
+		return ignoringEINTR(func() error {
+			return fallback(&fs.sys.Stat_t)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	fillStatInfoFromStatx(&fs.sys, &stx)
+	return nil
+}
+
+// fillStatInfoFromStatx 将stx转换为Stat_t并记录statx特有的信息。
+func fillStatInfoFromStatx(si *StatInfo, stx *statxT) { // 注：#
+	st := &si.Stat_t
+	st.Dev = makedev(stx.DevMajor, stx.DevMinor)
+	st.Ino = stx.Ino
+	st.Mode = uint32(stx.Mode)
+	st.Uid = stx.Uid
+	st.Gid = stx.Gid
+	st.Rdev = makedev(stx.RdevMajor, stx.RdevMinor)
+	st.Size = int64(stx.Size)
+	st.Blocks = int64(stx.Blocks)
+	st.Atim = statxTimespec(stx.Atime)
+	st.Mtim = statxTimespec(stx.Mtime)
+	st.Ctim = statxTimespec(stx.Ctime)
+	setNlinkBlksize(st, stx.Nlink, stx.Blksize)
+
+	si.Mask = stx.Mask
+	si.Attributes = stx.Attributes
+	si.AttributesMask = stx.AttributesMask
+	if stx.Mask&STATX_BTIME != 0 {
+		si.Btime = time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+	}
+	if stx.Mask&STATX_MNT_ID != 0 {
+		si.MountID = stx.MntID
+	}
+}
+
+func statxTimespec(ts statxTimestamp) syscall.Timespec {
+	return syscall.Timespec{Sec: ts.Sec, Nsec: int64(ts.Nsec)}
+}
+
+// makedev 将主设备号和次设备号编码为Linux的dev_t。
+func makedev(major, minor uint32) uint64 { // 注：#
+	dev := (uint64(major) & 0x00000fff) << 8
+	dev |= (uint64(major) & 0xfffff000) << 32
+	dev |= (uint64(minor) & 0x000000ff) << 0
+	dev |= (uint64(minor) & 0xffffff00) << 12
+	return dev
+}
+
+func fillFileStatFromSys(fs *fileStat, name string) { // 注：#
+	fs.name = basename(name)
+	fs.size = fs.sys.Size
+	fs.modTime = timespecToTime(fs.sys.Mtim)
+	fs.mode = FileMode(fs.sys.Mode & 0777)
+	switch fs.sys.Mode & syscall.S_IFMT {
+	case syscall.S_IFBLK:
+		fs.mode |= ModeDevice
+	case syscall.S_IFCHR:
+		fs.mode |= ModeDevice | ModeCharDevice
+	case syscall.S_IFDIR:
+		fs.mode |= ModeDir
+	case syscall.S_IFIFO:
+		fs.mode |= ModeNamedPipe
+	case syscall.S_IFLNK:
+		fs.mode |= ModeSymlink
+	case syscall.S_IFREG:
+		// nothing to do
+	case syscall.S_IFSOCK:
+		fs.mode |= ModeSocket
+	}
+	if fs.sys.Mode&syscall.S_ISGID != 0 {
+		fs.mode |= ModeSetgid
+	}
+	if fs.sys.Mode&syscall.S_ISUID != 0 {
+		fs.mode |= ModeSetuid
+	}
+	if fs.sys.Mode&syscall.S_ISVTX != 0 {
+		fs.mode |= ModeSticky
+	}
+}
+
+func timespecToTime(ts syscall.Timespec) time.Time {
+	return time.Unix(int64(ts.Sec), int64(ts.Nsec))
+}
+
+// Stat 返回描述文件的FileInfo结构。
+// 如果有错误，它将是*PathError类型。
+func (f *File) Stat() (FileInfo, error) { // 注：#
+	if f == nil {
+		return nil, ErrInvalid
+	}
+	var fs fileStat
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) { // 注：持有引用，防止文件描述符被并发关闭
+		err = statxFileStat(&fs, int(fd), "", _AT_EMPTY_PATH, func(st *syscall.Stat_t) error {
+			return syscall.Fstat(int(fd), st)
+		})
+	})
+	if cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, f.wrapErr("stat", err)
+	}
+	fillFileStatFromSys(&fs, f.name)
+	return &fs, nil
+}
+
+// statNolog 获取文件的信息，不记录测试日志。
+func statNolog(name string) (FileInfo, error) {
+	var fs fileStat
+	err := statxFileStat(&fs, _AT_FDCWD, name, 0, func(st *syscall.Stat_t) error {
+		return syscall.Stat(name, st)
+	})
+	if err != nil {
+		return nil, &PathError{"stat", name, err}
+	}
+	fillFileStatFromSys(&fs, name)
+	return &fs, nil
+}
+
+// lstatNolog 获取文件（不跟随符号链接）的信息，不记录测试日志。
+func lstatNolog(name string) (FileInfo, error) {
+	var fs fileStat
+	err := statxFileStat(&fs, _AT_FDCWD, name, _AT_SYMLINK_NOFOLLOW, func(st *syscall.Stat_t) error {
+		return syscall.Lstat(name, st)
+	})
+	if err != nil {
+		return nil, &PathError{"lstat", name, err}
+	}
+	fillFileStatFromSys(&fs, name)
+	return &fs, nil
+}
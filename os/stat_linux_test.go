@@ -0,0 +1,120 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func testStat(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, []byte("12345"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Unix(1500000000, 123456789)
+	if err := Chtimes(name, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := Symlink("file", link); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := Stat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Name() != "link" || fi.Size() != 5 || fi.Mode() != 0640 || !fi.ModTime().Equal(mtime) {
+		t.Errorf("Stat(link) = {%q, %d, %v, %v}", fi.Name(), fi.Size(), fi.Mode(), fi.ModTime())
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		t.Fatalf("Sys() returned %T, want *syscall.Stat_t", fi.Sys())
+	}
+	if st.Size != 5 || st.Mode&syscall.S_IFMT != syscall.S_IFREG || st.Nlink != 1 {
+		t.Errorf("Sys() = {Size: %d, Mode: %#o, Nlink: %d}", st.Size, st.Mode, st.Nlink)
+	}
+
+	lfi, err := Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lfi.Mode()&ModeSymlink == 0 || SameFile(fi, lfi) {
+		t.Errorf("Lstat(link): mode %v, SameFile with target %v", lfi.Mode(), SameFile(fi, lfi))
+	}
+
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ffi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !SameFile(fi, ffi) || ffi.Name() != "file" {
+		t.Errorf("File.Stat() = %q, SameFile %v", ffi.Name(), SameFile(fi, ffi))
+	}
+
+	dfi, err := Stat(dir)
+	if err != nil || !dfi.IsDir() {
+		t.Errorf("Stat(dir) = %v, %v, want directory", dfi, err)
+	}
+	if _, err := Stat(filepath.Join(dir, "missing")); !IsNotExist(err) {
+		t.Errorf("Stat(missing) = %v, want IsNotExist", err)
+	}
+}
+
+func TestStat(t *testing.T) {
+	testStat(t)
+}
+
+func TestStatWithoutStatx(t *testing.T) {
+	old := atomic.LoadInt32(StatxUnsupported)
+	atomic.StoreInt32(StatxUnsupported, 1)
+	defer atomic.StoreInt32(StatxUnsupported, old)
+	testStat(t)
+}
+
+func TestStatxInfo(t *testing.T) {
+	fi, err := Stat(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	si, ok := StatxInfo(fi)
+	if !ok {
+		t.Fatal("StatxInfo(Stat(\".\")) not ok")
+	}
+	if st := fi.Sys().(*syscall.Stat_t); si.Ino != st.Ino || si.Dev != st.Dev {
+		t.Errorf("StatxInfo() Stat_t = {Dev: %d, Ino: %d}, want {%d, %d}", si.Dev, si.Ino, st.Dev, st.Ino)
+	}
+	if si.Mask&STATX_BTIME != 0 && si.Btime.IsZero() {
+		t.Error("StatxInfo(): STATX_BTIME set but Btime is zero")
+	}
+	if atomic.LoadInt32(StatxUnsupported) == 0 && si.Mask == 0 {
+		t.Log("statx(2) returned no extra fields")
+	}
+
+	if _, ok := StatxInfo(fakeFileInfo{}); ok {
+		t.Error("StatxInfo(fakeFileInfo{}) ok, want false")
+	}
+}
+
+type fakeFileInfo struct{}
+
+func (fakeFileInfo) Name() string       { return "fake" }
+func (fakeFileInfo) Size() int64        { return 0 }
+func (fakeFileInfo) Mode() FileMode     { return 0 }
+func (fakeFileInfo) ModTime() time.Time { return time.Time{} }
+func (fakeFileInfo) IsDir() bool        { return false }
+func (fakeFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,33 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// Simple conversions to avoid depending on strconv.
+
+package os
+
+// itoa 将val转换为十进制字符串。
+func itoa(val int) string { // 注：例：-12 => "-12"
+	if val < 0 {
+		return "-" + uitoa(uint(-val))
+	}
+	return uitoa(uint(val))
+}
+
+// uitoa 将val转换为十进制字符串。
+func uitoa(val uint) string { // 注：例：12 => "12"
+	if val == 0 { // 避免下面的字符串分配
+		return "0"
+	}
+	var buf [20]byte // 足够大以容纳64位值的十进制表示
+	i := len(buf) - 1
+	for val >= 10 {
+		q := val / 10
+		buf[i] = byte('0' + val - q*10)
+		i--
+		val = q
+	}
+	// val < 10
+	buf[i] = byte('0' + val)
+	return string(buf[i:])
+}
@@ -0,0 +1,20 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import "syscall"
+
+// 标准库syscall包中缺少的系统调用编号。
+const (
+	sysSTATX           = 291 // 注：statx(2)
+	sysCOPY_FILE_RANGE = 285 // 注：copy_file_range(2)
+	sysFSTATAT         = 79  // 注：fstatat(2)
+)
+
+// setNlinkBlksize 设置st中大小随架构变化的字段。
+func setNlinkBlksize(st *syscall.Stat_t, nlink, blksize uint32) {
+	st.Nlink = uint32(nlink)
+	st.Blksize = int32(blksize)
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNonpollableDeadline(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	f, err := Create(filepath.Join(dir, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	deadline := time.Now().Add(10 * time.Second)
+	if err := f.SetDeadline(deadline); err != ErrNoDeadline {
+		t.Errorf("SetDeadline on regular file = %v, want ErrNoDeadline", err)
+	}
+	if err := f.SetReadDeadline(deadline); err != ErrNoDeadline {
+		t.Errorf("SetReadDeadline on regular file = %v, want ErrNoDeadline", err)
+	}
+	if err := f.SetWriteDeadline(deadline); err != ErrNoDeadline {
+		t.Errorf("SetWriteDeadline on regular file = %v, want ErrNoDeadline", err)
+	}
+}
+
+func TestPipeReadDeadline(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := r.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	n, err := r.Read(make([]byte, 1))
+	if n != 0 || !IsTimeout(err) {
+		t.Fatalf("Read() = %d, %v, want timeout", n, err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Read() timed out after %v, before the deadline", d)
+	}
+	if _, ok := err.(*PathError); !ok {
+		t.Errorf("Read() error is %T, want *PathError", err)
+	}
+
+	// Clearing the deadline lets the read block until data arrives.
+	if err := r.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("x"))
+	}()
+	b := make([]byte, 1)
+	if n, err := r.Read(b); n != 1 || err != nil || b[0] != 'x' {
+		t.Errorf("Read() without deadline = %d, %v, %q", n, err, b[:n])
+	}
+
+	// A deadline in the past fails immediately.
+	r.SetDeadline(time.Now().Add(-time.Second))
+	if _, err := r.Read(b); !IsTimeout(err) {
+		t.Errorf("Read() with past deadline = %v, want timeout", err)
+	}
+}
+
+func TestPipeWriteDeadline(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := w.SetWriteDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1<<16)
+	var total int
+	for {
+		n, err := w.Write(buf)
+		total += n
+		if err != nil {
+			if !IsTimeout(err) {
+				t.Fatalf("Write() = %v, want timeout", err)
+			}
+			break
+		}
+		if total > 64<<20 {
+			t.Fatal("Write() never blocked on a full pipe")
+		}
+	}
+	if total == 0 {
+		t.Error("no data written before the deadline")
+	}
+}
+
+func TestPipeCloseUnblocksRead(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	c := make(chan error)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		c <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	r.Close()
+	select {
+	case err := <-c:
+		if err == nil || err == io.EOF {
+			t.Errorf("Read() after Close = %v, want error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not unblock Read")
+	}
+}
+
+func TestFIFODeadline(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := filepath.Join(dir, "fifo")
+	if err := syscall.Mkfifo(name, 0600); err != nil {
+		t.Skipf("Mkfifo: %v", err)
+	}
+	// O_RDWR on a FIFO does not block waiting for a peer on Linux.
+	f, err := OpenFile(name, O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatalf("SetReadDeadline on FIFO = %v", err)
+	}
+	if _, err := f.Read(make([]byte, 1)); !IsTimeout(err) {
+		t.Errorf("Read() from FIFO = %v, want timeout", err)
+	}
+}
+
+func TestChildStdoutDeadline(t *testing.T) {
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	p, err := StartProcess("/bin/sh", []string{"sh", "-c", "echo ready; exec sleep 60"}, &ProcAttr{
+		Files: []*File{nil, w, nil},
+	})
+	w.Close()
+	if err != nil {
+		t.Skipf("StartProcess: %v", err)
+	}
+	defer func() {
+		p.Kill()
+		p.Wait()
+	}()
+
+	r.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 6)
+	if _, err := io.ReadFull(r, b); err != nil || string(b) != "ready\n" {
+		t.Fatalf("ReadFull() = %q, %v", b, err)
+	}
+	r.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	if _, err := r.Read(b); !IsTimeout(err) {
+		t.Errorf("Read() from idle child = %v, want timeout", err)
+	}
+	p.Kill()
+	r.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if rest, err := ioutil.ReadAll(r); err != nil || len(rest) != 0 {
+		t.Errorf("ReadAll() after Kill = %q, %v, want EOF", rest, err)
+	}
+}
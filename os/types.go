@@ -98,6 +98,11 @@ func (m FileMode) IsRegular() bool {
 	return m&ModeType == 0
 }
 
+// Type 返回m中的类型位（m & ModeType）。
+func (m FileMode) Type() FileMode {
+	return m & ModeType
+}
+
 // Perm returns the Unix permission bits in m.
 func (m FileMode) Perm() FileMode {
 	return m & ModePerm
@@ -0,0 +1,80 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package os
+
+import (
+	"syscall"
+	"time"
+)
+
+// 在Linux上，fileStat.Sys返回*syscall.Stat_t，statx(2)提供的其他信息由StatxInfo返回。
+type fileStat struct {
+	name    string
+	size    int64
+	mode    FileMode
+	modTime time.Time
+	sys     StatInfo
+}
+
+// StatInfo 是Linux上StatxInfo返回的底层数据。
+// 内嵌的Stat_t总是有效的，它也是FileInfo.Sys返回的值（Sys不返回StatInfo本身，原因见fileStat.Sys）；其余字段只有在内核支持statx(2)并返回了对应的信息时才有效，
+// 可以通过Mask检查（例如Mask&STATX_BTIME != 0表示Btime有效）。
+type StatInfo struct {
+	syscall.Stat_t
+
+	Mask           uint32    // statx(2)实际返回的STATX_*字段
+	Btime          time.Time // 文件的创建时间
+	MountID        uint64    // 文件所在挂载点的ID，与/proc/self/mountinfo中的一致
+	Attributes     uint64    // STATX_ATTR_*文件属性
+	AttributesMask uint64    // 文件系统支持的STATX_ATTR_*属性
+}
+
+// statx(2)返回的字段掩码，用于StatInfo.Mask。
+const (
+	STATX_BTIME  = 0x800  // Btime有效
+	STATX_MNT_ID = 0x1000 // MountID有效
+)
+
+// statx(2)报告的文件属性，用于StatInfo.Attributes与StatInfo.AttributesMask。
+const (
+	STATX_ATTR_COMPRESSED = 0x4    // 文件在文件系统上被压缩
+	STATX_ATTR_IMMUTABLE  = 0x10   // 文件不可修改（chattr +i）
+	STATX_ATTR_APPEND     = 0x20   // 文件只能追加写入（chattr +a）
+	STATX_ATTR_NODUMP     = 0x40   // 文件不参与备份（chattr +d）
+	STATX_ATTR_ENCRYPTED  = 0x800  // 文件需要密钥才能解密
+	STATX_ATTR_AUTOMOUNT  = 0x1000 // 目录是自动挂载点
+	STATX_ATTR_MOUNT_ROOT = 0x2000 // 目录是挂载点的根
+)
+
+// Immutable 报告文件是否设置了不可修改属性。
+func (si *StatInfo) Immutable() bool { return si.Attributes&STATX_ATTR_IMMUTABLE != 0 }
+
+// AppendOnly 报告文件是否设置了只能追加属性。
+func (si *StatInfo) AppendOnly() bool { return si.Attributes&STATX_ATTR_APPEND != 0 }
+
+func (fs *fileStat) Size() int64        { return fs.size }
+func (fs *fileStat) Mode() FileMode     { return fs.mode }
+func (fs *fileStat) ModTime() time.Time { return fs.modTime }
+
+// Sys 返回*syscall.Stat_t，而不是包含statx(2)信息的*StatInfo。
+// 现有代码（包括archive/tar）普遍以fi.Sys().(*syscall.Stat_t)取得底层数据，
+// 改变Sys的结果类型会使这些类型断言失败，因此创建时间、挂载点ID和文件属性改由StatxInfo提供。
+func (fs *fileStat) Sys() interface{} { return &fs.sys.Stat_t }
+
+// StatxInfo 返回fi附带的StatInfo，其中包括statx(2)提供的创建时间、挂载点ID和文件属性。
+// fi必须由Stat、Lstat、File.Stat或File.Readdir返回，否则ok为false。
+func StatxInfo(fi FileInfo) (si *StatInfo, ok bool) { // 注：#
+	fs, ok := fi.(*fileStat)
+	if !ok {
+		return nil, false
+	}
+	return &fs.sys, true
+}
+
+func sameFile(fs1, fs2 *fileStat) bool { // 注：比较设备号与inode号
+	return fs1.sys.Dev == fs2.sys.Dev && fs1.sys.Ino == fs2.sys.Ino
+}
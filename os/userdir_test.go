@@ -0,0 +1,185 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// setenv sets key to value for the duration of the test.
+// An empty value unsets key.
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+	old, had := LookupEnv(key)
+	if value == "" {
+		Unsetenv(key)
+	} else if err := Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if had {
+			Setenv(key, old)
+		} else {
+			Unsetenv(key)
+		}
+	}
+}
+
+func skipNonXDG(t *testing.T) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "plan9", "android", "ios":
+		t.Skipf("XDG base directories are not used on %s", runtime.GOOS)
+	}
+}
+
+func TestUserDirs(t *testing.T) {
+	skipNonXDG(t)
+	defer setenv(t, "HOME", "/home/gopher")()
+
+	tests := []struct {
+		key  string
+		fn   func() (string, error)
+		def  string // result when key is unset, relative or empty
+		name string
+	}{
+		{"XDG_CACHE_HOME", UserCacheDir, "/home/gopher/.cache", "UserCacheDir"},
+		{"XDG_CONFIG_HOME", UserConfigDir, "/home/gopher/.config", "UserConfigDir"},
+		{"XDG_DATA_HOME", UserDataDir, "/home/gopher/.local/share", "UserDataDir"},
+		{"XDG_STATE_HOME", UserStateDir, "/home/gopher/.local/state", "UserStateDir"},
+	}
+	for _, tt := range tests {
+		for _, v := range []struct{ env, want string }{
+			{"", tt.def},
+			{"/xdg/dir", "/xdg/dir"},
+			{"relative/dir", tt.def},
+			{"./dir", tt.def},
+		} {
+			restore := setenv(t, tt.key, v.env)
+			got, err := tt.fn()
+			restore()
+			if err != nil || got != v.want {
+				t.Errorf("%s() with %s=%q = %q, %v, want %q", tt.name, tt.key, v.env, got, err, v.want)
+			}
+		}
+
+		restore := setenv(t, "HOME", "")
+		restoreKey := setenv(t, tt.key, "relative")
+		if got, err := tt.fn(); err == nil {
+			t.Errorf("%s() without $HOME and with relative %s = %q, want error", tt.name, tt.key, got)
+		}
+		restoreKey()
+		restore()
+	}
+}
+
+func TestUserRuntimeDir(t *testing.T) {
+	skipNonXDG(t)
+	for _, v := range []struct {
+		env, want string
+		ok        bool
+	}{
+		{"/run/user/1000", "/run/user/1000", true},
+		{"", "", false},
+		{"run/user/1000", "", false},
+	} {
+		restore := setenv(t, "XDG_RUNTIME_DIR", v.env)
+		got, err := UserRuntimeDir()
+		restore()
+		if (err == nil) != v.ok || got != v.want {
+			t.Errorf("UserRuntimeDir() with XDG_RUNTIME_DIR=%q = %q, %v, want %q (ok %v)", v.env, got, err, v.want, v.ok)
+		}
+	}
+}
+
+func TestUserSearchDirs(t *testing.T) {
+	skipNonXDG(t)
+	tests := []struct {
+		env    string
+		config []string
+		data   []string
+	}{
+		{"", []string{"/etc/xdg"}, []string{"/usr/local/share", "/usr/share"}},
+		{"/a:/b", []string{"/a", "/b"}, []string{"/a", "/b"}},
+		{"/a::/b:", []string{"/a", "/b"}, []string{"/a", "/b"}},
+		{"rel:/abs:./dot:/last", []string{"/abs", "/last"}, []string{"/abs", "/last"}},
+		{"relative", nil, nil},
+		{":", nil, nil},
+	}
+	for _, tt := range tests {
+		restore := setenv(t, "XDG_CONFIG_DIRS", tt.env)
+		if got := UserConfigDirs(); !reflect.DeepEqual(got, tt.config) {
+			t.Errorf("UserConfigDirs() with XDG_CONFIG_DIRS=%q = %q, want %q", tt.env, got, tt.config)
+		}
+		restore()
+		restore = setenv(t, "XDG_DATA_DIRS", tt.env)
+		if got := UserDataDirs(); !reflect.DeepEqual(got, tt.data) {
+			t.Errorf("UserDataDirs() with XDG_DATA_DIRS=%q = %q, want %q", tt.env, got, tt.data)
+		}
+		restore()
+	}
+}
+
+func TestFindUserFile(t *testing.T) {
+	skipNonXDG(t)
+	dir := tempDir(t)
+	defer removeAll(dir)
+	home := filepath.Join(dir, "home")
+	sys1 := filepath.Join(dir, "sys1")
+	sys2 := filepath.Join(dir, "sys2")
+	for _, d := range []string{home, sys1, sys2} {
+		if err := Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := Mkdir(filepath.Join(d, "app"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name string) {
+		if err := ioutil.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(sys1, "app", "both"))
+	write(filepath.Join(sys2, "app", "both"))
+	write(filepath.Join(sys2, "app", "last"))
+	write(filepath.Join(home, "app", "home"))
+	write(filepath.Join(sys1, "app", "home"))
+
+	for _, env := range []struct{ home, dirs string }{
+		{"XDG_CONFIG_HOME", "XDG_CONFIG_DIRS"},
+		{"XDG_DATA_HOME", "XDG_DATA_DIRS"},
+	} {
+		defer setenv(t, env.home, home)()
+		defer setenv(t, env.dirs, "relative:"+sys1+":"+sys2)()
+	}
+
+	for name, find := range map[string]func(string) (string, error){
+		"FindUserConfigFile": FindUserConfigFile,
+		"FindUserDataFile":   FindUserDataFile,
+	} {
+		for _, tt := range []struct{ file, want string }{
+			{"app/home", filepath.Join(home, "app", "home")},
+			{"app/both", filepath.Join(sys1, "app", "both")},
+			{"app/last", filepath.Join(sys2, "app", "last")},
+		} {
+			if got, err := find(tt.file); err != nil || got != tt.want {
+				t.Errorf("%s(%q) = %q, %v, want %q", name, tt.file, got, err, tt.want)
+			}
+		}
+		if _, err := find("app/missing"); !IsNotExist(err) {
+			t.Errorf("%s(missing) = %v, want IsNotExist", name, err)
+		}
+		for _, bad := range []string{"", "/etc/passwd"} {
+			if _, err := find(bad); err == nil || IsNotExist(err) {
+				t.Errorf("%s(%q) = %v, want invalid argument", name, bad, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import "errors"
+
+// SkipDir 用作WalkDirFunc的返回值，以指示将跳过调用中命名的目录。
+// 任何函数都不会将其作为错误返回。
+var SkipDir = errors.New("skip this directory")
+
+// WalkDirFunc 是WalkDir调用的用于访问每个文件或目录的函数类型。
+//
+// path参数包含WalkDir的参数作为前缀。
+// 也就是说，如果使用root参数"dir"调用WalkDir并在该目录中找到名为"a"的文件，
+// 则将使用参数"dir/a"调用walk函数。
+//
+// d参数是命名路径的DirEntry。
+//
+// 函数返回的错误结果控制WalkDir如何继续。
+// 如果函数返回特殊值SkipDir，则WalkDir会跳过当前目录（如果d.IsDir()为true，则为path，否则为path的父目录）。
+// 如果函数返回非nil错误，则WalkDir完全停止并返回该错误。
+//
+// err参数报告与path相关的错误，表示WalkDir将不会进入该目录。
+// 该函数可以决定如何处理该错误；如前所述，返回错误将导致WalkDir停止遍历整个树，
+// 返回nil则只记录该目录的问题并继续遍历其余部分。
+//
+// WalkDir在两种情况下使用非nil的err参数调用函数。
+//
+// 首先，如果对根目录的Lstat失败，则WalkDir调用该函数，将path设置为root，将d设置为nil，将err设置为来自Lstat的错误。
+//
+// 其次，如果目录的ReadDir方法失败，则WalkDir调用该函数，将path设置为目录的路径，
+// 将d设置为描述目录的DirEntry，将err设置为来自ReadDir的错误。
+// 在第二种情况下，对目录的路径调用了两次函数：第一次调用是在尝试读取目录之前，err为nil，
+// 使函数有机会返回SkipDir并完全避免ReadDir；第二次调用是在ReadDir失败之后，报告来自ReadDir的错误。
+// （如果ReadDir成功，则没有第二次调用。）
+// 即使ReadDir失败，在出错之前已经读到的目录项仍会被遍历。
+type WalkDirFunc func(path string, d DirEntry, err error) error
+
+// WalkDir 遍历以root为根的文件树，并为树中的每个文件或目录（包括root）调用fn。
+//
+// fn会过滤访问文件和目录时出现的所有错误，请参阅WalkDirFunc文档以获取详细信息。
+//
+// 这些文件按词法顺序遍历，这使输出具有确定性，但要求WalkDir在继续遍历该目录之前将整个目录读入内存。
+//
+// WalkDir不跟随符号链接。
+// 目录项的类型来自getdents64(2)返回的d_type，WalkDir不会对每一项调用lstat；
+// 需要完整FileInfo的调用者可以调用DirEntry.Info。
+func WalkDir(root string, fn WalkDirFunc) error { // 注：#
+	info, err := Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(root, &statDirEntry{info}, fn)
+	}
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+// walkDir 递归地遍历path，并调用fn。
+func walkDir(path string, d DirEntry, fn WalkDirFunc) error { // 注：#
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if err == SkipDir && d.IsDir() {
+			// 成功跳过目录
+			err = nil
+		}
+		return err
+	}
+
+	dirs, err := ReadDir(path)
+	if err != nil {
+		// 第二次调用，报告ReadDir错误
+		err = fn(path, d, err)
+		if err != nil {
+			if err == SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, d1 := range dirs {
+		path1 := joinPath(path, d1.Name())
+		if err := walkDir(path1, d1, fn); err != nil {
+			if err == SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// joinPath 用路径分隔符连接dir和name。
+func joinPath(dir, name string) string { // 注：例："a/" + "b" => "a/b"
+	if len(dir) > 0 && IsPathSeparator(dir[len(dir)-1]) {
+		return dir + name
+	}
+	return dir + string(PathSeparator) + name
+}
+
+// statDirEntry 使用FileInfo实现DirEntry，用于WalkDir的根。
+type statDirEntry struct {
+	info FileInfo
+}
+
+func (d *statDirEntry) Name() string            { return d.info.Name() }
+func (d *statDirEntry) IsDir() bool             { return d.info.IsDir() }
+func (d *statDirEntry) Type() FileMode          { return d.info.Mode().Type() }
+func (d *statDirEntry) Info() (FileInfo, error) { return d.info, nil }
@@ -0,0 +1,238 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// makeTree creates the following tree under a new temporary directory:
+//
+//	a/
+//	a/b/
+//	a/b/c
+//	a/d
+//	e/
+//	e/f
+//	g -> a
+//	h
+func makeTree(t *testing.T) string {
+	t.Helper()
+	dir := tempDir(t)
+	for _, d := range []string{"a", "a/b", "e"} {
+		if err := Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"a/b/c", "a/d", "e/f", "h"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Symlink("a", filepath.Join(dir, "g")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestReadDirTypes(t *testing.T) {
+	dir := makeTree(t)
+	defer removeAll(dir)
+
+	dirs, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		typ  FileMode
+	}{
+		{"a", ModeDir},
+		{"e", ModeDir},
+		{"g", ModeSymlink},
+		{"h", 0},
+	}
+	if len(dirs) != len(want) {
+		t.Fatalf("ReadDir() returned %d entries, want %d", len(dirs), len(want))
+	}
+	for i, d := range dirs {
+		if d.Name() != want[i].name || d.Type() != want[i].typ || d.IsDir() != (want[i].typ == ModeDir) {
+			t.Errorf("entry %d = {%q, %v, IsDir %v}, want {%q, %v}", i, d.Name(), d.Type(), d.IsDir(), want[i].name, want[i].typ)
+		}
+		info, err := d.Info()
+		if err != nil {
+			t.Errorf("%s: Info() = %v", d.Name(), err)
+			continue
+		}
+		if info.Name() != d.Name() || info.Mode().Type() != d.Type() {
+			t.Errorf("%s: Info() = {%q, %v}, want type %v", d.Name(), info.Name(), info.Mode(), d.Type())
+		}
+	}
+
+	if err := Remove(filepath.Join(dir, "h")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dirs[3].Info(); !IsNotExist(err) {
+		t.Errorf("Info() after Remove = %v, want IsNotExist", err)
+	}
+
+	if _, err := ReadDir(filepath.Join(dir, "missing")); !IsNotExist(err) {
+		t.Errorf("ReadDir(missing) = %v, want IsNotExist", err)
+	}
+}
+
+func TestFileReadDirBatches(t *testing.T) {
+	dir := makeTree(t)
+	defer removeAll(dir)
+
+	f, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var names []string
+	for {
+		dirs, err := f.ReadDir(3)
+		if err == io.EOF {
+			if len(dirs) != 0 {
+				t.Errorf("ReadDir(3) at EOF returned %d entries", len(dirs))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dirs) == 0 || len(dirs) > 3 {
+			t.Fatalf("ReadDir(3) returned %d entries", len(dirs))
+		}
+		for _, d := range dirs {
+			names = append(names, d.Name())
+		}
+	}
+	if len(names) != 4 {
+		t.Errorf("ReadDir(3) returned %v, want 4 names in total", names)
+	}
+
+	var nilFile *File
+	if _, err := nilFile.ReadDir(-1); err != ErrInvalid {
+		t.Errorf("nil File.ReadDir() = %v, want ErrInvalid", err)
+	}
+}
+
+func TestWalkDir(t *testing.T) {
+	dir := makeTree(t)
+	defer removeAll(dir)
+
+	tests := []struct {
+		name string
+		skip string // path for which fn returns SkipDir
+		want []string
+	}{
+		{"all", "", []string{".", "a", "a/b", "a/b/c", "a/d", "e", "e/f", "g", "h"}},
+		{"skip-dir", "a/b", []string{".", "a", "a/b", "a/d", "e", "e/f", "g", "h"}},
+		{"skip-file", "a/b/c", []string{".", "a", "a/b", "a/b/c", "a/d", "e", "e/f", "g", "h"}},
+		{"skip-siblings", "g", []string{".", "a", "a/b", "a/b/c", "a/d", "e", "e/f", "g"}},
+		{"skip-root", ".", []string{"."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := WalkDir(dir, func(path string, d DirEntry, err error) error {
+				if err != nil {
+					t.Errorf("fn(%q) called with error %v", path, err)
+					return err
+				}
+				rel, _ := filepath.Rel(dir, path)
+				rel = filepath.ToSlash(rel)
+				got = append(got, rel)
+				if rel == "g" && d.Type() != ModeSymlink {
+					t.Errorf("g: Type() = %v, want symlink", d.Type())
+				}
+				if rel == tt.skip {
+					return SkipDir
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("WalkDir() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkDir() visited\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkDirStop(t *testing.T) {
+	dir := makeTree(t)
+	defer removeAll(dir)
+
+	errStop := errors.New("stop")
+	var n int
+	err := WalkDir(dir, func(path string, d DirEntry, err error) error {
+		n++
+		if strings.HasSuffix(path, "b") {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || n != 3 {
+		t.Errorf("WalkDir() = %v after %d calls, want %v after 3 calls", err, n, errStop)
+	}
+}
+
+func TestWalkDirErrors(t *testing.T) {
+	dir := makeTree(t)
+	defer removeAll(dir)
+
+	missing := filepath.Join(dir, "missing")
+	var calls int
+	err := WalkDir(missing, func(path string, d DirEntry, err error) error {
+		calls++
+		if path != missing || d != nil || !IsNotExist(err) {
+			t.Errorf("fn(%q, %v, %v), want root with nil DirEntry and IsNotExist", path, d, err)
+		}
+		return nil
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("WalkDir(missing) = %v after %d calls, want nil after 1 call", err, calls)
+	}
+
+	if syscall.Getuid() == 0 {
+		t.Skip("skipping unreadable directory test as root")
+	}
+	locked := filepath.Join(dir, "a")
+	if err := Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer Chmod(locked, 0755)
+	var visited []string
+	var readErrs int
+	err = WalkDir(dir, func(path string, d DirEntry, err error) error {
+		if err != nil {
+			if path != locked || !errors.Is(err, syscall.EACCES) {
+				t.Errorf("fn(%q) error = %v, want EACCES for %q", path, err, locked)
+			}
+			readErrs++
+			return nil
+		}
+		visited = append(visited, filepath.Base(path))
+		return nil
+	})
+	if err != nil || readErrs != 1 {
+		t.Errorf("WalkDir() = %v with %d directory errors, want nil with 1", err, readErrs)
+	}
+	if len(visited) != 6 {
+		t.Errorf("WalkDir() visited %v, want the walk to continue past %q", visited, locked)
+	}
+}
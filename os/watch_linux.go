@@ -0,0 +1,367 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"errors"
+	"internal/poll"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// WatchOp 描述一组文件系统变化。
+type WatchOp uint32
+
+// Watcher报告的变化。
+const (
+	WatchCreate WatchOp = 1 << iota // 创建了文件或目录，或者被移动到被监视的目录中
+	WatchWrite                      // 文件内容被修改
+	WatchRemove                     // 文件或目录被删除
+	WatchRename                     // 文件或目录被重命名或移出被监视的目录
+	WatchAttrib                     // 权限、所有者、时间戳或扩展属性等元数据被修改
+)
+
+func (op WatchOp) String() string { // 注：例：WatchCreate|WatchWrite => "CREATE|WRITE"
+	var s string
+	for i, name := range []string{"CREATE", "WRITE", "REMOVE", "RENAME", "ATTRIB"} {
+		if op&(1<<uint(i)) != 0 {
+			if s != "" {
+				s += "|"
+			}
+			s += name
+		}
+	}
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// WatchEvent 描述一次文件系统变化。
+type WatchEvent struct {
+	Name   string  // 发生变化的文件的路径，以Add时传入的路径为前缀
+	Op     WatchOp // 发生的变化
+	Cookie uint32  // 非零时，关联同一次重命名的WatchRename与WatchCreate事件
+}
+
+func (e WatchEvent) String() string {
+	return e.Op.String() + " " + e.Name
+}
+
+// ErrEventOverflow 在内核的inotify事件队列溢出时发送到Watcher.Errors。
+// 收到它之后，调用者应认为可能丢失了任意事件，并重新扫描被监视的文件。
+var ErrEventOverflow = errors.New("os: inotify event queue overflow")
+
+// Watcher 使用inotify(7)监视文件与目录的变化，并通过Events通道报告。
+//
+// inotify文件描述符以非阻塞方式注册到运行时的网络轮询器中，
+// 等待事件时不会占用一个操作系统线程。
+//
+// 调用者必须持续从Events与Errors接收，否则读取事件的goroutine会阻塞。
+type Watcher struct {
+	// Events 发送文件系统变化事件。Close之后被关闭。
+	Events chan WatchEvent
+	// Errors 发送读取事件时遇到的错误，包括ErrEventOverflow。Close之后被关闭。
+	Errors chan error
+
+	pfd      poll.FD
+	mu       sync.Mutex        // 保护以下字段
+	wds      map[int32]*watch  // 内核的watch描述符到watch
+	byPath   map[string]*watch // 路径到watch
+	done     chan struct{}     // Close时关闭
+	closed   bool
+	loopDone chan struct{} // 读取事件的goroutine退出时关闭
+}
+
+// watch 是一个被监视的路径。
+type watch struct {
+	wd        int32
+	path      string
+	recursive bool // 新建的子目录是否自动被监视
+}
+
+// inotify监视的事件。
+const watchMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_DELETE | syscall.IN_DELETE_SELF | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_MOVE_SELF | syscall.IN_ATTRIB
+
+// NewWatcher 创建一个新的Watcher并开始读取事件。
+func NewWatcher() (*Watcher, error) { // 注：#
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, NewSyscallError("inotify_init1", err)
+	}
+	w := &Watcher{
+		Events:   make(chan WatchEvent),
+		Errors:   make(chan error),
+		pfd:      poll.FD{Sysfd: fd, IsStream: true},
+		wds:      make(map[int32]*watch),
+		byPath:   make(map[string]*watch),
+		done:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	if err := w.pfd.Init("inotify", true); err != nil {
+		syscall.Close(fd)
+		return nil, NewSyscallError("inotify_init1", err)
+	}
+	go w.readEvents()
+	return w, nil
+}
+
+// Add 开始监视命名文件或目录。
+// 监视目录时报告目录本身以及其中直接包含的文件的变化。
+// 重复添加同一路径只会更新监视。
+func (w *Watcher) Add(name string) error {
+	return w.add(name, false)
+}
+
+// AddRecursive 开始监视命名目录以及其下的所有子目录。
+// 之后在其中新建（或移入）的子目录会被自动监视；
+// 在添加监视之前就已经出现在新目录中的文件会补发WatchCreate事件，避免遗漏。
+func (w *Watcher) AddRecursive(name string) error { // 注：#
+	return WalkDir(name, func(path string, d DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return w.add(path, true)
+	})
+}
+
+func (w *Watcher) add(name string, recursive bool) error { // 注：#
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return &PathError{"inotify_add_watch", name, ErrClosed}
+	}
+	var wd int
+	var err error
+	cerr := w.pfd.RawControl(func(fd uintptr) {
+		wd, err = syscall.InotifyAddWatch(int(fd), name, watchMask)
+	})
+	if cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return &PathError{"inotify_add_watch", name, err}
+	}
+	wt := w.wds[int32(wd)]
+	if wt == nil {
+		wt = &watch{wd: int32(wd), path: name}
+		w.wds[wt.wd] = wt
+	} else if wt.path != name {
+		// 同一个inode通过另一个路径被添加：使用新路径
+		delete(w.byPath, wt.path)
+		wt.path = name
+	}
+	wt.recursive = wt.recursive || recursive
+	w.byPath[name] = wt
+	return nil
+}
+
+// Remove 停止监视命名文件或目录。
+// 对使用AddRecursive添加的目录，Remove只停止监视该目录本身。
+func (w *Watcher) Remove(name string) error { // 注：#
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wt := w.byPath[name]
+	if wt == nil {
+		return &PathError{"inotify_rm_watch", name, errors.New("not watched")}
+	}
+	delete(w.byPath, name)
+	delete(w.wds, wt.wd)
+	var err error
+	cerr := w.pfd.RawControl(func(fd uintptr) {
+		_, err = syscall.InotifyRmWatch(int(fd), uint32(wt.wd))
+	})
+	if cerr != nil {
+		err = cerr
+	}
+	if err != nil && err != syscall.EINVAL { // 注：EINVAL表示watch已被内核删除
+		return &PathError{"inotify_rm_watch", name, err}
+	}
+	return nil
+}
+
+// Close 停止所有监视，关闭Events与Errors通道。
+func (w *Watcher) Close() error { // 注：#
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+
+	// 关闭文件描述符会唤醒在轮询器中等待的读取
+	err := w.pfd.Close()
+	<-w.loopDone
+	if err != nil {
+		return NewSyscallError("close", err)
+	}
+	return nil
+}
+
+// readEvents 在单独的goroutine中读取并分发事件，直到Watcher被关闭。
+func (w *Watcher) readEvents() { // 注：#
+	defer func() {
+		close(w.Events)
+		close(w.Errors)
+		close(w.loopDone)
+	}()
+
+	var buf [syscall.SizeofInotifyEvent * 4096]byte // 足够容纳多个带名称的事件
+	for {
+		n, err := w.pfd.Read(buf[:])
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			if err == poll.ErrFileClosing {
+				return
+			}
+			if !w.sendError(NewSyscallError("read", err)) {
+				return
+			}
+			continue
+		}
+		if n < syscall.SizeofInotifyEvent {
+			if !w.sendError(NewSyscallError("read", errors.New("short inotify read"))) {
+				return
+			}
+			continue
+		}
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameLen := int(raw.Len)
+			var name string
+			if nameLen > 0 {
+				nb := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+nameLen]
+				for i, c := range nb { // 注：名称以NUL填充
+					if c == 0 {
+						nb = nb[:i]
+						break
+					}
+				}
+				name = string(nb)
+			}
+			off += syscall.SizeofInotifyEvent + nameLen
+			if !w.handleEvent(raw.Wd, raw.Mask, raw.Cookie, name) {
+				return
+			}
+		}
+	}
+}
+
+// handleEvent 将一个原始的inotify事件转换为WatchEvent并发送。
+// Watcher被关闭时返回false。
+func (w *Watcher) handleEvent(wd int32, mask, cookie uint32, name string) bool { // 注：#
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return w.sendError(ErrEventOverflow)
+	}
+
+	w.mu.Lock()
+	wt := w.wds[wd]
+	if wt == nil {
+		w.mu.Unlock()
+		return true // 已经被Remove的watch的剩余事件
+	}
+	path := wt.path
+	recursive := wt.recursive
+	if mask&syscall.IN_IGNORED != 0 {
+		// 内核删除了watch（被监视的文件被删除或所在文件系统被卸载）
+		delete(w.wds, wd)
+		if w.byPath[path] == wt {
+			delete(w.byPath, path)
+		}
+		w.mu.Unlock()
+		return true
+	}
+	w.mu.Unlock()
+
+	if name != "" {
+		path = joinPath(path, name)
+	}
+
+	var op WatchOp
+	if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		op |= WatchCreate
+	}
+	if mask&(syscall.IN_MODIFY|syscall.IN_CLOSE_WRITE) != 0 {
+		op |= WatchWrite
+	}
+	if mask&(syscall.IN_DELETE|syscall.IN_DELETE_SELF) != 0 {
+		op |= WatchRemove
+	}
+	if mask&(syscall.IN_MOVED_FROM|syscall.IN_MOVE_SELF) != 0 {
+		op |= WatchRename
+	}
+	if mask&syscall.IN_ATTRIB != 0 {
+		op |= WatchAttrib
+	}
+	if op == 0 {
+		return true
+	}
+	if !w.sendEvent(WatchEvent{Name: path, Op: op, Cookie: cookie}) {
+		return false
+	}
+
+	// 递归监视时，为新建或移入的子目录添加监视
+	if recursive && op&WatchCreate != 0 && mask&syscall.IN_ISDIR != 0 {
+		return w.watchNewDir(path)
+	}
+	return true
+}
+
+// watchNewDir 递归地监视新出现的目录dir。
+// 在监视建立之前就已经在dir中的文件不会产生inotify事件，因此为它们补发WatchCreate。
+func (w *Watcher) watchNewDir(dir string) bool { // 注：#
+	ok := true
+	WalkDir(dir, func(path string, d DirEntry, err error) error {
+		if err != nil {
+			// 目录可能已经被删除，之后的事件会报告它
+			if path == dir {
+				return SkipDir
+			}
+			return nil
+		}
+		if path != dir && !w.sendEvent(WatchEvent{Name: path, Op: WatchCreate}) {
+			ok = false
+			return errors.New("watcher closed")
+		}
+		if d.IsDir() {
+			if err := w.add(path, true); err != nil {
+				return SkipDir
+			}
+		}
+		return nil
+	})
+	return ok
+}
+
+// sendEvent 发送事件，Watcher被关闭时返回false。
+func (w *Watcher) sendEvent(e WatchEvent) bool {
+	select {
+	case w.Events <- e:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// sendError 发送错误，Watcher被关闭时返回false。
+func (w *Watcher) sendError(err error) bool {
+	select {
+	case w.Errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"errors"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchOpString(t *testing.T) {
+	tests := []struct {
+		op   WatchOp
+		want string
+	}{
+		{0, "0"},
+		{WatchCreate, "CREATE"},
+		{WatchWrite, "WRITE"},
+		{WatchRemove, "REMOVE"},
+		{WatchRename, "RENAME"},
+		{WatchAttrib, "ATTRIB"},
+		{WatchCreate | WatchWrite, "CREATE|WRITE"},
+		{WatchRemove | WatchRename | WatchAttrib, "REMOVE|RENAME|ATTRIB"},
+		{WatchAttrib | 1<<10, "ATTRIB"},
+	}
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("WatchOp(%#x).String() = %q, want %q", uint32(tt.op), got, tt.want)
+		}
+	}
+	e := WatchEvent{Name: "a/b", Op: WatchCreate | WatchWrite}
+	if got := e.String(); got != "CREATE|WRITE a/b" {
+		t.Errorf("WatchEvent.String() = %q, want %q", got, "CREATE|WRITE a/b")
+	}
+}
+
+func newTestWatcher(t *testing.T) *Watcher {
+	t.Helper()
+	w, err := NewWatcher()
+	if err != nil {
+		t.Skipf("NewWatcher: %v", err)
+	}
+	return w
+}
+
+// waitEvent receives events from w until one for name includes op.
+func waitEvent(t *testing.T, w *Watcher, name string, op WatchOp) WatchEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-w.Events:
+			if e.Name == name && e.Op&op != 0 {
+				return e
+			}
+		case err := <-w.Errors:
+			t.Fatalf("Errors: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v %s", op, name)
+		}
+	}
+}
+
+func TestWatcherEvents(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	w := newTestWatcher(t)
+	defer w.Close()
+	if err := w.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, name, WatchCreate)
+	waitEvent(t, w, name, WatchWrite)
+
+	if err := Chmod(name, 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, name, WatchAttrib)
+
+	renamed := filepath.Join(dir, "renamed")
+	if err := Rename(name, renamed); err != nil {
+		t.Fatal(err)
+	}
+	from := waitEvent(t, w, name, WatchRename)
+	to := waitEvent(t, w, renamed, WatchCreate)
+	if from.Cookie == 0 || from.Cookie != to.Cookie {
+		t.Errorf("rename cookies = %d, %d, want equal and non-zero", from.Cookie, to.Cookie)
+	}
+
+	if err := Remove(renamed); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, renamed, WatchRemove)
+}
+
+func TestWatcherRecursive(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	if err := Mkdir(filepath.Join(dir, "old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWatcher(t)
+	defer w.Close()
+	if err := w.AddRecursive(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing subdirectories are watched.
+	name := filepath.Join(dir, "old", "file")
+	if err := ioutil.WriteFile(name, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, name, WatchCreate)
+
+	// A directory moved in with contents is watched, and the files
+	// already in it are reported.
+	staging := tempDir(t)
+	defer removeAll(staging)
+	if err := Mkdir(filepath.Join(staging, "new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(staging, "new", "early"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	newDir := filepath.Join(dir, "new")
+	if err := Rename(filepath.Join(staging, "new"), newDir); err != nil {
+		t.Skipf("Rename across directories: %v", err)
+	}
+	waitEvent(t, w, newDir, WatchCreate)
+	waitEvent(t, w, filepath.Join(newDir, "early"), WatchCreate)
+
+	late := filepath.Join(newDir, "late")
+	if err := ioutil.WriteFile(late, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, late, WatchCreate)
+}
+
+func TestWatcherRemove(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	w := newTestWatcher(t)
+	defer w.Close()
+
+	if err := w.Remove(dir); err == nil {
+		t.Error("Remove() of unwatched path = nil, want error")
+	}
+	if err := w.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Remove(dir); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-w.Events:
+		t.Errorf("event %v after Remove", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := w.Add(filepath.Join(dir, "missing")); !IsNotExist(err) {
+		t.Errorf("Add(missing) = %v, want IsNotExist", err)
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	w := newTestWatcher(t)
+	if err := w.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-w.Events; ok {
+		t.Error("Events not closed after Close")
+	}
+	if _, ok := <-w.Errors; ok {
+		t.Error("Errors not closed after Close")
+	}
+	if err := w.Close(); err != ErrClosed {
+		t.Errorf("second Close() = %v, want ErrClosed", err)
+	}
+	if err := w.Add(dir); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add() after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,305 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// Setxattr的flags。
+const (
+	XATTR_CREATE  = 0x1 // 属性必须不存在，否则返回满足IsExist的错误
+	XATTR_REPLACE = 0x2 // 属性必须已经存在，否则返回满足IsNoXattr的错误
+)
+
+// Getxattr 返回命名文件的扩展属性attr的值。如果文件是符号链接，则读取链接目标的属性。
+// 属性不存在时返回的错误满足IsNoXattr。
+// 如果有错误，它将是*PathError类型。
+func Getxattr(name, attr string) ([]byte, error) {
+	return pathGetxattr("getxattr", syscall.SYS_GETXATTR, name, attr)
+}
+
+// Lgetxattr 与Getxattr类似，但如果文件是符号链接，则读取链接本身的属性。
+func Lgetxattr(name, attr string) ([]byte, error) {
+	return pathGetxattr("lgetxattr", syscall.SYS_LGETXATTR, name, attr)
+}
+
+// Setxattr 将命名文件的扩展属性attr设置为data。如果文件是符号链接，则设置链接目标的属性。
+// flags为0、XATTR_CREATE或XATTR_REPLACE。
+// 如果有错误，它将是*PathError类型。
+func Setxattr(name, attr string, data []byte, flags int) error {
+	return pathSetxattr("setxattr", syscall.SYS_SETXATTR, name, attr, data, flags)
+}
+
+// Lsetxattr 与Setxattr类似，但如果文件是符号链接，则设置链接本身的属性。
+func Lsetxattr(name, attr string, data []byte, flags int) error {
+	return pathSetxattr("lsetxattr", syscall.SYS_LSETXATTR, name, attr, data, flags)
+}
+
+// Listxattr 返回命名文件的所有扩展属性的名称。如果文件是符号链接，则列出链接目标的属性。
+// 如果有错误，它将是*PathError类型。
+func Listxattr(name string) ([]string, error) {
+	return pathListxattr("listxattr", syscall.SYS_LISTXATTR, name)
+}
+
+// Llistxattr 与Listxattr类似，但如果文件是符号链接，则列出链接本身的属性。
+func Llistxattr(name string) ([]string, error) {
+	return pathListxattr("llistxattr", syscall.SYS_LLISTXATTR, name)
+}
+
+// Removexattr 删除命名文件的扩展属性attr。如果文件是符号链接，则删除链接目标的属性。
+// 属性不存在时返回的错误满足IsNoXattr。
+// 如果有错误，它将是*PathError类型。
+func Removexattr(name, attr string) error {
+	return pathRemovexattr("removexattr", syscall.SYS_REMOVEXATTR, name, attr)
+}
+
+// Lremovexattr 与Removexattr类似，但如果文件是符号链接，则删除链接本身的属性。
+func Lremovexattr(name, attr string) error {
+	return pathRemovexattr("lremovexattr", syscall.SYS_LREMOVEXATTR, name, attr)
+}
+
+// Getxattr 返回文件的扩展属性attr的值。
+// 属性不存在时返回的错误满足IsNoXattr。
+func (f *File) Getxattr(attr string) ([]byte, error) { // 注：#
+	if err := f.checkValid("fgetxattr"); err != nil {
+		return nil, err
+	}
+	var data []byte
+	err := f.xattrControl(func(fd int) (err error) {
+		data, err = getxattr(syscall.SYS_FGETXATTR, uintptr(fd), attr)
+		return err
+	})
+	if err != nil {
+		return nil, f.wrapErr("fgetxattr", err)
+	}
+	return data, nil
+}
+
+// Setxattr 将文件的扩展属性attr设置为data，flags为0、XATTR_CREATE或XATTR_REPLACE。
+func (f *File) Setxattr(attr string, data []byte, flags int) error { // 注：#
+	if err := f.checkValid("fsetxattr"); err != nil {
+		return err
+	}
+	err := f.xattrControl(func(fd int) error {
+		return setxattr(syscall.SYS_FSETXATTR, uintptr(fd), attr, data, flags)
+	})
+	if err != nil {
+		return f.wrapErr("fsetxattr", err)
+	}
+	return nil
+}
+
+// Listxattr 返回文件的所有扩展属性的名称。
+func (f *File) Listxattr() ([]string, error) { // 注：#
+	if err := f.checkValid("flistxattr"); err != nil {
+		return nil, err
+	}
+	var names []string
+	err := f.xattrControl(func(fd int) (err error) {
+		names, err = listxattr(syscall.SYS_FLISTXATTR, uintptr(fd))
+		return err
+	})
+	if err != nil {
+		return nil, f.wrapErr("flistxattr", err)
+	}
+	return names, nil
+}
+
+// Removexattr 删除文件的扩展属性attr。
+func (f *File) Removexattr(attr string) error { // 注：#
+	if err := f.checkValid("fremovexattr"); err != nil {
+		return err
+	}
+	err := f.xattrControl(func(fd int) error {
+		return removexattr(syscall.SYS_FREMOVEXATTR, uintptr(fd), attr)
+	})
+	if err != nil {
+		return f.wrapErr("fremovexattr", err)
+	}
+	return nil
+}
+
+// xattrControl 在持有文件描述符引用的情况下调用fn。
+func (f *File) xattrControl(fn func(fd int) error) error {
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) {
+		err = fn(int(fd))
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func pathGetxattr(op string, trap uintptr, name, attr string) ([]byte, error) {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, &PathError{op, name, err}
+	}
+	data, err := getxattr(trap, uintptr(unsafe.Pointer(p)), attr)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, &PathError{op, name, err}
+	}
+	return data, nil
+}
+
+func pathSetxattr(op string, trap uintptr, name, attr string, data []byte, flags int) error {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return &PathError{op, name, err}
+	}
+	err = setxattr(trap, uintptr(unsafe.Pointer(p)), attr, data, flags)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return &PathError{op, name, err}
+	}
+	return nil
+}
+
+func pathListxattr(op string, trap uintptr, name string) ([]string, error) {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, &PathError{op, name, err}
+	}
+	names, err := listxattr(trap, uintptr(unsafe.Pointer(p)))
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, &PathError{op, name, err}
+	}
+	return names, nil
+}
+
+func pathRemovexattr(op string, trap uintptr, name, attr string) error {
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return &PathError{op, name, err}
+	}
+	err = removexattr(trap, uintptr(unsafe.Pointer(p)), attr)
+	runtime.KeepAlive(p)
+	if err != nil {
+		return &PathError{op, name, err}
+	}
+	return nil
+}
+
+// getxattr 使用系统调用trap（getxattr、lgetxattr或fgetxattr）读取属性attr。
+// target是路径指针或文件描述符。
+// 先以大小0查询值的长度；若在两次调用之间值变大（ERANGE），则扩大缓冲区重试。
+func getxattr(trap, target uintptr, attr string) ([]byte, error) { // 注：#
+	a, err := syscall.BytePtrFromString(attr)
+	if err != nil {
+		return nil, err
+	}
+	size := 0
+	for {
+		var buf []byte
+		var p unsafe.Pointer
+		if size > 0 {
+			buf = make([]byte, size)
+			p = unsafe.Pointer(&buf[0])
+		}
+		n, err := xattrSyscall(trap, target, uintptr(unsafe.Pointer(a)), uintptr(p), uintptr(len(buf)), 0)
+		runtime.KeepAlive(a)
+		switch {
+		case err == syscall.ERANGE:
+			size = 0 // 值变大了：重新查询长度
+			continue
+		case err != nil:
+			return nil, err
+		case size == 0 && n > 0:
+			size = n // 查询到长度，分配缓冲区后再次读取
+			continue
+		}
+		return buf[:n], nil
+	}
+}
+
+// setxattr 使用系统调用trap（setxattr、lsetxattr或fsetxattr）设置属性attr。
+func setxattr(trap, target uintptr, attr string, data []byte, flags int) error { // 注：#
+	a, err := syscall.BytePtrFromString(attr)
+	if err != nil {
+		return err
+	}
+	var p unsafe.Pointer
+	if len(data) > 0 {
+		p = unsafe.Pointer(&data[0])
+	}
+	_, err = xattrSyscall(trap, target, uintptr(unsafe.Pointer(a)), uintptr(p), uintptr(len(data)), uintptr(flags))
+	runtime.KeepAlive(a)
+	return err
+}
+
+// listxattr 使用系统调用trap（listxattr、llistxattr或flistxattr）列出所有属性的名称。
+func listxattr(trap, target uintptr) ([]string, error) { // 注：#
+	size := 0
+	for {
+		var buf []byte
+		var p unsafe.Pointer
+		if size > 0 {
+			buf = make([]byte, size)
+			p = unsafe.Pointer(&buf[0])
+		}
+		n, err := xattrSyscall(trap, target, uintptr(p), uintptr(len(buf)), 0, 0)
+		switch {
+		case err == syscall.ERANGE:
+			size = 0
+			continue
+		case err != nil:
+			return nil, err
+		case size == 0 && n > 0:
+			size = n
+			continue
+		}
+		// 名称以NUL分隔
+		var names []string
+		buf = buf[:n]
+		for len(buf) > 0 {
+			i := 0
+			for i < len(buf) && buf[i] != 0 {
+				i++
+			}
+			if i > 0 {
+				names = append(names, string(buf[:i]))
+			}
+			if i == len(buf) {
+				break
+			}
+			buf = buf[i+1:]
+		}
+		return names, nil
+	}
+}
+
+// removexattr 使用系统调用trap（removexattr、lremovexattr或fremovexattr）删除属性attr。
+func removexattr(trap, target uintptr, attr string) error {
+	a, err := syscall.BytePtrFromString(attr)
+	if err != nil {
+		return err
+	}
+	_, err = xattrSyscall(trap, target, uintptr(unsafe.Pointer(a)), 0, 0, 0)
+	runtime.KeepAlive(a)
+	return err
+}
+
+// xattrSyscall 调用扩展属性系统调用，并在被信号中断时重试。
+// 属性不存在时内核返回的ENODATA被转换为ErrNoXattr。
+func xattrSyscall(trap, a1, a2, a3, a4, a5 uintptr) (int, error) {
+	for {
+		r, _, e := syscall.Syscall6(trap, a1, a2, a3, a4, a5, 0)
+		switch e {
+		case 0:
+			return int(r), nil
+		case syscall.EINTR:
+			continue
+		case syscall.ENODATA:
+			return int(r), ErrNoXattr // 注：Linux以ENODATA报告属性不存在
+		}
+		return int(r), e
+	}
+}
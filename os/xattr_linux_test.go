@@ -0,0 +1,203 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// xattrFile creates a file and skips the test if its file system
+// does not support user extended attributes.
+func xattrFile(t *testing.T, dir string) string {
+	t.Helper()
+	name := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(name, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Setxattr(name, "user.probe", []byte("x"), 0); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) {
+			t.Skipf("user xattrs not supported: %v", err)
+		}
+		t.Fatal(err)
+	}
+	if err := Removexattr(name, "user.probe"); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestXattr(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := xattrFile(t, dir)
+
+	if err := Setxattr(name, "user.a", []byte("alpha"), 0); err != nil {
+		t.Fatal(err)
+	}
+	// A value larger than the initial buffer exercises the ERANGE retry.
+	big := bytes.Repeat([]byte("b"), 3000)
+	if err := Setxattr(name, "user.big", big, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := Setxattr(name, "user.empty", nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := Getxattr(name, "user.a"); string(v) != "alpha" || err != nil {
+		t.Errorf("Getxattr(user.a) = %q, %v", v, err)
+	}
+	if v, err := Getxattr(name, "user.big"); !bytes.Equal(v, big) || err != nil {
+		t.Errorf("Getxattr(user.big) = %d bytes, %v, want %d bytes", len(v), err, len(big))
+	}
+	if v, err := Getxattr(name, "user.empty"); len(v) != 0 || err != nil {
+		t.Errorf("Getxattr(user.empty) = %q, %v", v, err)
+	}
+
+	names, err := Listxattr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user []string
+	for _, n := range names {
+		if strings.HasPrefix(n, "user.") {
+			user = append(user, n)
+		}
+	}
+	sort.Strings(user)
+	if strings.Join(user, ",") != "user.a,user.big,user.empty" {
+		t.Errorf("Listxattr() = %q", names)
+	}
+
+	if err := Removexattr(name, "user.a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Getxattr(name, "user.a"); !IsNoXattr(err) {
+		t.Errorf("Getxattr() after Removexattr = %v, want IsNoXattr", err)
+	}
+}
+
+func TestXattrErrors(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := xattrFile(t, dir)
+
+	_, err := Getxattr(name, "user.missing")
+	if !IsNoXattr(err) || !errors.Is(err, ErrNoXattr) {
+		t.Errorf("Getxattr(missing) = %v, want ErrNoXattr", err)
+	}
+	if got, want := ErrNoXattr.Error(), "extended attribute does not exist"; got != want {
+		t.Errorf("ErrNoXattr.Error() = %q, want %q", got, want)
+	}
+	if IsNoXattr(&PathError{"read", name, syscall.ENODATA}) {
+		t.Error("IsNoXattr(ENODATA) = true for an error unrelated to extended attributes")
+	}
+	if pe, ok := err.(*PathError); !ok || pe.Op != "getxattr" || pe.Path != name {
+		t.Errorf("Getxattr(missing) = %#v, want *PathError for getxattr", err)
+	}
+	if IsNotExist(err) || IsNoXattr(ErrNotExist) {
+		t.Error("IsNoXattr and IsNotExist overlap")
+	}
+	if !IsNoXattr(ErrNoXattr) {
+		t.Error("IsNoXattr(ErrNoXattr) = false")
+	}
+	if err := Removexattr(name, "user.missing"); !IsNoXattr(err) {
+		t.Errorf("Removexattr(missing) = %v, want IsNoXattr", err)
+	}
+	if err := Setxattr(name, "user.missing", []byte("v"), XATTR_REPLACE); !IsNoXattr(err) {
+		t.Errorf("Setxattr(XATTR_REPLACE) of missing = %v, want IsNoXattr", err)
+	}
+	if err := Setxattr(name, "user.x", []byte("v"), XATTR_CREATE); err != nil {
+		t.Fatal(err)
+	}
+	if err := Setxattr(name, "user.x", []byte("v"), XATTR_CREATE); !IsExist(err) {
+		t.Errorf("Setxattr(XATTR_CREATE) of existing = %v, want IsExist", err)
+	}
+	if _, err := Getxattr(filepath.Join(dir, "missing"), "user.x"); !IsNotExist(err) || IsNoXattr(err) {
+		t.Errorf("Getxattr() of missing file = %v, want IsNotExist", err)
+	}
+}
+
+func TestLxattr(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := xattrFile(t, dir)
+	link := filepath.Join(dir, "link")
+	if err := Symlink("file", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Setxattr(link, "user.target", []byte("t"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Getxattr(name, "user.target"); string(v) != "t" || err != nil {
+		t.Errorf("Getxattr(file) = %q, %v, want attribute set through the link", v, err)
+	}
+	if _, err := Lgetxattr(link, "user.target"); !IsNoXattr(err) {
+		t.Errorf("Lgetxattr(link) = %v, want IsNoXattr", err)
+	}
+	// Linux does not allow user attributes on symlinks.
+	if err := Lsetxattr(link, "user.link", []byte("l"), 0); err == nil {
+		t.Error("Lsetxattr(link, user.link) = nil, want error")
+	}
+	if names, err := Llistxattr(link); err != nil {
+		t.Errorf("Llistxattr(link) = %v", err)
+	} else {
+		for _, n := range names {
+			if n == "user.target" {
+				t.Errorf("Llistxattr(link) = %q, includes the target's attribute", names)
+			}
+		}
+	}
+	if err := Lremovexattr(link, "user.target"); err == nil {
+		t.Error("Lremovexattr(link, user.target) = nil, want error")
+	}
+	if _, err := Getxattr(name, "user.target"); err != nil {
+		t.Errorf("Getxattr(file) after Lremovexattr(link) = %v", err)
+	}
+}
+
+func TestFileXattr(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	name := xattrFile(t, dir)
+	f, err := OpenFile(name, O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := f.Setxattr("user.f", []byte("file"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := f.Getxattr("user.f"); string(v) != "file" || err != nil {
+		t.Errorf("File.Getxattr() = %q, %v", v, err)
+	}
+	if names, err := f.Listxattr(); err != nil || len(names) == 0 {
+		t.Errorf("File.Listxattr() = %q, %v", names, err)
+	}
+	if err := f.Removexattr("user.f"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Getxattr("user.f")
+	if !IsNoXattr(err) {
+		t.Errorf("File.Getxattr() after Removexattr = %v, want IsNoXattr", err)
+	}
+	if pe, ok := err.(*PathError); !ok || pe.Op != "fgetxattr" {
+		t.Errorf("File.Getxattr() = %#v, want *PathError for fgetxattr", err)
+	}
+
+	f.Close()
+	if _, err := f.Getxattr("user.f"); !errors.Is(err, ErrClosed) {
+		t.Errorf("File.Getxattr() after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,227 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package os
+
+import (
+	"io"
+	"sync/atomic"
+	"syscall"
+)
+
+// maxCopyChunk 是单次copy_file_range/splice/sendfile调用请求的最大字节数。
+// sendfile(2)单次最多传输0x7ffff000字节，这里取一个更小的整数。
+const maxCopyChunk = 1 << 30
+
+// copyFileRangeUnsupported 在内核第一次对copy_file_range(2)返回ENOSYS后被置为1。
+var copyFileRangeUnsupported int32
+
+// readFrom 是ReadFrom的Linux快速路径。
+// 源为常规文件时使用copy_file_range(2)，源为管道时使用splice(2)。
+// handled为false时表示没有复制任何数据，调用者应退回到普通的复制。
+func (f *File) readFrom(r io.Reader) (written int64, handled bool, err error) { // 注：#
+	// copy_file_range(2)和splice(2)都不支持以O_APPEND打开的目标文件。
+	if f.appendMode {
+		return 0, false, nil
+	}
+
+	remain := int64(1<<63 - 1) // 默认复制到EOF
+	lr, ok := r.(*io.LimitedReader)
+	if ok {
+		remain, r = lr.N, lr.R
+		if remain <= 0 {
+			return 0, true, nil
+		}
+	}
+
+	var src *File
+	switch v := r.(type) {
+	case *File:
+		src = v
+	case fileWithoutWriteTo:
+		src = v.File
+	default:
+		return 0, false, nil
+	}
+	if src.checkValid("ReadFrom") != nil {
+		return 0, false, nil
+	}
+
+	typ, err := src.fileType()
+	if err != nil {
+		return 0, false, nil
+	}
+	switch typ {
+	case syscall.S_IFREG:
+		written, handled, err = copyLoop(f, src, remain, "copy_file_range", waitSrc, copyFileRange)
+	case syscall.S_IFIFO:
+		if f.nonblock {
+			// 两端都是非阻塞的管道时无法知道EAGAIN来自哪一端，交给普通的复制
+			return 0, false, nil
+		}
+		written, handled, err = copyLoop(f, src, remain, "splice", waitSrc, spliceFromPipe)
+	}
+	if lr != nil {
+		lr.N -= written
+	}
+	return written, handled, err
+}
+
+// writeTo 是WriteTo的Linux快速路径。
+// io.Copy先尝试src的WriteTo，因此目标为常规文件时在这里交给dst.readFrom，
+// 使文件之间的复制同样使用copy_file_range(2)，从而可以利用reflink和服务器端复制；
+// 该方式不可用时，f为常规文件且w为*File时使用sendfile(2)。
+func (f *File) writeTo(w io.Writer) (written int64, handled bool, err error) { // 注：#
+	var dst *File
+	switch v := w.(type) {
+	case *File:
+		dst = v
+	case fileWithoutReadFrom:
+		dst = v.File
+	default:
+		return 0, false, nil
+	}
+	if dst.checkValid("WriteTo") != nil || dst.appendMode {
+		return 0, false, nil
+	}
+	if typ, err := dst.fileType(); err == nil && typ == syscall.S_IFREG {
+		written, handled, err = dst.readFrom(f)
+		if handled {
+			return written, handled, err
+		}
+	}
+	if typ, err := f.fileType(); err != nil || typ != syscall.S_IFREG {
+		// sendfile(2)要求输入支持类似mmap的操作，即常规文件
+		return 0, false, nil
+	}
+	return copyLoop(dst, f, int64(1<<63-1), "sendfile", waitDst, sendfile)
+}
+
+// testHookCopy 在copyLoop开始复制前以所用系统调用的名称被调用，供测试检查复制走了哪条路径。
+var testHookCopy = func(name string) {}
+
+// copyWait 指定零拷贝调用返回EAGAIN时等待哪一端就绪。
+type copyWait int
+
+const (
+	waitSrc copyWait = iota // 等待src可读，例如从非阻塞的管道splice
+	waitDst                 // 等待dst可写，例如sendfile到非阻塞的管道
+)
+
+// copyLoop 反复调用copyFn将最多remain字节从src复制到dst，直到EOF。
+// 加入了轮询器的管道处于非阻塞模式，copyFn返回EAGAIN时通过轮询器等待wait指定的一端就绪，
+// 因此复制会遵守该端的截止时间。
+// 第一次调用就失败并且错误表示该方式不可用（EXDEV、ENOSYS、EINVAL等），
+// 或者第一次调用就返回0时，handled为false。
+func copyLoop(dst, src *File, remain int64, name string, wait copyWait, copyFn func(dst, src int, n int) (int, error)) (written int64, handled bool, err error) { // 注：#
+	testHookCopy(name)
+	for remain > 0 {
+		chunk := maxCopyChunk
+		if int64(chunk) > remain {
+			chunk = int(remain)
+		}
+		var n int
+		try := func(dfd, sfd uintptr) bool {
+			err = ignoringEINTR(func() error {
+				var e error
+				n, e = copyFn(int(dfd), int(sfd), chunk)
+				return e
+			})
+			return err != syscall.EAGAIN // 注：返回false时在轮询器中等待后重试
+		}
+		var cerr, werr error
+		if wait == waitSrc {
+			cerr = dst.pfd.RawControl(func(dfd uintptr) {
+				werr = src.pfd.RawRead(func(sfd uintptr) bool { return try(dfd, sfd) })
+			})
+		} else {
+			cerr = src.pfd.RawControl(func(sfd uintptr) {
+				werr = dst.pfd.RawWrite(func(dfd uintptr) bool { return try(dfd, sfd) })
+			})
+		}
+		if werr != nil {
+			err = werr // 注：等待失败，例如超过截止时间或文件被关闭
+		}
+		if cerr != nil {
+			err = cerr
+		}
+		if err != nil {
+			if written == 0 && isCopyUnsupported(err) {
+				return 0, false, nil
+			}
+			return written, true, NewSyscallError(name, err)
+		}
+		if n == 0 {
+			if written == 0 {
+				// 5.3到5.18的内核上，copy_file_range(2)对procfs、sysfs及部分FUSE文件直接返回0，
+				// 尽管文件并不为空；交给普通的复制，对于真正为空的源它也会立即遇到EOF。
+				return 0, false, nil
+			}
+			break // EOF
+		}
+		written += int64(n)
+		remain -= int64(n)
+	}
+	return written, true, nil
+}
+
+// isCopyUnsupported 报告err是否表示内核无法对这一对文件使用零拷贝，应当退回到普通复制。
+func isCopyUnsupported(err error) bool {
+	switch err {
+	case syscall.EXDEV, // 跨文件系统（5.3之前的copy_file_range）
+		syscall.ENOSYS,     // 内核不支持该系统调用
+		syscall.EINVAL,     // 文件类型或打开方式不支持
+		syscall.EOPNOTSUPP, // 文件系统不支持
+		syscall.EPERM,      // 例如seccomp过滤或不可修改的目标
+		syscall.EBADF:      // 例如目标以O_APPEND打开
+		return true
+	}
+	return false
+}
+
+// copyFileRange 使用copy_file_range(2)从src的当前偏移量复制最多n字节到dst的当前偏移量。
+func copyFileRange(dst, src int, n int) (int, error) { // 注：#
+	if atomic.LoadInt32(&copyFileRangeUnsupported) != 0 {
+		return 0, syscall.ENOSYS
+	}
+	r, _, e := syscall.Syscall6(sysCOPY_FILE_RANGE, uintptr(src), 0, uintptr(dst), 0, uintptr(n), 0)
+	if e != 0 {
+		if e == syscall.ENOSYS {
+			atomic.StoreInt32(&copyFileRangeUnsupported, 1)
+		}
+		return 0, e
+	}
+	return int(r), nil
+}
+
+// spliceFromPipe 使用splice(2)从管道src移动最多n字节到dst。
+func spliceFromPipe(dst, src int, n int) (int, error) { // 注：#
+	written, err := syscall.Splice(src, nil, dst, nil, n, _SPLICE_F_MOVE)
+	return int(written), err
+}
+
+// sendfile 使用sendfile(2)从常规文件src的当前偏移量复制最多n字节到dst。
+func sendfile(dst, src int, n int) (int, error) { // 注：#
+	return syscall.Sendfile(dst, src, nil, n)
+}
+
+const _SPLICE_F_MOVE = 0x1
+
+// fileType 返回f的S_IFMT类型位。
+func (f *File) fileType() (uint32, error) {
+	var st syscall.Stat_t
+	var err error
+	cerr := f.pfd.RawControl(func(fd uintptr) {
+		err = ignoringEINTR(func() error {
+			return syscall.Fstat(int(fd), &st)
+		})
+	})
+	if cerr != nil {
+		return 0, cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return st.Mode & syscall.S_IFMT, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	. "os"
+	"path/filepath"
+	"testing"
+)
+
+func createTemp(t *testing.T, dir, name string, data []byte) *File {
+	t.Helper()
+	f, err := OpenFile(filepath.Join(dir, name), O_RDWR|O_CREATE|O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func readBack(t *testing.T, f *File) []byte {
+	t.Helper()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestReadFromFile(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<12)
+
+	for _, limit := range []int64{-1, 0, 100, int64(len(data)), int64(len(data)) + 10} {
+		src := createTemp(t, dir, "src", data)
+		dst := createTemp(t, dir, "dst", nil)
+		var r io.Reader = src
+		want := data
+		if limit >= 0 {
+			r = &io.LimitedReader{R: src, N: limit}
+			if limit < int64(len(data)) {
+				want = data[:limit]
+			}
+		}
+		n, err := dst.ReadFrom(r)
+		if err != nil || n != int64(len(want)) {
+			t.Errorf("limit %d: ReadFrom() = %d, %v, want %d, nil", limit, n, err, len(want))
+		}
+		if got := readBack(t, dst); !bytes.Equal(got, want) {
+			t.Errorf("limit %d: copied %d bytes, want %d", limit, len(got), len(want))
+		}
+		if lr, ok := r.(*io.LimitedReader); ok && lr.N != limit-int64(len(want)) {
+			t.Errorf("limit %d: LimitedReader.N = %d, want %d", limit, lr.N, limit-int64(len(want)))
+		}
+		src.Close()
+		dst.Close()
+	}
+}
+
+func TestReadFromEmptyFile(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	src := createTemp(t, dir, "src", nil)
+	defer src.Close()
+	dst := createTemp(t, dir, "dst", nil)
+	defer dst.Close()
+	if n, err := dst.ReadFrom(src); n != 0 || err != nil {
+		t.Errorf("ReadFrom(empty) = %d, %v, want 0, nil", n, err)
+	}
+}
+
+// Before Linux 5.19, copy_file_range returns 0 for files in pseudo
+// file systems such as procfs and sysfs even though they are not empty.
+func TestReadFromProcfs(t *testing.T) {
+	const name = "/proc/self/cmdline"
+	want, err := ioutil.ReadFile(name)
+	if err != nil || len(want) == 0 {
+		t.Skipf("%s not readable: %v", name, err)
+	}
+	src, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	dir := tempDir(t)
+	defer removeAll(dir)
+	dst := createTemp(t, dir, "dst", nil)
+	defer dst.Close()
+
+	n, err := dst.ReadFrom(src)
+	if err != nil || n != int64(len(want)) {
+		t.Errorf("ReadFrom(%s) = %d, %v, want %d, nil", name, n, err, len(want))
+	}
+	if got := readBack(t, dst); !bytes.Equal(got, want) {
+		t.Errorf("ReadFrom(%s) copied %q, want %q", name, got, want)
+	}
+}
+
+func TestReadFromPipe(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	dst := createTemp(t, dir, "dst", nil)
+	defer dst.Close()
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data := bytes.Repeat([]byte("pipe"), 1<<15)
+	go func() {
+		w.Write(data)
+		w.Close()
+	}()
+	n, err := dst.ReadFrom(r)
+	if err != nil || n != int64(len(data)) {
+		t.Errorf("ReadFrom(pipe) = %d, %v, want %d, nil", n, err, len(data))
+	}
+	if got := readBack(t, dst); !bytes.Equal(got, data) {
+		t.Errorf("ReadFrom(pipe) copied %d bytes, want %d", len(got), len(data))
+	}
+}
+
+func TestWriteToPipe(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	data := bytes.Repeat([]byte("sendfile"), 1<<14)
+	src := createTemp(t, dir, "src", data)
+	defer src.Close()
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	c := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		c <- b
+	}()
+	n, err := src.WriteTo(w)
+	w.Close()
+	if err != nil || n != int64(len(data)) {
+		t.Errorf("WriteTo(pipe) = %d, %v, want %d, nil", n, err, len(data))
+	}
+	if got := <-c; !bytes.Equal(got, data) {
+		t.Errorf("WriteTo(pipe) copied %d bytes, want %d", len(got), len(data))
+	}
+}
+
+func TestReadFromAppend(t *testing.T) {
+	dir := tempDir(t)
+	defer removeAll(dir)
+	src := createTemp(t, dir, "src", []byte("world"))
+	defer src.Close()
+	name := filepath.Join(dir, "dst")
+	if err := ioutil.WriteFile(name, []byte("hello, "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst, err := OpenFile(name, O_WRONLY|O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if n, err := dst.ReadFrom(src); n != 5 || err != nil {
+		t.Errorf("ReadFrom() in append mode = %d, %v, want 5, nil", n, err)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "hello, world" {
+		t.Errorf("file contains %q, want %q", b, "hello, world")
+	}
+}
+
+func TestCopyFilePath(t *testing.T) {
+	var calls []string
+	defer func(old func(string)) { *TestHookCopy = old }(*TestHookCopy)
+	*TestHookCopy = func(name string) { calls = append(calls, name) }
+
+	dir := tempDir(t)
+	defer removeAll(dir)
+	data := bytes.Repeat([]byte("copy_file_range"), 1<<12)
+
+	// io.Copy tries src.WriteTo first; between regular files it must
+	// still use copy_file_range rather than sendfile.
+	src := createTemp(t, dir, "src", data)
+	defer src.Close()
+	dst := createTemp(t, dir, "dst", nil)
+	defer dst.Close()
+	if n, err := io.Copy(dst, src); err != nil || n != int64(len(data)) {
+		t.Fatalf("io.Copy(file, file) = %d, %v", n, err)
+	}
+	if len(calls) == 0 || calls[0] != "copy_file_range" {
+		t.Errorf("io.Copy(file, file) used %q, want copy_file_range first", calls)
+	}
+	if got := readBack(t, dst); !bytes.Equal(got, data) {
+		t.Errorf("io.Copy(file, file) copied %d bytes, want %d", len(got), len(data))
+	}
+
+	// To a pipe there is no copy_file_range; sendfile is used.
+	calls = nil
+	src.Seek(0, io.SeekStart)
+	r, w, err := Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go ioutil.ReadAll(r)
+	_, err = io.Copy(w, src)
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0] != "sendfile" {
+		t.Errorf("io.Copy(pipe, file) used %q, want [sendfile]", calls)
+	}
+}
@@ -0,0 +1,145 @@
+// 版权所有2010 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+// Unix环境变量。
+
+package syscall
+
+import "sync"
+
+var (
+	// envOnce 保护copyenv进行的初始化，copyenv填充env。
+	envOnce sync.Once
+
+	// envLock 保护env和envs。
+	envLock sync.RWMutex
+
+	// env 将环境变量映射到它在envs中第一次出现的位置。
+	env map[string]int
+
+	// envs 由runtime包提供。元素应为"key=value"的形式。
+	// 空字符串表示已删除（或应忽略的重复项）。
+	envs []string = runtime_envs()
+)
+
+func runtime_envs() []string // 在runtime包中
+
+// setenv_c 和unsetenv_c由runtime包提供，但如果没有加载cgo，它们什么也不做。
+func setenv_c(k, v string)
+func unsetenv_c(k string)
+
+func copyenv() {
+	env = make(map[string]int)
+	for i, s := range envs {
+		for j := 0; j < len(s); j++ {
+			if s[j] == '=' {
+				key := s[:j]
+				if _, ok := env[key]; !ok {
+					env[key] = i // 第一次出现key
+				} else {
+					// 清除重复的key。这使得Unsetenv可以安全地只删除第一项，
+					// 而不必担心暴露后面的一项，那可能是一个安全问题。
+					envs[i] = ""
+				}
+				break
+			}
+		}
+	}
+}
+
+func Unsetenv(key string) error {
+	envOnce.Do(copyenv)
+
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	if i, ok := env[key]; ok {
+		envs[i] = ""
+		delete(env, key)
+	}
+	unsetenv_c(key)
+	return nil
+}
+
+func Getenv(key string) (value string, found bool) {
+	envOnce.Do(copyenv)
+	if len(key) == 0 {
+		return "", false
+	}
+
+	envLock.RLock()
+	defer envLock.RUnlock()
+
+	i, ok := env[key]
+	if !ok {
+		return "", false
+	}
+	s := envs[i]
+	for i := 0; i < len(s); i++ {
+		if s[i] == '=' {
+			return s[i+1:], true
+		}
+	}
+	return "", false
+}
+
+func Setenv(key, value string) error {
+	envOnce.Do(copyenv)
+	if len(key) == 0 {
+		return EINVAL
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] == '=' || key[i] == 0 {
+			return EINVAL
+		}
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] == 0 {
+			return EINVAL
+		}
+	}
+
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	i, ok := env[key]
+	kv := key + "=" + value
+	if ok {
+		envs[i] = kv
+	} else {
+		i = len(envs)
+		envs = append(envs, kv)
+	}
+	env[key] = i
+	setenv_c(key, value)
+	return nil
+}
+
+func Clearenv() {
+	envOnce.Do(copyenv) // 防止在Getenv/Setenv中调用copyenv
+
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	for k := range env {
+		unsetenv_c(k)
+	}
+	env = make(map[string]int)
+	envs = []string{}
+}
+
+func Environ() []string {
+	envOnce.Do(copyenv)
+	envLock.RLock()
+	defer envLock.RUnlock()
+	a := make([]string, 0, len(envs))
+	for _, env := range envs {
+		if env != "" {
+			a = append(a, env)
+		}
+	}
+	return a
+}
@@ -0,0 +1,320 @@
+// 版权所有2011 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+package syscall
+
+import "unsafe"
+
+type SysProcAttr struct {
+	Chroot     string      // Chroot。
+	Credential *Credential // 凭据。
+	// Ptrace 告诉子进程调用ptrace(PTRACE_TRACEME)。
+	// 在启动设置了此项的进程之前调用runtime.LockOSThread，并且在完成跟踪之前不要调用UnlockOSThread。
+	Ptrace bool
+	Setsid bool // 创建会话。
+	// Setpgid 将子进程的进程组ID设置为Pgid，如果Pgid == 0，则设置为新的子进程ID。
+	Setpgid bool
+	// Setctty 将子进程的控制终端设置为文件描述符Ctty。
+	// Ctty必须是子进程中的描述符编号：即ProcAttr.Files的索引。
+	// 仅当Setsid为true时才有意义。
+	Setctty bool
+	Noctty  bool // 将fd 0从控制终端分离
+	Ctty    int  // 控制TTY的fd
+	// Foreground 将子进程组放在前台。这意味着Setpgid。
+	// Ctty字段必须设置为控制TTY的描述符。
+	// 与Setctty不同，在这种情况下，Ctty必须是父进程中的描述符编号。
+	Foreground   bool
+	Pgid         int     // 如果设置了Setpgid，子进程的进程组ID。
+	Pdeathsig    Signal  // 父进程终止时该进程收到的信号（仅限Linux）
+	Cloneflags   uintptr // clone调用的标志（仅限Linux）
+	Unshareflags uintptr // unshare调用的标志（仅限Linux）
+}
+
+var (
+	none  = [...]byte{'n', 'o', 'n', 'e', 0}
+	slash = [...]byte{'/', 0}
+)
+
+// linux/ptrace.h中的请求，ptrace的其他部分没有在本包中提供。
+const _PTRACE_TRACEME = 0
+
+// 在runtime包中实现。
+func runtime_BeforeFork()
+func runtime_AfterFork()
+func runtime_AfterForkInChild()
+
+// rawVforkSyscall 在asm_linux_$GOARCH.s中实现。
+func rawVforkSyscall(trap, a1 uintptr) (r1 uintptr, err Errno)
+
+// forkAndExecInChild fork子进程，将fd复制到0..len(fd)，然后在子进程中执行exec(argv0, argvv, envv)。
+// 如果dup或exec失败，则将errno写入管道。（管道设置了close-on-exec，因此如果exec成功，它将被关闭。）
+// 在子进程中，此函数不得获取任何锁，因为这些锁可能在fork时已被持有。
+// 这意味着不能重新调度、不能调用malloc，也不能增长栈。
+// 出于同样的原因，编译器不会对其进行竞态检测插桩。
+// 调用RawSyscall是可以的，因为它们是不会增长栈的汇编函数。
+//go:norace
+func forkAndExecInChild(argv0 *byte, argv, envv []*byte, chroot, dir *byte, attr *ProcAttr, sys *SysProcAttr, pipe int) (pid int, err Errno) {
+	// 设置并fork。在父进程中或出错时立即返回。
+	r1, err1, locked := forkAndExecInChild1(argv0, argv, envv, chroot, dir, attr, sys, pipe)
+	if locked {
+		runtime_AfterFork()
+	}
+	if err1 != 0 {
+		return 0, err1
+	}
+
+	// 父进程；返回PID
+	return int(r1), 0
+}
+
+// forkAndExecInChild1 实现forkAndExecInChild中直到父进程的fork后处理为止的部分。
+// 它是一个单独的函数，以便在使用vfork时将子进程和父进程的栈帧分开。
+//
+// 它是go:noinline的，因为目的就是让它与forkAndExecInChild的栈帧保持独立。
+//
+//go:noinline
+//go:norace
+func forkAndExecInChild1(argv0 *byte, argv, envv []*byte, chroot, dir *byte, attr *ProcAttr, sys *SysProcAttr, pipe int) (r1 uintptr, err1 Errno, locked bool) {
+	// vfork要求子进程不能触及父进程任何活动的栈帧。
+	// 因此，子进程在此栈帧中完成fork后的所有处理并且永不返回，
+	// 而父进程立即从此栈帧返回，并在外层栈帧中完成fork后的所有处理。
+	// 在顶部声明所有变量，以防任何声明需要堆分配（例如err1）。
+	var (
+		nextfd int
+		i      int
+	)
+
+	// 记录父进程的PID，以便子进程可以检测它是否已终止。
+	ppid, _, _ := RawSyscall(SYS_GETPID, 0, 0, 0)
+
+	// 防止下面调整fd时的副作用。
+	// 确保nextfd大于所有当前打开的文件，这样就不会有覆盖它们的风险。
+	fd := make([]int, len(attr.Files))
+	nextfd = len(attr.Files)
+	for i, ufd := range attr.Files {
+		if nextfd < int(ufd) {
+			nextfd = int(ufd)
+		}
+		fd[i] = int(ufd)
+	}
+	nextfd++
+
+	// 即将调用fork。
+	// 不能再分配内存，也不能调用非汇编函数。
+	runtime_BeforeFork()
+	locked = true
+	if sys.Cloneflags&CLONE_NEWUSER == 0 && sys.Unshareflags&CLONE_NEWUSER == 0 {
+		r1, err1 = rawVforkSyscall(SYS_CLONE, uintptr(SIGCHLD|CLONE_VFORK|CLONE_VM)|sys.Cloneflags)
+	} else {
+		r1, _, err1 = RawSyscall6(SYS_CLONE, uintptr(SIGCHLD)|sys.Cloneflags, 0, 0, 0, 0, 0)
+	}
+	if err1 != 0 || r1 != 0 {
+		// 如果在父进程中，必须立即返回，以免与子进程处于同一栈帧中。
+		// 这最多只能使用返回PC（子进程不会修改它）以及rawVforkSyscall的结果，
+		// 后者一定是在子进程被替换之后写入的。
+		return
+	}
+
+	// fork成功，现在在子进程中。
+
+	runtime_AfterForkInChild()
+
+	// 会话ID
+	if sys.Setsid {
+		_, _, err1 = RawSyscall(SYS_SETSID, 0, 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 设置进程组
+	if sys.Setpgid || sys.Foreground {
+		// 将子进程放入进程组。
+		_, _, err1 = RawSyscall(SYS_SETPGID, 0, uintptr(sys.Pgid), 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	if sys.Foreground {
+		pgrp := int32(sys.Pgid)
+		if pgrp == 0 {
+			r1, _, _ = RawSyscall(SYS_GETPID, 0, 0, 0)
+
+			pgrp = int32(r1)
+		}
+
+		// 将进程组放在前台。
+		_, _, err1 = RawSyscall(SYS_IOCTL, uintptr(sys.Ctty), uintptr(TIOCSPGRP), uintptr(unsafe.Pointer(&pgrp)))
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// Unshare
+	if sys.Unshareflags != 0 {
+		_, _, err1 = RawSyscall(SYS_UNSHARE, sys.Unshareflags, 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+		// Linux中的unshare系统调用不会取消共享以--shared挂载的挂载点，而systemd以--shared挂载/。
+		// Go的取消共享模型更像Plan 9，要求取消共享时命名空间将无条件地取消共享。
+		// 为了使此模型起作用，我们必须进一步将/标记为MS_PRIVATE。标准的unshare命令也是这样做的。
+		if sys.Unshareflags&CLONE_NEWNS == CLONE_NEWNS {
+			_, _, err1 = RawSyscall6(SYS_MOUNT, uintptr(unsafe.Pointer(&none[0])), uintptr(unsafe.Pointer(&slash[0])), 0, MS_REC|MS_PRIVATE, 0, 0)
+			if err1 != 0 {
+				goto childerror
+			}
+		}
+	}
+
+	// Chroot
+	if chroot != nil {
+		_, _, err1 = RawSyscall(SYS_CHROOT, uintptr(unsafe.Pointer(chroot)), 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 用户和组
+	if cred := sys.Credential; cred != nil {
+		ngroups := uintptr(len(cred.Groups))
+		groups := uintptr(0)
+		if ngroups > 0 {
+			groups = uintptr(unsafe.Pointer(&cred.Groups[0]))
+		}
+		if !cred.NoSetGroups {
+			_, _, err1 = RawSyscall(SYS_SETGROUPS, ngroups, groups, 0)
+			if err1 != 0 {
+				goto childerror
+			}
+		}
+		_, _, err1 = RawSyscall(SYS_SETGID, uintptr(cred.Gid), 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+		_, _, err1 = RawSyscall(SYS_SETUID, uintptr(cred.Uid), 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// Chdir
+	if dir != nil {
+		_, _, err1 = RawSyscall(SYS_CHDIR, uintptr(unsafe.Pointer(dir)), 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 父进程终止信号
+	if sys.Pdeathsig != 0 {
+		_, _, err1 = RawSyscall6(SYS_PRCTL, PR_SET_PDEATHSIG, uintptr(sys.Pdeathsig), 0, 0, 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+
+		// 如果父进程已经终止，则向自己发送信号。
+		// 在极少数情况下这可能会导致重复的信号，但使用SIGKILL时无关紧要。
+		r1, _, _ = RawSyscall(SYS_GETPPID, 0, 0, 0)
+		if r1 != ppid {
+			pid, _, _ := RawSyscall(SYS_GETPID, 0, 0, 0)
+			_, _, err1 := RawSyscall(SYS_KILL, pid, uintptr(sys.Pdeathsig), 0)
+			if err1 != 0 {
+				goto childerror
+			}
+		}
+	}
+
+	// 第1步：查找fd[i] < i的项，并将它们移到len(fd)之上，这样第2步就不会覆盖之后需要的fd。
+	if pipe < nextfd {
+		_, _, err1 = RawSyscall(SYS_DUP3, uintptr(pipe), uintptr(nextfd), O_CLOEXEC)
+		if err1 != 0 {
+			goto childerror
+		}
+		pipe = nextfd
+		nextfd++
+	}
+	for i = 0; i < len(fd); i++ {
+		if fd[i] >= 0 && fd[i] < int(i) {
+			if nextfd == pipe { // 不要覆盖管道
+				nextfd++
+			}
+			_, _, err1 = RawSyscall(SYS_DUP3, uintptr(fd[i]), uintptr(nextfd), O_CLOEXEC)
+			if err1 != 0 {
+				goto childerror
+			}
+			fd[i] = nextfd
+			nextfd++
+		}
+	}
+
+	// 第2步：将fd[i]复制到i。
+	for i = 0; i < len(fd); i++ {
+		if fd[i] == -1 {
+			RawSyscall(SYS_CLOSE, uintptr(i), 0, 0)
+			continue
+		}
+		if fd[i] == int(i) {
+			// 在Linux上dup2(i, i)不会清除close-on-exec标志。
+			_, _, err1 = RawSyscall(SYS_FCNTL, uintptr(fd[i]), F_SETFD, 0)
+			if err1 != 0 {
+				goto childerror
+			}
+			continue
+		}
+		// 新的fd没有设置close-on-exec，这正是我们想要的。
+		_, _, err1 = RawSyscall(SYS_DUP3, uintptr(fd[i]), uintptr(i), 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 按照惯例，我们不会为启动时拥有的fd设置close-on-exec，
+	// 因此如果len(fd) < 3，则根据需要关闭0、1、2。
+	// 知道自己会继承>= 3的fd的程序需要自行设置close-on-exec。
+	for i = len(fd); i < 3; i++ {
+		RawSyscall(SYS_CLOSE, uintptr(i), 0, 0)
+	}
+
+	// 将fd 0从tty分离
+	if sys.Noctty {
+		_, _, err1 = RawSyscall(SYS_IOCTL, 0, uintptr(TIOCNOTTY), 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 将控制TTY设置为Ctty
+	if sys.Setctty {
+		_, _, err1 = RawSyscall(SYS_IOCTL, uintptr(sys.Ctty), uintptr(TIOCSCTTY), 1)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 如果需要，启用跟踪。
+	// 在exec之前才这样做，以免不必要地跟踪fork后运行时的设置过程。
+	if sys.Ptrace {
+		_, _, err1 = RawSyscall(SYS_PTRACE, _PTRACE_TRACEME, 0, 0)
+		if err1 != 0 {
+			goto childerror
+		}
+	}
+
+	// 执行exec。
+	_, _, err1 = RawSyscall(SYS_EXECVE,
+		uintptr(unsafe.Pointer(argv0)),
+		uintptr(unsafe.Pointer(&argv[0])),
+		uintptr(unsafe.Pointer(&envv[0])))
+
+childerror:
+	// 在管道上发送错误码
+	RawSyscall(SYS_WRITE, uintptr(pipe), uintptr(unsafe.Pointer(&err1)), unsafe.Sizeof(err1))
+	for {
+		RawSyscall(SYS_EXIT, 253, 0, 0)
+	}
+}
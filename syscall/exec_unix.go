@@ -0,0 +1,234 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build linux
+
+// Fork、exec、wait等
+
+package syscall
+
+import (
+	"internal/bytealg"
+	"sync"
+	"unsafe"
+)
+
+// ForkLock 用于同步新文件描述符的创建与fork。
+//
+// 我们希望子进程只继承我们明确指定的文件描述符，而不是其他goroutine碰巧打开的文件描述符。
+// 因此，所有新创建的文件描述符都应该设置close-on-exec标志，这样它们就不会被子进程继承。
+// 然而，open、pipe和socket等系统调用创建的描述符默认不设置该标志，
+// 在调用创建描述符与设置close-on-exec标志之间存在一个窗口，此时fork会泄露该描述符。
+//
+// 为了避免这种情况，创建新文件描述符并设置close-on-exec标志的代码要持有ForkLock的读锁，
+// 而forkExec在fork期间持有写锁。能够原子地设置close-on-exec标志的调用
+// （例如带O_CLOEXEC的open或pipe2）不需要持有该锁。
+var ForkLock sync.RWMutex
+
+// StringSlicePtr 将字符串切片转换为指向以NUL结尾的字节数组的指针切片。
+// 如果任何字符串包含NUL字节，则此函数将panic而不是返回错误。
+//
+// 不推荐使用：改用SlicePtrFromStrings。
+func StringSlicePtr(ss []string) []*byte {
+	bb := make([]*byte, len(ss)+1)
+	for i := 0; i < len(ss); i++ {
+		bb[i] = StringBytePtr(ss[i])
+	}
+	bb[len(ss)] = nil
+	return bb
+}
+
+// SlicePtrFromStrings 将字符串切片转换为指向以NUL结尾的字节数组的指针切片。
+// 如果任何字符串包含NUL字节，则返回(nil, EINVAL)。
+func SlicePtrFromStrings(ss []string) ([]*byte, error) {
+	n := 0
+	for _, s := range ss {
+		if bytealg.IndexByteString(s, 0) != -1 {
+			return nil, EINVAL
+		}
+		n += len(s) + 1 // +1表示NUL
+	}
+	bb := make([]*byte, len(ss)+1)
+	b := make([]byte, n)
+	n = 0
+	for i, s := range ss {
+		bb[i] = &b[n]
+		copy(b[n:], s)
+		n += len(s) + 1
+	}
+	return bb, nil
+}
+
+func CloseOnExec(fd int) { fcntl(fd, F_SETFD, FD_CLOEXEC) }
+
+func SetNonblock(fd int, nonblocking bool) (err error) {
+	flag, err := fcntl(fd, F_GETFL, 0)
+	if err != nil {
+		return err
+	}
+	if nonblocking {
+		flag |= O_NONBLOCK
+	} else {
+		flag &^= O_NONBLOCK
+	}
+	_, err = fcntl(fd, F_SETFL, flag)
+	return err
+}
+
+// Credential 保存由StartProcess启动的子进程要使用的用户和组身份。
+type Credential struct {
+	Uid         uint32   // 用户ID。
+	Gid         uint32   // 组ID。
+	Groups      []uint32 // 附加组ID。
+	NoSetGroups bool     // 如果为true，则不设置附加组
+}
+
+// ProcAttr 保存将应用于由StartProcess启动的新进程的属性。
+type ProcAttr struct {
+	Dir   string    // 当前工作目录。
+	Env   []string  // 环境。
+	Files []uintptr // 文件描述符。
+	Sys   *SysProcAttr
+}
+
+var zeroProcAttr ProcAttr
+var zeroSysProcAttr SysProcAttr
+
+func forkExec(argv0 string, argv []string, attr *ProcAttr) (pid int, err error) {
+	var p [2]int
+	var n int
+	var err1 Errno
+	var wstatus WaitStatus
+
+	if attr == nil {
+		attr = &zeroProcAttr
+	}
+	sys := attr.Sys
+	if sys == nil {
+		sys = &zeroSysProcAttr
+	}
+
+	p[0] = -1
+	p[1] = -1
+
+	// 将参数转换为C的形式。
+	argv0p, err := BytePtrFromString(argv0)
+	if err != nil {
+		return 0, err
+	}
+	argvp, err := SlicePtrFromStrings(argv)
+	if err != nil {
+		return 0, err
+	}
+	envvp, err := SlicePtrFromStrings(attr.Env)
+	if err != nil {
+		return 0, err
+	}
+
+	var chroot *byte
+	if sys.Chroot != "" {
+		chroot, err = BytePtrFromString(sys.Chroot)
+		if err != nil {
+			return 0, err
+		}
+	}
+	var dir *byte
+	if attr.Dir != "" {
+		dir, err = BytePtrFromString(attr.Dir)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// 获取fork锁，这样在fork之前，其他线程就不会创建尚未设置close-on-exec的新文件描述符。
+	ForkLock.Lock()
+
+	// 分配设置了close-on-exec的子进程状态管道。
+	if err = Pipe2(p[:], O_CLOEXEC); err != nil {
+		goto error
+	}
+
+	// 启动子进程。
+	pid, err1 = forkAndExecInChild(argv0p, argvp, envvp, chroot, dir, attr, sys, p[1])
+	if err1 != 0 {
+		err = Errno(err1)
+		goto error
+	}
+	ForkLock.Unlock()
+
+	// 从管道中读取子进程的错误状态。
+	Close(p[1])
+	for {
+		n, err = readlen(p[0], (*byte)(unsafe.Pointer(&err1)), int(unsafe.Sizeof(err1)))
+		if err != EINTR {
+			break
+		}
+	}
+	Close(p[0])
+	if err != nil || n != 0 {
+		if n == int(unsafe.Sizeof(err1)) {
+			err = Errno(err1)
+		}
+		if err == nil {
+			err = EPIPE
+		}
+
+		// 子进程失败；等待它退出，以确保不会累积僵尸进程。
+		_, err1 := Wait4(pid, &wstatus, 0, nil)
+		for err1 == EINTR {
+			_, err1 = Wait4(pid, &wstatus, 0, nil)
+		}
+		return 0, err
+	}
+
+	// 读取到EOF，说明管道在exec时被关闭，因此exec成功。
+	return pid, nil
+
+error:
+	if p[0] >= 0 {
+		Close(p[0])
+		Close(p[1])
+	}
+	ForkLock.Unlock()
+	return 0, err
+}
+
+// ForkExec 是fork和exec的组合，注意线程安全。
+func ForkExec(argv0 string, argv []string, attr *ProcAttr) (pid int, err error) {
+	return forkExec(argv0, argv, attr)
+}
+
+// StartProcess 为os包包装ForkExec。
+func StartProcess(argv0 string, argv []string, attr *ProcAttr) (pid int, handle uintptr, err error) {
+	pid, err = forkExec(argv0, argv, attr)
+	return pid, 0, err
+}
+
+// 在runtime包中实现。
+func runtime_BeforeExec()
+func runtime_AfterExec()
+
+// Exec 调用execve(2)系统调用。
+func Exec(argv0 string, argv []string, envv []string) (err error) {
+	argv0p, err := BytePtrFromString(argv0)
+	if err != nil {
+		return err
+	}
+	argvp, err := SlicePtrFromStrings(argv)
+	if err != nil {
+		return err
+	}
+	envvp, err := SlicePtrFromStrings(envv)
+	if err != nil {
+		return err
+	}
+	runtime_BeforeExec()
+
+	_, _, err1 := RawSyscall(SYS_EXECVE,
+		uintptr(unsafe.Pointer(argv0p)),
+		uintptr(unsafe.Pointer(&argvp[0])),
+		uintptr(unsafe.Pointer(&envvp[0])))
+	runtime_AfterExec()
+	return err1
+}
@@ -0,0 +1,56 @@
+#!/usr/bin/env bash
+# 版权所有2020 The Go Authors。 版权所有。
+# 此源代码的使用受BSD样式的约束
+# 可以在LICENSE文件中找到的许可证。
+
+# mkall.sh 为$GOARCH（默认为amd64）重新生成Linux的z*_linux_$GOARCH.go文件：
+#
+#	zerrors_linux_$GOARCH.go   常量、错误号和信号，由mkerrors.go从C头文件提取
+#	zsysnum_linux_$GOARCH.go   系统调用号，由mksysnum.go从内核头文件提取
+#	ztypes_linux_$GOARCH.go    结构体和类型，由cgo -godefs从types_linux.go生成
+#	zsyscall_linux_$GOARCH.go  系统调用存根，由mksyscall.go根据//sys注释生成
+#
+# 除了zsyscall，其他文件都依赖目标架构的C头文件和类型布局，
+# 因此必须在目标架构上运行，或者将$CC设置为目标架构的交叉编译器。
+#
+# 用法：
+#
+#	GOARCH=arm64 ./mkall.sh      # 重新生成
+#	GOARCH=arm64 ./mkall.sh -n   # 只打印将要执行的命令
+
+set -e
+
+GOARCH=${GOARCH:-amd64}
+run=sh
+if [ "$1" = "-n" ]; then
+	run=cat
+fi
+
+# 生成器本身总是为主机构建和运行。
+gorun="env -u GOARCH go run"
+
+case "$GOARCH" in
+amd64)
+	sysnum="-arch amd64 /usr/include/x86_64-linux-gnu/asm/unistd_64.h"
+	godefs="go tool cgo -godefs"
+	;;
+arm64)
+	# arm64使用asm-generic/unistd.h中的通用系统调用表，需要打开与arch/arm64/include/uapi/asm/unistd.h相同的宏。
+	sysnum="-arch arm64 -D __BITS_PER_LONG=64 -D __ARCH_WANT_RENAMEAT -D __ARCH_WANT_NEW_STAT"
+	sysnum="$sysnum -D __ARCH_WANT_SET_GET_RLIMIT -D __ARCH_WANT_TIME32_SYSCALLS -D __ARCH_WANT_SYS_CLONE3"
+	sysnum="$sysnum -D __ARCH_WANT_MEMFD_SECRET /usr/include/asm-generic/unistd.h"
+	godefs="go tool cgo -godefs -- -fsigned-char"
+	;;
+*)
+	echo "mkall.sh: unsupported GOARCH $GOARCH" 1>&2
+	exit 1
+	;;
+esac
+
+(
+	echo "$gorun mkerrors.go -arch $GOARCH > zerrors_linux_$GOARCH.go"
+	echo "$gorun mksysnum.go $sysnum > zsysnum_linux_$GOARCH.go"
+	echo "{ printf '// cgo -godefs types_linux.go\n// 由上面的命令生成的代码； 请勿编辑。\n\n// +build $GOARCH,linux\n'; $godefs types_linux.go | sed 1,2d; } | gofmt > ztypes_linux_$GOARCH.go"
+	echo "rm -rf _obj"
+	echo "$gorun mksyscall.go -tags linux,$GOARCH syscall_linux.go syscall_linux_$GOARCH.go > zsyscall_linux_$GOARCH.go"
+) | $run
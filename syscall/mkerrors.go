@@ -0,0 +1,274 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build ignore
+
+// mkerrors 从C头文件中提取错误号、信号以及文件、进程、套接字和epoll相关的常量，
+// 生成zerrors_linux_$GOARCH.go。
+//
+// 用法：
+//
+//	go run mkerrors.go -arch $GOARCH > zerrors_linux_$GOARCH.go
+//
+// 常量的名字来自C预处理器（$CC，默认为cc）的-dM输出，值和错误信息由一个临时编译的C程序打印，
+// 因此必须在目标架构上（或使用目标架构的交叉编译器作为$CC）运行。
+// 错误信息和信号描述来自strerror(3)和strsignal(3)，首字母改为小写。
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var arch = flag.String("arch", "", "target `GOARCH`")
+
+// includes 是提取常量时包含的头文件。
+const includes = `
+#define _GNU_SOURCE
+#include <sys/types.h>
+#include <sys/epoll.h>
+#include <sys/file.h>
+#include <sys/inotify.h>
+#include <sys/ioctl.h>
+#include <sys/mman.h>
+#include <sys/mount.h>
+#include <sys/prctl.h>
+#include <sys/resource.h>
+#include <sys/socket.h>
+#include <sys/stat.h>
+#include <sys/un.h>
+#include <sys/wait.h>
+#include <dirent.h>
+#include <errno.h>
+#include <fcntl.h>
+#include <sched.h>
+#include <signal.h>
+#include <string.h>
+#include <stdio.h>
+#include <netinet/in.h>
+#include <netinet/tcp.h>
+`
+
+var (
+	// reConst 匹配要导出的常量名。
+	reConst = regexp.MustCompile(`^((AF|AT|CLONE|DT|F|FD|IN|IPPROTO|IP|IPV6|LOCK|MADV|MAP|MCL|MSG|MS|O|PRIO|PROT|PR|RLIMIT|RUSAGE|SCM|SHUT|SOCK|SOL|SO|SPLICE_F|S|TCP)_[A-Z0-9_]+|(EPOLL|TIOC)[A-Z0-9_]+|SOMAXCONN|W(NOHANG|UNTRACED|EXITED|STOPPED|CONTINUED|NOWAIT|ALL|CLONE|NOTHREAD))$`)
+	reErrno = regexp.MustCompile(`^E[A-Z0-9]+$`)
+	reSig   = regexp.MustCompile(`^SIG[A-Z0-9]+$`)
+
+	// exclude 是匹配上面的模式但不是整数常量或不应导出的名字。
+	exclude = map[string]bool{
+		"MAP_FAILED": true,
+		"SIGRTMIN":   true,
+		"SIGRTMAX":   true,
+		"SIGSTKSZ":   true,
+	}
+)
+
+func main() {
+	flag.Parse()
+	if *arch == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run mkerrors.go -arch goarch")
+		os.Exit(2)
+	}
+
+	dir, err := ioutil.TempDir("", "mkerrors")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	// 错误号和信号只取自errno.h和signal.h，以免混入stdio.h中的EOF这样的宏。
+	errnoNames := nameSet(macros(dir, "#include <errno.h>\n"))
+	sigNames := nameSet(macros(dir, "#include <signal.h>\n"))
+
+	var consts, errnos, sigs []string
+	for _, name := range macros(dir, includes) {
+		switch {
+		case exclude[name]:
+		case reErrno.MatchString(name) && errnoNames[name]:
+			errnos = append(errnos, name)
+		case reSig.MatchString(name) && sigNames[name]:
+			sigs = append(sigs, name)
+		case reConst.MatchString(name):
+			consts = append(consts, name)
+		}
+	}
+
+	values, errstr, sigstr := evaluate(dir, consts, errnos, sigs)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// go run mkerrors.go -arch %s\n", *arch)
+	fmt.Fprintf(&buf, "// 由上面的命令生成的代码； 请勿编辑。\n\n")
+	fmt.Fprintf(&buf, "// +build %s,linux\n\n", *arch)
+	fmt.Fprintf(&buf, "package syscall\n\n")
+	fmt.Fprintf(&buf, "const (\n")
+	for _, name := range consts {
+		if v, ok := values[name]; ok {
+			fmt.Fprintf(&buf, "\t%s = %s\n", name, v)
+		}
+	}
+	fmt.Fprintf(&buf, ")\n\n// 错误\nconst (\n")
+	for _, name := range errnos {
+		if v, ok := values[name]; ok {
+			fmt.Fprintf(&buf, "\t%s = Errno(%s)\n", name, v)
+		}
+	}
+	fmt.Fprintf(&buf, ")\n\n// 信号\nconst (\n")
+	for _, name := range sigs {
+		if v, ok := values[name]; ok {
+			fmt.Fprintf(&buf, "\t%s = Signal(%s)\n", name, v)
+		}
+	}
+	fmt.Fprintf(&buf, ")\n\n// 错误表\nvar errors = [...]string{\n")
+	writeTable(&buf, errstr)
+	fmt.Fprintf(&buf, "}\n\n// 信号表\nvar signals = [...]string{\n")
+	writeTable(&buf, sigstr)
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	check(err)
+	os.Stdout.Write(src)
+}
+
+// macros 返回C源代码src定义的所有类对象宏的名字，按字母顺序排列。
+func macros(dir, src string) []string {
+	hdr := filepath.Join(dir, "macros.h")
+	check(ioutil.WriteFile(hdr, []byte(src), 0666))
+	out, err := exec.Command(cc(), "-E", "-dM", hdr).Output()
+	check(err)
+
+	var names []string
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		f := strings.Fields(s.Text())
+		if len(f) < 3 || f[0] != "#define" || strings.Contains(f[1], "(") {
+			continue
+		}
+		names = append(names, f[1])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func nameSet(names []string) map[string]bool {
+	m := make(map[string]bool)
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// evaluate 编译并运行一个C程序，得到常量的值以及错误号和信号的描述。
+// 无法作为整数求值的宏会被丢弃。
+func evaluate(dir string, consts, errnos, sigs []string) (values map[string]string, errstr, sigstr map[int]string) {
+	all := append(append(append([]string(nil), consts...), errnos...), sigs...)
+	src := filepath.Join(dir, "consts.c")
+	bin := filepath.Join(dir, "consts")
+	reLine := regexp.MustCompile(`consts\.c:(\d+):\d+: error`)
+	for {
+		var prog bytes.Buffer
+		prog.WriteString(includes)
+		prog.WriteString("\nstatic void pr(const char *name, long long v) {\n" +
+			"\tif (v < 0) printf(\"const %s -0x%llx\\n\", name, -(unsigned long long)v);\n" +
+			"\telse printf(\"const %s 0x%llx\\n\", name, (unsigned long long)v);\n}\n")
+		prog.WriteString("\nint main(void) {\n")
+		first := strings.Count(prog.String(), "\n") + 1
+		for _, name := range all {
+			fmt.Fprintf(&prog, "\tpr(%q, (long long)(%s));\n", name, name)
+		}
+		prog.WriteString("\tfor (int i = 1; i < 4096; i++) {\n" +
+			"\t\tchar buf[32]; snprintf(buf, sizeof buf, \"Unknown error %d\", i);\n" +
+			"\t\tif (strcmp(strerror(i), buf) != 0) printf(\"errstr %d %s\\n\", i, strerror(i));\n\t}\n")
+		prog.WriteString("\tfor (int i = 1; i < 32; i++) printf(\"sigstr %d %s\\n\", i, strsignal(i));\n")
+		prog.WriteString("\treturn 0;\n}\n")
+		check(ioutil.WriteFile(src, prog.Bytes(), 0666))
+
+		out, err := exec.Command(cc(), "-w", "-o", bin, src).CombinedOutput()
+		if err == nil {
+			break
+		}
+		// 删除导致编译错误的宏，然后重试。
+		bad := make(map[int]bool)
+		for _, m := range reLine.FindAllStringSubmatch(string(out), -1) {
+			n, _ := strconv.Atoi(m[1])
+			bad[n-first] = true
+		}
+		if len(bad) == 0 {
+			fmt.Fprintf(os.Stderr, "mkerrors: %s\n", out)
+			os.Exit(1)
+		}
+		var keep []string
+		for i, name := range all {
+			if !bad[i] {
+				keep = append(keep, name)
+			}
+		}
+		all = keep
+	}
+
+	out, err := exec.Command(bin).Output()
+	check(err)
+	values = make(map[string]string)
+	errstr = make(map[int]string)
+	sigstr = make(map[int]string)
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		f := strings.SplitN(s.Text(), " ", 3)
+		switch f[0] {
+		case "const":
+			values[f[1]] = f[2]
+		case "errstr", "sigstr":
+			n, _ := strconv.Atoi(f[1])
+			msg := lowerFirst(f[2])
+			if f[0] == "errstr" {
+				errstr[n] = msg
+			} else {
+				sigstr[n] = msg
+			}
+		}
+	}
+	return values, errstr, sigstr
+}
+
+// lowerFirst 将"Bad address"变为"bad address"，但保留"STREAMS"这样的缩写。
+func lowerFirst(s string) string {
+	if len(s) > 1 && 'A' <= s[0] && s[0] <= 'Z' && 'a' <= s[1] && s[1] <= 'z' {
+		return string(s[0]+'a'-'A') + s[1:]
+	}
+	return s
+}
+
+func writeTable(buf *bytes.Buffer, table map[int]string) {
+	var nums []int
+	for n := range table {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+	for _, n := range nums {
+		fmt.Fprintf(buf, "\t%d: %q,\n", n, table[n])
+	}
+}
+
+func cc() string {
+	if cc := os.Getenv("CC"); cc != "" {
+		return cc
+	}
+	return "cc"
+}
+
+func check(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mkerrors: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,222 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build ignore
+
+// mksyscall 根据输入文件中的//sys和//sysnb注释生成系统调用的Go存根。
+//
+// 用法：
+//
+//	go run mksyscall.go -tags linux,$GOARCH syscall_linux.go syscall_linux_$GOARCH.go > zsyscall_linux_$GOARCH.go
+//
+// 注释的形式为
+//
+//	//sys	名字(参数列表) (结果列表) = SYS_调用号
+//
+// 结果列表和"= SYS_调用号"都是可选的，省略调用号时使用SYS_加上大写的函数名，
+// 驼峰形式的单词之间以下划线分隔，例如EpollCreate1对应SYS_EPOLL_CREATE1。
+// //sys生成的存根使用Syscall或Syscall6，会通知运行时可能阻塞；//sysnb生成的存根使用RawSyscall或RawSyscall6。
+// 名为err的error类型结果接收系统调用返回的errno，其他结果依次取自第一和第二个返回值。
+// string参数转换为以NUL结尾的字节数组，切片参数展开为指针和长度两个参数。
+// 仅支持64位架构，每个参数正好占用一个寄存器。
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var tags = flag.String("tags", "", "build `tags` for the generated file")
+
+var (
+	reSys   = regexp.MustCompile(`^//sys(nb)?\t+(\w+)\(([^()]*)\)\s*(?:\(([^()]+)\))?\s*(?:=\s*(SYS_\w+))?$`)
+	reParam = regexp.MustCompile(`^(\w+) (.+)$`)
+	reCamel = regexp.MustCompile(`([a-z])([A-Z])`)
+)
+
+type param struct {
+	name, typ string
+}
+
+func parseParams(list string) []param {
+	var ps []param
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	for _, p := range strings.Split(list, ",") {
+		m := reParam.FindStringSubmatch(strings.TrimSpace(p))
+		if m == nil {
+			fatalf("malformed parameter %q", p)
+		}
+		ps = append(ps, param{m[1], m[2]})
+	}
+	return ps
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 || *tags == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run mksyscall.go -tags os,arch file...")
+		os.Exit(2)
+	}
+
+	var text bytes.Buffer
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			line := s.Text()
+			if !strings.HasPrefix(line, "//sys") {
+				continue
+			}
+			m := reSys.FindStringSubmatch(line)
+			if m == nil {
+				fatalf("%s: malformed //sys declaration: %s", path, line)
+			}
+			genStub(&text, m[1] == "nb", m[2], parseParams(m[3]), parseParams(m[4]), m[5])
+		}
+		if err := s.Err(); err != nil {
+			fatalf("%s: %v", path, err)
+		}
+		f.Close()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// go run mksyscall.go -tags %s %s\n", *tags, strings.Join(flag.Args(), " "))
+	fmt.Fprintf(&buf, "// 由上面的命令生成的代码； 请勿编辑。\n\n")
+	fmt.Fprintf(&buf, "// +build %s\n\n", *tags)
+	fmt.Fprintf(&buf, "package syscall\n\nimport \"unsafe\"\n\n")
+	fmt.Fprintf(&buf, "var _ unsafe.Pointer\n\n")
+	buf.Write(text.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fatalf("%v", err)
+	}
+	os.Stdout.Write(src)
+}
+
+// genStub 生成一个系统调用存根。
+func genStub(w *bytes.Buffer, nonblock bool, name string, in, out []param, trap string) {
+	if trap == "" {
+		trap = "SYS_" + strings.ToUpper(reCamel.ReplaceAllString(name, "${1}_$2"))
+	}
+
+	var decl []string
+	for _, p := range in {
+		decl = append(decl, p.name+" "+p.typ)
+	}
+	var ret []string
+	for _, p := range out {
+		ret = append(ret, p.name+" "+p.typ)
+	}
+	fmt.Fprintf(w, "func %s(%s) ", name, strings.Join(decl, ", "))
+	if len(ret) > 0 {
+		fmt.Fprintf(w, "(%s) ", strings.Join(ret, ", "))
+	}
+	fmt.Fprintf(w, "{\n")
+
+	hasErr := len(out) > 0 && out[len(out)-1].name == "err" && out[len(out)-1].typ == "error"
+
+	// 准备参数。
+	var args []string
+	n := 0
+	for _, p := range in {
+		switch {
+		case p.typ == "string":
+			if !hasErr {
+				fatalf("%s: string argument %s requires an error result", name, p.name)
+			}
+			fmt.Fprintf(w, "\tvar _p%d *byte\n", n)
+			fmt.Fprintf(w, "\t_p%d, err = BytePtrFromString(%s)\n", n, p.name)
+			fmt.Fprintf(w, "\tif err != nil {\n\t\treturn\n\t}\n")
+			args = append(args, fmt.Sprintf("uintptr(unsafe.Pointer(_p%d))", n))
+			n++
+		case strings.HasPrefix(p.typ, "[]"):
+			fmt.Fprintf(w, "\tvar _p%d unsafe.Pointer\n", n)
+			fmt.Fprintf(w, "\tif len(%s) > 0 {\n\t\t_p%d = unsafe.Pointer(&%s[0])\n", p.name, n, p.name)
+			fmt.Fprintf(w, "\t} else {\n\t\t_p%d = unsafe.Pointer(&_zero)\n\t}\n", n)
+			args = append(args, fmt.Sprintf("uintptr(_p%d)", n), fmt.Sprintf("uintptr(len(%s))", p.name))
+			n++
+		case strings.HasPrefix(p.typ, "*"):
+			args = append(args, fmt.Sprintf("uintptr(unsafe.Pointer(%s))", p.name))
+		case p.typ == "bool":
+			fmt.Fprintf(w, "\tvar _p%d uint32\n", n)
+			fmt.Fprintf(w, "\tif %s {\n\t\t_p%d = 1\n\t}\n", p.name, n)
+			args = append(args, fmt.Sprintf("uintptr(_p%d)", n))
+			n++
+		default:
+			args = append(args, fmt.Sprintf("uintptr(%s)", p.name))
+		}
+	}
+
+	var fn string
+	switch {
+	case len(args) <= 3:
+		fn = "Syscall"
+		for len(args) < 3 {
+			args = append(args, "0")
+		}
+	case len(args) <= 6:
+		fn = "Syscall6"
+		for len(args) < 6 {
+			args = append(args, "0")
+		}
+	default:
+		fatalf("%s: too many arguments to system call", name)
+	}
+	if nonblock {
+		fn = "Raw" + fn
+	}
+
+	// 分配结果。
+	regs := []string{"_", "_", "_"}
+	var assign []string
+	i := 0
+	for _, p := range out {
+		if p.name == "err" && p.typ == "error" {
+			regs[2] = "e1"
+			continue
+		}
+		if i >= 2 {
+			fatalf("%s: too many results", name)
+		}
+		reg := fmt.Sprintf("r%d", i)
+		regs[i] = reg
+		if p.typ == "bool" {
+			assign = append(assign, fmt.Sprintf("\t%s = %s != 0\n", p.name, reg))
+		} else {
+			assign = append(assign, fmt.Sprintf("\t%s = %s(%s)\n", p.name, p.typ, reg))
+		}
+		i++
+	}
+
+	call := fmt.Sprintf("%s(%s, %s)", fn, trap, strings.Join(args, ", "))
+	if regs[0] == "_" && regs[1] == "_" && regs[2] == "_" {
+		fmt.Fprintf(w, "\t%s\n", call)
+	} else {
+		fmt.Fprintf(w, "\t%s := %s\n", strings.Join(regs, ", "), call)
+	}
+	for _, a := range assign {
+		w.WriteString(a)
+	}
+	if hasErr {
+		fmt.Fprintf(w, "\tif e1 != 0 {\n\t\terr = errnoErr(e1)\n\t}\n")
+	}
+	fmt.Fprintf(w, "\treturn\n}\n\n")
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "mksyscall: "+format+"\n", args...)
+	os.Exit(1)
+}
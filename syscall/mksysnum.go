@@ -0,0 +1,109 @@
+// 版权所有2020 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// +build ignore
+
+// mksysnum 从Linux内核头文件中提取系统调用号，生成zsysnum_linux_$GOARCH.go。
+//
+// 用法：
+//
+//	go run mksysnum.go -arch $GOARCH [-D 宏 ...] 头文件 > zsysnum_linux_$GOARCH.go
+//
+// 头文件先经过C预处理器（$CC，默认为cc）展开，因此-D可以用来打开架构相关的条件编译，
+// 例如arm64使用的asm-generic/unistd.h中的__ARCH_WANT_RENAMEAT。
+// 同时收集__NR_xxx与__NR3264_xxx，后者在64位系统上就是xxx的调用号。
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	arch    = flag.String("arch", "", "target `GOARCH`")
+	defines multiFlag
+)
+
+type multiFlag []string
+
+func (m *multiFlag) String() string     { return strings.Join(*m, " ") }
+func (m *multiFlag) Set(v string) error { *m = append(*m, v); return nil }
+
+var reDefine = regexp.MustCompile(`^#define\s+__NR(3264)?_(\w+)\s+([0-9]+)\s*$`)
+
+func main() {
+	flag.Var(&defines, "D", "define `macro` for the C preprocessor")
+	flag.Parse()
+	if flag.NArg() != 1 || *arch == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run mksysnum.go -arch goarch [-D macro]... header")
+		os.Exit(2)
+	}
+	header := flag.Arg(0)
+
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "cc"
+	}
+	args := []string{"-E", "-dD", "-P"}
+	for _, d := range defines {
+		args = append(args, "-D"+d)
+	}
+	args = append(args, header)
+	out, err := exec.Command(cc, args...).Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mksysnum: %s %s: %v\n", cc, strings.Join(args, " "), err)
+		os.Exit(1)
+	}
+
+	type sysnum struct {
+		name string
+		num  int
+	}
+	seen := make(map[string]bool)
+	var nums []sysnum
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		m := reDefine.FindStringSubmatch(s.Text())
+		if m == nil || m[2] == "syscalls" || m[2] == "arch_specific_syscall" {
+			continue
+		}
+		name := "SYS_" + strings.ToUpper(m[2])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		n, _ := strconv.Atoi(m[3])
+		nums = append(nums, sysnum{name, n})
+	}
+	sort.SliceStable(nums, func(i, j int) bool { return nums[i].num < nums[j].num })
+
+	cmd := "mksysnum.go -arch " + *arch
+	for _, d := range defines {
+		cmd += " -D " + d
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// go run %s %s\n", cmd, header)
+	fmt.Fprintf(&buf, "// 由上面的命令生成的代码； 请勿编辑。\n\n")
+	fmt.Fprintf(&buf, "// +build %s,linux\n\n", *arch)
+	fmt.Fprintf(&buf, "package syscall\n\nconst (\n")
+	for _, n := range nums {
+		fmt.Fprintf(&buf, "\t%s = %d\n", n.name, n.num)
+	}
+	fmt.Fprintf(&buf, ")\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mksysnum: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(src)
+}
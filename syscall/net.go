@@ -0,0 +1,26 @@
+// 版权所有2017 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+package syscall
+
+// RawConn 是原始网络连接。
+type RawConn interface {
+	// Control 在底层连接描述符上调用f，保证在f执行期间描述符有效。
+	// 不得在f之外使用该文件描述符。
+	Control(f func(fd uintptr)) error
+
+	// Read 在底层连接描述符上调用f，f应该尝试读取操作。
+	// 如果f返回true，Read返回；否则Read阻塞等待连接准备好读取，然后再次尝试。
+	// 在f执行期间，文件描述符保证有效。不得在f之外使用该文件描述符。
+	Read(f func(fd uintptr) (done bool)) error
+
+	// Write 与Read类似，但用于写入。
+	Write(f func(fd uintptr) (done bool)) error
+}
+
+// Conn 由某些类型在net和os包中实现，以提供对底层文件描述符或句柄的访问。
+type Conn interface {
+	// SyscallConn 返回原始网络连接。
+	SyscallConn() (RawConn, error)
+}
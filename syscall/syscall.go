@@ -0,0 +1,88 @@
+// 版权所有2009 The Go Authors。 版权所有。
+// 此源代码的使用受BSD样式的约束
+// 可以在LICENSE文件中找到的许可证。
+
+// Package syscall 包含到底层操作系统原语的接口。
+// 细节取决于底层系统，默认情况下，godoc将显示当前系统的系统调用文档。
+// 如果要让godoc显示另一个系统的系统调用文档，请将$GOOS和$GOARCH设置为所需的系统。
+// 例如，如果要在linux/amd64上查看freebsd/arm的文档，请将$GOOS设置为freebsd，将$GOARCH设置为arm。
+// syscall的主要用途是在其他为系统提供更便携接口的软件包中，例如"os"、"time"和"net"。
+// 如果可以，请使用这些软件包，而不要使用此软件包。
+// 有关此程序包中的函数和数据类型的详细信息，请参阅相应操作系统的手册。
+// 这些调用返回err == nil表示成功；否则err是描述失败的操作系统错误。
+// 在大多数系统上，该错误的类型为syscall.Errno。
+//
+// Linux上的zerrors_linux_$GOARCH.go、zsysnum_linux_$GOARCH.go、ztypes_linux_$GOARCH.go
+// 和zsyscall_linux_$GOARCH.go由mkall.sh调用mkerrors.go、mksysnum.go、cgo -godefs和mksyscall.go生成。
+package syscall
+
+import "internal/bytealg"
+
+//go:generate go run golang.org/x/sys/windows/mkwinsyscall -systemdll -output zsyscall_windows.go syscall_windows.go
+
+// StringByteSlice 将字符串转换为以NUL结尾的[]byte，如果s包含NUL字节，则此函数将panic而不是返回错误。
+//
+// 不推荐使用：改用ByteSliceFromString。
+func StringByteSlice(s string) []byte {
+	a, err := ByteSliceFromString(s)
+	if err != nil {
+		panic("syscall: string with NUL passed to StringByteSlice")
+	}
+	return a
+}
+
+// ByteSliceFromString 返回一个以NUL结尾的字节切片，其中包含字符串s的文本。
+// 如果s在任何位置包含NUL字节，则返回(nil, EINVAL)。
+func ByteSliceFromString(s string) ([]byte, error) {
+	if bytealg.IndexByteString(s, 0) != -1 {
+		return nil, EINVAL
+	}
+	a := make([]byte, len(s)+1)
+	copy(a, s)
+	return a, nil
+}
+
+// StringBytePtr 返回一个指向以NUL结尾的字节数组的指针。
+// 如果s包含NUL字节，则此函数将panic而不是返回错误。
+//
+// 不推荐使用：改用BytePtrFromString。
+func StringBytePtr(s string) *byte { return &StringByteSlice(s)[0] }
+
+// BytePtrFromString 返回一个指向以NUL结尾的字节数组的指针，该数组包含字符串s的文本。
+// 如果s在任何位置包含NUL字节，则返回(nil, EINVAL)。
+func BytePtrFromString(s string) (*byte, error) {
+	a, err := ByteSliceFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return &a[0], nil
+}
+
+// 需要指向0字节的有效指针时使用的单字零值。
+// 参见mksyscall.go。
+var _zero uintptr
+
+// Unix 返回ts表示的自1970年1月1日UTC以来经过的秒数和纳秒数。
+func (ts *Timespec) Unix() (sec int64, nsec int64) {
+	return int64(ts.Sec), int64(ts.Nsec)
+}
+
+// Unix 返回tv表示的自1970年1月1日UTC以来经过的秒数和纳秒数。
+func (tv *Timeval) Unix() (sec int64, nsec int64) {
+	return int64(tv.Sec), int64(tv.Usec) * 1000
+}
+
+// Nano 返回ts表示的自1970年1月1日UTC以来经过的纳秒数。
+func (ts *Timespec) Nano() int64 {
+	return int64(ts.Sec)*1e9 + int64(ts.Nsec)
+}
+
+// Nano 返回tv表示的自1970年1月1日UTC以来经过的纳秒数。
+func (tv *Timeval) Nano() int64 {
+	return int64(tv.Sec)*1e9 + int64(tv.Usec)*1000
+}
+
+// Getpagesize 和Exit由runtime包提供。
+
+func Getpagesize() int
+func Exit(code int)
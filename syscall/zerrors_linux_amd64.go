@@ -0,0 +1,1326 @@
+// go run mkerrors.go -arch amd64
+// 由上面的命令生成的代码； 请勿编辑。
+
+//go:build amd64 && linux
+// +build amd64,linux
+
+package syscall
+
+const (
+	AF_ALG                            = 0x26
+	AF_APPLETALK                      = 0x5
+	AF_ASH                            = 0x12
+	AF_ATMPVC                         = 0x8
+	AF_ATMSVC                         = 0x14
+	AF_AX25                           = 0x3
+	AF_BLUETOOTH                      = 0x1f
+	AF_BRIDGE                         = 0x7
+	AF_CAIF                           = 0x25
+	AF_CAN                            = 0x1d
+	AF_ECONET                         = 0x13
+	AF_FILE                           = 0x1
+	AF_IB                             = 0x1b
+	AF_IEEE802154                     = 0x24
+	AF_INET                           = 0x2
+	AF_INET6                          = 0xa
+	AF_IPX                            = 0x4
+	AF_IRDA                           = 0x17
+	AF_ISDN                           = 0x22
+	AF_IUCV                           = 0x20
+	AF_KCM                            = 0x29
+	AF_KEY                            = 0xf
+	AF_LLC                            = 0x1a
+	AF_LOCAL                          = 0x1
+	AF_MAX                            = 0x2e
+	AF_MCTP                           = 0x2d
+	AF_MPLS                           = 0x1c
+	AF_NETBEUI                        = 0xd
+	AF_NETLINK                        = 0x10
+	AF_NETROM                         = 0x6
+	AF_NFC                            = 0x27
+	AF_PACKET                         = 0x11
+	AF_PHONET                         = 0x23
+	AF_PPPOX                          = 0x18
+	AF_QIPCRTR                        = 0x2a
+	AF_RDS                            = 0x15
+	AF_ROSE                           = 0xb
+	AF_ROUTE                          = 0x10
+	AF_RXRPC                          = 0x21
+	AF_SECURITY                       = 0xe
+	AF_SMC                            = 0x2b
+	AF_SNA                            = 0x16
+	AF_TIPC                           = 0x1e
+	AF_UNIX                           = 0x1
+	AF_UNSPEC                         = 0x0
+	AF_VSOCK                          = 0x28
+	AF_WANPIPE                        = 0x19
+	AF_X25                            = 0x9
+	AF_XDP                            = 0x2c
+	AT_EACCESS                        = 0x200
+	AT_EMPTY_PATH                     = 0x1000
+	AT_FDCWD                          = -0x64
+	AT_NO_AUTOMOUNT                   = 0x800
+	AT_RECURSIVE                      = 0x8000
+	AT_REMOVEDIR                      = 0x200
+	AT_STATX_DONT_SYNC                = 0x4000
+	AT_STATX_FORCE_SYNC               = 0x2000
+	AT_STATX_SYNC_AS_STAT             = 0x0
+	AT_STATX_SYNC_TYPE                = 0x6000
+	AT_SYMLINK_FOLLOW                 = 0x400
+	AT_SYMLINK_NOFOLLOW               = 0x100
+	CLONE_CHILD_CLEARTID              = 0x200000
+	CLONE_CHILD_SETTID                = 0x1000000
+	CLONE_DETACHED                    = 0x400000
+	CLONE_FILES                       = 0x400
+	CLONE_FS                          = 0x200
+	CLONE_IO                          = 0x80000000
+	CLONE_NEWCGROUP                   = 0x2000000
+	CLONE_NEWIPC                      = 0x8000000
+	CLONE_NEWNET                      = 0x40000000
+	CLONE_NEWNS                       = 0x20000
+	CLONE_NEWPID                      = 0x20000000
+	CLONE_NEWTIME                     = 0x80
+	CLONE_NEWUSER                     = 0x10000000
+	CLONE_NEWUTS                      = 0x4000000
+	CLONE_PARENT                      = 0x8000
+	CLONE_PARENT_SETTID               = 0x100000
+	CLONE_PIDFD                       = 0x1000
+	CLONE_PTRACE                      = 0x2000
+	CLONE_SETTLS                      = 0x80000
+	CLONE_SIGHAND                     = 0x800
+	CLONE_SYSVSEM                     = 0x40000
+	CLONE_THREAD                      = 0x10000
+	CLONE_UNTRACED                    = 0x800000
+	CLONE_VFORK                       = 0x4000
+	CLONE_VM                          = 0x100
+	DT_BLK                            = 0x6
+	DT_CHR                            = 0x2
+	DT_DIR                            = 0x4
+	DT_FIFO                           = 0x1
+	DT_LNK                            = 0xa
+	DT_REG                            = 0x8
+	DT_SOCK                           = 0xc
+	DT_UNKNOWN                        = 0x0
+	DT_WHT                            = 0xe
+	EPOLLERR                          = 0x8
+	EPOLLET                           = 0x80000000
+	EPOLLEXCLUSIVE                    = 0x10000000
+	EPOLLHUP                          = 0x10
+	EPOLLIN                           = 0x1
+	EPOLLMSG                          = 0x400
+	EPOLLONESHOT                      = 0x40000000
+	EPOLLOUT                          = 0x4
+	EPOLLPRI                          = 0x2
+	EPOLLRDBAND                       = 0x80
+	EPOLLRDHUP                        = 0x2000
+	EPOLLRDNORM                       = 0x40
+	EPOLLWAKEUP                       = 0x20000000
+	EPOLLWRBAND                       = 0x200
+	EPOLLWRNORM                       = 0x100
+	EPOLL_CLOEXEC                     = 0x80000
+	EPOLL_CTL_ADD                     = 0x1
+	EPOLL_CTL_DEL                     = 0x2
+	EPOLL_CTL_MOD                     = 0x3
+	FD_CLOEXEC                        = 0x1
+	FD_SETSIZE                        = 0x400
+	F_ADD_SEALS                       = 0x409
+	F_DUPFD                           = 0x0
+	F_DUPFD_CLOEXEC                   = 0x406
+	F_EXLCK                           = 0x4
+	F_GETFD                           = 0x1
+	F_GETFL                           = 0x3
+	F_GETLEASE                        = 0x401
+	F_GETLK                           = 0x5
+	F_GETLK64                         = 0x5
+	F_GETOWN                          = 0x9
+	F_GETOWN_EX                       = 0x10
+	F_GETPIPE_SZ                      = 0x408
+	F_GETSIG                          = 0xb
+	F_GET_FILE_RW_HINT                = 0x40d
+	F_GET_RW_HINT                     = 0x40b
+	F_GET_SEALS                       = 0x40a
+	F_LOCK                            = 0x1
+	F_NOTIFY                          = 0x402
+	F_OFD_GETLK                       = 0x24
+	F_OFD_SETLK                       = 0x25
+	F_OFD_SETLKW                      = 0x26
+	F_OK                              = 0x0
+	F_RDLCK                           = 0x0
+	F_SEAL_FUTURE_WRITE               = 0x10
+	F_SEAL_GROW                       = 0x4
+	F_SEAL_SEAL                       = 0x1
+	F_SEAL_SHRINK                     = 0x2
+	F_SEAL_WRITE                      = 0x8
+	F_SETFD                           = 0x2
+	F_SETFL                           = 0x4
+	F_SETLEASE                        = 0x400
+	F_SETLK                           = 0x6
+	F_SETLK64                         = 0x6
+	F_SETLKW                          = 0x7
+	F_SETLKW64                        = 0x7
+	F_SETOWN                          = 0x8
+	F_SETOWN_EX                       = 0xf
+	F_SETPIPE_SZ                      = 0x407
+	F_SETSIG                          = 0xa
+	F_SET_FILE_RW_HINT                = 0x40e
+	F_SET_RW_HINT                     = 0x40c
+	F_SHLCK                           = 0x8
+	F_TEST                            = 0x3
+	F_TLOCK                           = 0x2
+	F_ULOCK                           = 0x0
+	F_UNLCK                           = 0x2
+	F_WRLCK                           = 0x1
+	IN_ACCESS                         = 0x1
+	IN_ALL_EVENTS                     = 0xfff
+	IN_ATTRIB                         = 0x4
+	IN_CLASSA_HOST                    = 0xffffff
+	IN_CLASSA_MAX                     = 0x80
+	IN_CLASSA_NET                     = 0xff000000
+	IN_CLASSA_NSHIFT                  = 0x18
+	IN_CLASSB_HOST                    = 0xffff
+	IN_CLASSB_MAX                     = 0x10000
+	IN_CLASSB_NET                     = 0xffff0000
+	IN_CLASSB_NSHIFT                  = 0x10
+	IN_CLASSC_HOST                    = 0xff
+	IN_CLASSC_NET                     = 0xffffff00
+	IN_CLASSC_NSHIFT                  = 0x8
+	IN_CLOEXEC                        = 0x80000
+	IN_CLOSE                          = 0x18
+	IN_CLOSE_NOWRITE                  = 0x10
+	IN_CLOSE_WRITE                    = 0x8
+	IN_CREATE                         = 0x100
+	IN_DELETE                         = 0x200
+	IN_DELETE_SELF                    = 0x400
+	IN_DONT_FOLLOW                    = 0x2000000
+	IN_EXCL_UNLINK                    = 0x4000000
+	IN_IGNORED                        = 0x8000
+	IN_ISDIR                          = 0x40000000
+	IN_LOOPBACKNET                    = 0x7f
+	IN_MASK_ADD                       = 0x20000000
+	IN_MASK_CREATE                    = 0x10000000
+	IN_MODIFY                         = 0x2
+	IN_MOVE                           = 0xc0
+	IN_MOVED_FROM                     = 0x40
+	IN_MOVED_TO                       = 0x80
+	IN_MOVE_SELF                      = 0x800
+	IN_NONBLOCK                       = 0x800
+	IN_ONESHOT                        = 0x80000000
+	IN_ONLYDIR                        = 0x1000000
+	IN_OPEN                           = 0x20
+	IN_Q_OVERFLOW                     = 0x4000
+	IN_UNMOUNT                        = 0x2000
+	IPPROTO_AH                        = 0x33
+	IPPROTO_BEETPH                    = 0x5e
+	IPPROTO_COMP                      = 0x6c
+	IPPROTO_DCCP                      = 0x21
+	IPPROTO_DSTOPTS                   = 0x3c
+	IPPROTO_EGP                       = 0x8
+	IPPROTO_ENCAP                     = 0x62
+	IPPROTO_ESP                       = 0x32
+	IPPROTO_ETHERNET                  = 0x8f
+	IPPROTO_FRAGMENT                  = 0x2c
+	IPPROTO_GRE                       = 0x2f
+	IPPROTO_HOPOPTS                   = 0x0
+	IPPROTO_ICMP                      = 0x1
+	IPPROTO_ICMPV6                    = 0x3a
+	IPPROTO_IDP                       = 0x16
+	IPPROTO_IGMP                      = 0x2
+	IPPROTO_IP                        = 0x0
+	IPPROTO_IPIP                      = 0x4
+	IPPROTO_IPV6                      = 0x29
+	IPPROTO_MH                        = 0x87
+	IPPROTO_MPLS                      = 0x89
+	IPPROTO_MPTCP                     = 0x106
+	IPPROTO_MTP                       = 0x5c
+	IPPROTO_NONE                      = 0x3b
+	IPPROTO_PIM                       = 0x67
+	IPPROTO_PUP                       = 0xc
+	IPPROTO_RAW                       = 0xff
+	IPPROTO_ROUTING                   = 0x2b
+	IPPROTO_RSVP                      = 0x2e
+	IPPROTO_SCTP                      = 0x84
+	IPPROTO_TCP                       = 0x6
+	IPPROTO_TP                        = 0x1d
+	IPPROTO_UDP                       = 0x11
+	IPPROTO_UDPLITE                   = 0x88
+	IPV6_2292DSTOPTS                  = 0x4
+	IPV6_2292HOPLIMIT                 = 0x8
+	IPV6_2292HOPOPTS                  = 0x3
+	IPV6_2292PKTINFO                  = 0x2
+	IPV6_2292PKTOPTIONS               = 0x6
+	IPV6_2292RTHDR                    = 0x5
+	IPV6_ADDRFORM                     = 0x1
+	IPV6_ADDR_PREFERENCES             = 0x48
+	IPV6_ADD_MEMBERSHIP               = 0x14
+	IPV6_AUTHHDR                      = 0xa
+	IPV6_AUTOFLOWLABEL                = 0x46
+	IPV6_CHECKSUM                     = 0x7
+	IPV6_DONTFRAG                     = 0x3e
+	IPV6_DROP_MEMBERSHIP              = 0x15
+	IPV6_DSTOPTS                      = 0x3b
+	IPV6_FREEBIND                     = 0x4e
+	IPV6_HDRINCL                      = 0x24
+	IPV6_HOPLIMIT                     = 0x34
+	IPV6_HOPOPTS                      = 0x36
+	IPV6_IPSEC_POLICY                 = 0x22
+	IPV6_JOIN_ANYCAST                 = 0x1b
+	IPV6_JOIN_GROUP                   = 0x14
+	IPV6_LEAVE_ANYCAST                = 0x1c
+	IPV6_LEAVE_GROUP                  = 0x15
+	IPV6_MINHOPCOUNT                  = 0x49
+	IPV6_MTU                          = 0x18
+	IPV6_MTU_DISCOVER                 = 0x17
+	IPV6_MULTICAST_ALL                = 0x1d
+	IPV6_MULTICAST_HOPS               = 0x12
+	IPV6_MULTICAST_IF                 = 0x11
+	IPV6_MULTICAST_LOOP               = 0x13
+	IPV6_NEXTHOP                      = 0x9
+	IPV6_ORIGDSTADDR                  = 0x4a
+	IPV6_PATHMTU                      = 0x3d
+	IPV6_PKTINFO                      = 0x32
+	IPV6_PMTUDISC_DO                  = 0x2
+	IPV6_PMTUDISC_DONT                = 0x0
+	IPV6_PMTUDISC_INTERFACE           = 0x4
+	IPV6_PMTUDISC_OMIT                = 0x5
+	IPV6_PMTUDISC_PROBE               = 0x3
+	IPV6_PMTUDISC_WANT                = 0x1
+	IPV6_RECVDSTOPTS                  = 0x3a
+	IPV6_RECVERR                      = 0x19
+	IPV6_RECVERR_RFC4884              = 0x1f
+	IPV6_RECVFRAGSIZE                 = 0x4d
+	IPV6_RECVHOPLIMIT                 = 0x33
+	IPV6_RECVHOPOPTS                  = 0x35
+	IPV6_RECVORIGDSTADDR              = 0x4a
+	IPV6_RECVPATHMTU                  = 0x3c
+	IPV6_RECVPKTINFO                  = 0x31
+	IPV6_RECVRTHDR                    = 0x38
+	IPV6_RECVTCLASS                   = 0x42
+	IPV6_ROUTER_ALERT                 = 0x16
+	IPV6_ROUTER_ALERT_ISOLATE         = 0x1e
+	IPV6_RTHDR                        = 0x39
+	IPV6_RTHDRDSTOPTS                 = 0x37
+	IPV6_RTHDR_LOOSE                  = 0x0
+	IPV6_RTHDR_STRICT                 = 0x1
+	IPV6_RTHDR_TYPE_0                 = 0x0
+	IPV6_RXDSTOPTS                    = 0x3b
+	IPV6_RXHOPOPTS                    = 0x36
+	IPV6_TCLASS                       = 0x43
+	IPV6_TRANSPARENT                  = 0x4b
+	IPV6_UNICAST_HOPS                 = 0x10
+	IPV6_UNICAST_IF                   = 0x4c
+	IPV6_V6ONLY                       = 0x1a
+	IPV6_XFRM_POLICY                  = 0x23
+	IP_ADD_MEMBERSHIP                 = 0x23
+	IP_ADD_SOURCE_MEMBERSHIP          = 0x27
+	IP_BIND_ADDRESS_NO_PORT           = 0x18
+	IP_BLOCK_SOURCE                   = 0x26
+	IP_CHECKSUM                       = 0x17
+	IP_DEFAULT_MULTICAST_LOOP         = 0x1
+	IP_DEFAULT_MULTICAST_TTL          = 0x1
+	IP_DROP_MEMBERSHIP                = 0x24
+	IP_DROP_SOURCE_MEMBERSHIP         = 0x28
+	IP_FREEBIND                       = 0xf
+	IP_HDRINCL                        = 0x3
+	IP_IPSEC_POLICY                   = 0x10
+	IP_MAX_MEMBERSHIPS                = 0x14
+	IP_MINTTL                         = 0x15
+	IP_MSFILTER                       = 0x29
+	IP_MTU                            = 0xe
+	IP_MTU_DISCOVER                   = 0xa
+	IP_MULTICAST_ALL                  = 0x31
+	IP_MULTICAST_IF                   = 0x20
+	IP_MULTICAST_LOOP                 = 0x22
+	IP_MULTICAST_TTL                  = 0x21
+	IP_NODEFRAG                       = 0x16
+	IP_OPTIONS                        = 0x4
+	IP_ORIGDSTADDR                    = 0x14
+	IP_PASSSEC                        = 0x12
+	IP_PKTINFO                        = 0x8
+	IP_PKTOPTIONS                     = 0x9
+	IP_PMTUDISC                       = 0xa
+	IP_PMTUDISC_DO                    = 0x2
+	IP_PMTUDISC_DONT                  = 0x0
+	IP_PMTUDISC_INTERFACE             = 0x4
+	IP_PMTUDISC_OMIT                  = 0x5
+	IP_PMTUDISC_PROBE                 = 0x3
+	IP_PMTUDISC_WANT                  = 0x1
+	IP_RECVERR                        = 0xb
+	IP_RECVERR_RFC4884                = 0x1a
+	IP_RECVFRAGSIZE                   = 0x19
+	IP_RECVOPTS                       = 0x6
+	IP_RECVORIGDSTADDR                = 0x14
+	IP_RECVRETOPTS                    = 0x7
+	IP_RECVTOS                        = 0xd
+	IP_RECVTTL                        = 0xc
+	IP_RETOPTS                        = 0x7
+	IP_ROUTER_ALERT                   = 0x5
+	IP_TOS                            = 0x1
+	IP_TRANSPARENT                    = 0x13
+	IP_TTL                            = 0x2
+	IP_UNBLOCK_SOURCE                 = 0x25
+	IP_UNICAST_IF                     = 0x32
+	IP_XFRM_POLICY                    = 0x11
+	LOCK_EX                           = 0x2
+	LOCK_MAND                         = 0x20
+	LOCK_NB                           = 0x4
+	LOCK_READ                         = 0x40
+	LOCK_RW                           = 0xc0
+	LOCK_SH                           = 0x1
+	LOCK_UN                           = 0x8
+	LOCK_WRITE                        = 0x80
+	MADV_COLD                         = 0x14
+	MADV_DODUMP                       = 0x11
+	MADV_DOFORK                       = 0xb
+	MADV_DONTDUMP                     = 0x10
+	MADV_DONTFORK                     = 0xa
+	MADV_DONTNEED                     = 0x4
+	MADV_DONTNEED_LOCKED              = 0x18
+	MADV_FREE                         = 0x8
+	MADV_HUGEPAGE                     = 0xe
+	MADV_HWPOISON                     = 0x64
+	MADV_KEEPONFORK                   = 0x13
+	MADV_MERGEABLE                    = 0xc
+	MADV_NOHUGEPAGE                   = 0xf
+	MADV_NORMAL                       = 0x0
+	MADV_PAGEOUT                      = 0x15
+	MADV_POPULATE_READ                = 0x16
+	MADV_POPULATE_WRITE               = 0x17
+	MADV_RANDOM                       = 0x1
+	MADV_REMOVE                       = 0x9
+	MADV_SEQUENTIAL                   = 0x2
+	MADV_UNMERGEABLE                  = 0xd
+	MADV_WILLNEED                     = 0x3
+	MADV_WIPEONFORK                   = 0x12
+	MAP_32BIT                         = 0x40
+	MAP_ANON                          = 0x20
+	MAP_ANONYMOUS                     = 0x20
+	MAP_DENYWRITE                     = 0x800
+	MAP_EXECUTABLE                    = 0x1000
+	MAP_FILE                          = 0x0
+	MAP_FIXED                         = 0x10
+	MAP_FIXED_NOREPLACE               = 0x100000
+	MAP_GROWSDOWN                     = 0x100
+	MAP_HUGETLB                       = 0x40000
+	MAP_HUGE_MASK                     = 0x3f
+	MAP_HUGE_SHIFT                    = 0x1a
+	MAP_LOCKED                        = 0x2000
+	MAP_NONBLOCK                      = 0x10000
+	MAP_NORESERVE                     = 0x4000
+	MAP_POPULATE                      = 0x8000
+	MAP_PRIVATE                       = 0x2
+	MAP_SHARED                        = 0x1
+	MAP_SHARED_VALIDATE               = 0x3
+	MAP_STACK                         = 0x20000
+	MAP_SYNC                          = 0x80000
+	MAP_TYPE                          = 0xf
+	MCL_CURRENT                       = 0x1
+	MCL_FUTURE                        = 0x2
+	MCL_ONFAULT                       = 0x4
+	MSG_BATCH                         = 0x40000
+	MSG_CMSG_CLOEXEC                  = 0x40000000
+	MSG_CONFIRM                       = 0x800
+	MSG_CTRUNC                        = 0x8
+	MSG_DONTROUTE                     = 0x4
+	MSG_DONTWAIT                      = 0x40
+	MSG_EOR                           = 0x80
+	MSG_ERRQUEUE                      = 0x2000
+	MSG_FASTOPEN                      = 0x20000000
+	MSG_FIN                           = 0x200
+	MSG_MORE                          = 0x8000
+	MSG_NOSIGNAL                      = 0x4000
+	MSG_OOB                           = 0x1
+	MSG_PEEK                          = 0x2
+	MSG_PROXY                         = 0x10
+	MSG_RST                           = 0x1000
+	MSG_SYN                           = 0x400
+	MSG_TRUNC                         = 0x20
+	MSG_TRYHARD                       = 0x4
+	MSG_WAITALL                       = 0x100
+	MSG_WAITFORONE                    = 0x10000
+	MSG_ZEROCOPY                      = 0x4000000
+	MS_ACTIVE                         = 0x40000000
+	MS_ASYNC                          = 0x1
+	MS_BIND                           = 0x1000
+	MS_BORN                           = 0x20000000
+	MS_DIRSYNC                        = 0x80
+	MS_INVALIDATE                     = 0x2
+	MS_I_VERSION                      = 0x800000
+	MS_KERNMOUNT                      = 0x400000
+	MS_LAZYTIME                       = 0x2000000
+	MS_MANDLOCK                       = 0x40
+	MS_MGC_MSK                        = 0xffff0000
+	MS_MGC_VAL                        = 0xc0ed0000
+	MS_MOVE                           = 0x2000
+	MS_NOATIME                        = 0x400
+	MS_NODEV                          = 0x4
+	MS_NODIRATIME                     = 0x800
+	MS_NOEXEC                         = 0x8
+	MS_NOREMOTELOCK                   = 0x8000000
+	MS_NOSEC                          = 0x10000000
+	MS_NOSUID                         = 0x2
+	MS_NOSYMFOLLOW                    = 0x100
+	MS_NOUSER                         = -0x80000000
+	MS_POSIXACL                       = 0x10000
+	MS_PRIVATE                        = 0x40000
+	MS_RDONLY                         = 0x1
+	MS_REC                            = 0x4000
+	MS_RELATIME                       = 0x200000
+	MS_REMOUNT                        = 0x20
+	MS_RMT_MASK                       = 0x2800051
+	MS_SHARED                         = 0x100000
+	MS_SILENT                         = 0x8000
+	MS_SLAVE                          = 0x80000
+	MS_STRICTATIME                    = 0x1000000
+	MS_SUBMOUNT                       = 0x4000000
+	MS_SYNC                           = 0x4
+	MS_SYNCHRONOUS                    = 0x10
+	MS_UNBINDABLE                     = 0x20000
+	MS_VERBOSE                        = 0x8000
+	O_ACCMODE                         = 0x3
+	O_APPEND                          = 0x400
+	O_ASYNC                           = 0x2000
+	O_CLOEXEC                         = 0x80000
+	O_CREAT                           = 0x40
+	O_DIRECT                          = 0x4000
+	O_DIRECTORY                       = 0x10000
+	O_DSYNC                           = 0x1000
+	O_EXCL                            = 0x80
+	O_FSYNC                           = 0x101000
+	O_LARGEFILE                       = 0x0
+	O_NDELAY                          = 0x800
+	O_NOATIME                         = 0x40000
+	O_NOCTTY                          = 0x100
+	O_NOFOLLOW                        = 0x20000
+	O_NONBLOCK                        = 0x800
+	O_PATH                            = 0x200000
+	O_RDONLY                          = 0x0
+	O_RDWR                            = 0x2
+	O_RSYNC                           = 0x101000
+	O_SYNC                            = 0x101000
+	O_TMPFILE                         = 0x410000
+	O_TRUNC                           = 0x200
+	O_WRONLY                          = 0x1
+	PRIO_MAX                          = 0x14
+	PRIO_MIN                          = -0x14
+	PRIO_PGRP                         = 0x1
+	PRIO_PROCESS                      = 0x0
+	PRIO_USER                         = 0x2
+	PROT_EXEC                         = 0x4
+	PROT_GROWSDOWN                    = 0x1000000
+	PROT_GROWSUP                      = 0x2000000
+	PROT_NONE                         = 0x0
+	PROT_READ                         = 0x1
+	PROT_WRITE                        = 0x2
+	PR_CAPBSET_DROP                   = 0x18
+	PR_CAPBSET_READ                   = 0x17
+	PR_CAP_AMBIENT                    = 0x2f
+	PR_CAP_AMBIENT_CLEAR_ALL          = 0x4
+	PR_CAP_AMBIENT_IS_SET             = 0x1
+	PR_CAP_AMBIENT_LOWER              = 0x3
+	PR_CAP_AMBIENT_RAISE              = 0x2
+	PR_ENDIAN_BIG                     = 0x0
+	PR_ENDIAN_LITTLE                  = 0x1
+	PR_ENDIAN_PPC_LITTLE              = 0x2
+	PR_FPEMU_NOPRINT                  = 0x1
+	PR_FPEMU_SIGFPE                   = 0x2
+	PR_FP_EXC_ASYNC                   = 0x2
+	PR_FP_EXC_DISABLED                = 0x0
+	PR_FP_EXC_DIV                     = 0x10000
+	PR_FP_EXC_INV                     = 0x100000
+	PR_FP_EXC_NONRECOV                = 0x1
+	PR_FP_EXC_OVF                     = 0x20000
+	PR_FP_EXC_PRECISE                 = 0x3
+	PR_FP_EXC_RES                     = 0x80000
+	PR_FP_EXC_SW_ENABLE               = 0x80
+	PR_FP_EXC_UND                     = 0x40000
+	PR_FP_MODE_FR                     = 0x1
+	PR_FP_MODE_FRE                    = 0x2
+	PR_GET_CHILD_SUBREAPER            = 0x25
+	PR_GET_DUMPABLE                   = 0x3
+	PR_GET_ENDIAN                     = 0x13
+	PR_GET_FPEMU                      = 0x9
+	PR_GET_FPEXC                      = 0xb
+	PR_GET_FP_MODE                    = 0x2e
+	PR_GET_IO_FLUSHER                 = 0x3a
+	PR_GET_KEEPCAPS                   = 0x7
+	PR_GET_NAME                       = 0x10
+	PR_GET_NO_NEW_PRIVS               = 0x27
+	PR_GET_PDEATHSIG                  = 0x2
+	PR_GET_SECCOMP                    = 0x15
+	PR_GET_SECUREBITS                 = 0x1b
+	PR_GET_SPECULATION_CTRL           = 0x34
+	PR_GET_TAGGED_ADDR_CTRL           = 0x38
+	PR_GET_THP_DISABLE                = 0x2a
+	PR_GET_TID_ADDRESS                = 0x28
+	PR_GET_TIMERSLACK                 = 0x1e
+	PR_GET_TIMING                     = 0xd
+	PR_GET_TSC                        = 0x19
+	PR_GET_UNALIGN                    = 0x5
+	PR_MCE_KILL                       = 0x21
+	PR_MCE_KILL_CLEAR                 = 0x0
+	PR_MCE_KILL_DEFAULT               = 0x2
+	PR_MCE_KILL_EARLY                 = 0x1
+	PR_MCE_KILL_GET                   = 0x22
+	PR_MCE_KILL_LATE                  = 0x0
+	PR_MCE_KILL_SET                   = 0x1
+	PR_MPX_DISABLE_MANAGEMENT         = 0x2c
+	PR_MPX_ENABLE_MANAGEMENT          = 0x2b
+	PR_MTE_TAG_MASK                   = 0x7fff8
+	PR_MTE_TAG_SHIFT                  = 0x3
+	PR_MTE_TCF_ASYNC                  = 0x4
+	PR_MTE_TCF_MASK                   = 0x6
+	PR_MTE_TCF_NONE                   = 0x0
+	PR_MTE_TCF_SHIFT                  = 0x1
+	PR_MTE_TCF_SYNC                   = 0x2
+	PR_PAC_APDAKEY                    = 0x4
+	PR_PAC_APDBKEY                    = 0x8
+	PR_PAC_APGAKEY                    = 0x10
+	PR_PAC_APIAKEY                    = 0x1
+	PR_PAC_APIBKEY                    = 0x2
+	PR_PAC_GET_ENABLED_KEYS           = 0x3d
+	PR_PAC_RESET_KEYS                 = 0x36
+	PR_PAC_SET_ENABLED_KEYS           = 0x3c
+	PR_SCHED_CORE                     = 0x3e
+	PR_SCHED_CORE_CREATE              = 0x1
+	PR_SCHED_CORE_GET                 = 0x0
+	PR_SCHED_CORE_MAX                 = 0x4
+	PR_SCHED_CORE_SCOPE_PROCESS_GROUP = 0x2
+	PR_SCHED_CORE_SCOPE_THREAD        = 0x0
+	PR_SCHED_CORE_SCOPE_THREAD_GROUP  = 0x1
+	PR_SCHED_CORE_SHARE_FROM          = 0x3
+	PR_SCHED_CORE_SHARE_TO            = 0x2
+	PR_SET_CHILD_SUBREAPER            = 0x24
+	PR_SET_DUMPABLE                   = 0x4
+	PR_SET_ENDIAN                     = 0x14
+	PR_SET_FPEMU                      = 0xa
+	PR_SET_FPEXC                      = 0xc
+	PR_SET_FP_MODE                    = 0x2d
+	PR_SET_IO_FLUSHER                 = 0x39
+	PR_SET_KEEPCAPS                   = 0x8
+	PR_SET_MM                         = 0x23
+	PR_SET_MM_ARG_END                 = 0x9
+	PR_SET_MM_ARG_START               = 0x8
+	PR_SET_MM_AUXV                    = 0xc
+	PR_SET_MM_BRK                     = 0x7
+	PR_SET_MM_END_CODE                = 0x2
+	PR_SET_MM_END_DATA                = 0x4
+	PR_SET_MM_ENV_END                 = 0xb
+	PR_SET_MM_ENV_START               = 0xa
+	PR_SET_MM_EXE_FILE                = 0xd
+	PR_SET_MM_MAP                     = 0xe
+	PR_SET_MM_MAP_SIZE                = 0xf
+	PR_SET_MM_START_BRK               = 0x6
+	PR_SET_MM_START_CODE              = 0x1
+	PR_SET_MM_START_DATA              = 0x3
+	PR_SET_MM_START_STACK             = 0x5
+	PR_SET_NAME                       = 0xf
+	PR_SET_NO_NEW_PRIVS               = 0x26
+	PR_SET_PDEATHSIG                  = 0x1
+	PR_SET_PTRACER                    = 0x59616d61
+	PR_SET_PTRACER_ANY                = -0x1
+	PR_SET_SECCOMP                    = 0x16
+	PR_SET_SECUREBITS                 = 0x1c
+	PR_SET_SPECULATION_CTRL           = 0x35
+	PR_SET_SYSCALL_USER_DISPATCH      = 0x3b
+	PR_SET_TAGGED_ADDR_CTRL           = 0x37
+	PR_SET_THP_DISABLE                = 0x29
+	PR_SET_TIMERSLACK                 = 0x1d
+	PR_SET_TIMING                     = 0xe
+	PR_SET_TSC                        = 0x1a
+	PR_SET_UNALIGN                    = 0x6
+	PR_SET_VMA                        = 0x53564d41
+	PR_SET_VMA_ANON_NAME              = 0x0
+	PR_SME_GET_VL                     = 0x40
+	PR_SME_SET_VL                     = 0x3f
+	PR_SME_SET_VL_ONEXEC              = 0x40000
+	PR_SME_VL_INHERIT                 = 0x20000
+	PR_SME_VL_LEN_MASK                = 0xffff
+	PR_SPEC_DISABLE                   = 0x4
+	PR_SPEC_DISABLE_NOEXEC            = 0x10
+	PR_SPEC_ENABLE                    = 0x2
+	PR_SPEC_FORCE_DISABLE             = 0x8
+	PR_SPEC_INDIRECT_BRANCH           = 0x1
+	PR_SPEC_L1D_FLUSH                 = 0x2
+	PR_SPEC_NOT_AFFECTED              = 0x0
+	PR_SPEC_PRCTL                     = 0x1
+	PR_SPEC_STORE_BYPASS              = 0x0
+	PR_SVE_GET_VL                     = 0x33
+	PR_SVE_SET_VL                     = 0x32
+	PR_SVE_SET_VL_ONEXEC              = 0x40000
+	PR_SVE_VL_INHERIT                 = 0x20000
+	PR_SVE_VL_LEN_MASK                = 0xffff
+	PR_SYS_DISPATCH_OFF               = 0x0
+	PR_SYS_DISPATCH_ON                = 0x1
+	PR_TAGGED_ADDR_ENABLE             = 0x1
+	PR_TASK_PERF_EVENTS_DISABLE       = 0x1f
+	PR_TASK_PERF_EVENTS_ENABLE        = 0x20
+	PR_TIMING_STATISTICAL             = 0x0
+	PR_TIMING_TIMESTAMP               = 0x1
+	PR_TSC_ENABLE                     = 0x1
+	PR_TSC_SIGSEGV                    = 0x2
+	PR_UNALIGN_NOPRINT                = 0x1
+	PR_UNALIGN_SIGBUS                 = 0x2
+	RLIMIT_AS                         = 0x9
+	RLIMIT_CORE                       = 0x4
+	RLIMIT_CPU                        = 0x0
+	RLIMIT_DATA                       = 0x2
+	RLIMIT_FSIZE                      = 0x1
+	RLIMIT_LOCKS                      = 0xa
+	RLIMIT_MEMLOCK                    = 0x8
+	RLIMIT_MSGQUEUE                   = 0xc
+	RLIMIT_NICE                       = 0xd
+	RLIMIT_NLIMITS                    = 0x10
+	RLIMIT_NOFILE                     = 0x7
+	RLIMIT_NPROC                      = 0x6
+	RLIMIT_OFILE                      = 0x7
+	RLIMIT_RSS                        = 0x5
+	RLIMIT_RTPRIO                     = 0xe
+	RLIMIT_RTTIME                     = 0xf
+	RLIMIT_SIGPENDING                 = 0xb
+	RLIMIT_STACK                      = 0x3
+	RUSAGE_CHILDREN                   = -0x1
+	RUSAGE_LWP                        = 0x1
+	RUSAGE_SELF                       = 0x0
+	RUSAGE_THREAD                     = 0x1
+	SCM_CREDENTIALS                   = 0x2
+	SCM_RIGHTS                        = 0x1
+	SCM_TIMESTAMP                     = 0x1d
+	SCM_TIMESTAMPING                  = 0x25
+	SCM_TIMESTAMPING_OPT_STATS        = 0x36
+	SCM_TIMESTAMPING_PKTINFO          = 0x3a
+	SCM_TIMESTAMPNS                   = 0x23
+	SCM_TXTIME                        = 0x3d
+	SCM_WIFI_STATUS                   = 0x29
+	SHUT_RD                           = 0x0
+	SHUT_RDWR                         = 0x2
+	SHUT_WR                           = 0x1
+	SOCK_CLOEXEC                      = 0x80000
+	SOCK_DCCP                         = 0x6
+	SOCK_DGRAM                        = 0x2
+	SOCK_NONBLOCK                     = 0x800
+	SOCK_PACKET                       = 0xa
+	SOCK_RAW                          = 0x3
+	SOCK_RDM                          = 0x4
+	SOCK_SEQPACKET                    = 0x5
+	SOCK_STREAM                       = 0x1
+	SOL_AAL                           = 0x109
+	SOL_ALG                           = 0x117
+	SOL_ATM                           = 0x108
+	SOL_BLUETOOTH                     = 0x112
+	SOL_CAIF                          = 0x116
+	SOL_DCCP                          = 0x10d
+	SOL_DECNET                        = 0x105
+	SOL_ICMPV6                        = 0x3a
+	SOL_IP                            = 0x0
+	SOL_IPV6                          = 0x29
+	SOL_IRDA                          = 0x10a
+	SOL_IUCV                          = 0x115
+	SOL_KCM                           = 0x119
+	SOL_LLC                           = 0x10c
+	SOL_MCTP                          = 0x11d
+	SOL_MPTCP                         = 0x11c
+	SOL_NETBEUI                       = 0x10b
+	SOL_NETLINK                       = 0x10e
+	SOL_NFC                           = 0x118
+	SOL_PACKET                        = 0x107
+	SOL_PNPIPE                        = 0x113
+	SOL_PPPOL2TP                      = 0x111
+	SOL_RAW                           = 0xff
+	SOL_RDS                           = 0x114
+	SOL_RXRPC                         = 0x110
+	SOL_SMC                           = 0x11e
+	SOL_SOCKET                        = 0x1
+	SOL_TCP                           = 0x6
+	SOL_TIPC                          = 0x10f
+	SOL_TLS                           = 0x11a
+	SOL_X25                           = 0x106
+	SOL_XDP                           = 0x11b
+	SOMAXCONN                         = 0x1000
+	SO_ACCEPTCONN                     = 0x1e
+	SO_ATTACH_BPF                     = 0x32
+	SO_ATTACH_FILTER                  = 0x1a
+	SO_ATTACH_REUSEPORT_CBPF          = 0x33
+	SO_ATTACH_REUSEPORT_EBPF          = 0x34
+	SO_BINDTODEVICE                   = 0x19
+	SO_BINDTOIFINDEX                  = 0x3e
+	SO_BPF_EXTENSIONS                 = 0x30
+	SO_BROADCAST                      = 0x6
+	SO_BSDCOMPAT                      = 0xe
+	SO_BUF_LOCK                       = 0x48
+	SO_BUSY_POLL                      = 0x2e
+	SO_BUSY_POLL_BUDGET               = 0x46
+	SO_CNX_ADVICE                     = 0x35
+	SO_COOKIE                         = 0x39
+	SO_DEBUG                          = 0x1
+	SO_DETACH_BPF                     = 0x1b
+	SO_DETACH_FILTER                  = 0x1b
+	SO_DETACH_REUSEPORT_BPF           = 0x44
+	SO_DOMAIN                         = 0x27
+	SO_DONTROUTE                      = 0x5
+	SO_ERROR                          = 0x4
+	SO_GET_FILTER                     = 0x1a
+	SO_INCOMING_CPU                   = 0x31
+	SO_INCOMING_NAPI_ID               = 0x38
+	SO_KEEPALIVE                      = 0x9
+	SO_LINGER                         = 0xd
+	SO_LOCK_FILTER                    = 0x2c
+	SO_MARK                           = 0x24
+	SO_MAX_PACING_RATE                = 0x2f
+	SO_MEMINFO                        = 0x37
+	SO_NETNS_COOKIE                   = 0x47
+	SO_NOFCS                          = 0x2b
+	SO_NO_CHECK                       = 0xb
+	SO_OOBINLINE                      = 0xa
+	SO_PASSCRED                       = 0x10
+	SO_PASSSEC                        = 0x22
+	SO_PEEK_OFF                       = 0x2a
+	SO_PEERCRED                       = 0x11
+	SO_PEERGROUPS                     = 0x3b
+	SO_PEERNAME                       = 0x1c
+	SO_PEERSEC                        = 0x1f
+	SO_PREFER_BUSY_POLL               = 0x45
+	SO_PRIORITY                       = 0xc
+	SO_PROTOCOL                       = 0x26
+	SO_RCVBUF                         = 0x8
+	SO_RCVBUFFORCE                    = 0x21
+	SO_RCVLOWAT                       = 0x12
+	SO_RCVMARK                        = 0x4b
+	SO_RCVTIMEO                       = 0x14
+	SO_RCVTIMEO_NEW                   = 0x42
+	SO_RCVTIMEO_OLD                   = 0x14
+	SO_RESERVE_MEM                    = 0x49
+	SO_REUSEADDR                      = 0x2
+	SO_REUSEPORT                      = 0xf
+	SO_RXQ_OVFL                       = 0x28
+	SO_SECURITY_AUTHENTICATION        = 0x16
+	SO_SECURITY_ENCRYPTION_NETWORK    = 0x18
+	SO_SECURITY_ENCRYPTION_TRANSPORT  = 0x17
+	SO_SELECT_ERR_QUEUE               = 0x2d
+	SO_SNDBUF                         = 0x7
+	SO_SNDBUFFORCE                    = 0x20
+	SO_SNDLOWAT                       = 0x13
+	SO_SNDTIMEO                       = 0x15
+	SO_SNDTIMEO_NEW                   = 0x43
+	SO_SNDTIMEO_OLD                   = 0x15
+	SO_TIMESTAMP                      = 0x1d
+	SO_TIMESTAMPING                   = 0x25
+	SO_TIMESTAMPING_NEW               = 0x41
+	SO_TIMESTAMPING_OLD               = 0x25
+	SO_TIMESTAMPNS                    = 0x23
+	SO_TIMESTAMPNS_NEW                = 0x40
+	SO_TIMESTAMPNS_OLD                = 0x23
+	SO_TIMESTAMP_NEW                  = 0x3f
+	SO_TIMESTAMP_OLD                  = 0x1d
+	SO_TXREHASH                       = 0x4a
+	SO_TXTIME                         = 0x3d
+	SO_TYPE                           = 0x3
+	SO_WIFI_STATUS                    = 0x29
+	SO_ZEROCOPY                       = 0x3c
+	SPLICE_F_GIFT                     = 0x8
+	SPLICE_F_MORE                     = 0x4
+	SPLICE_F_MOVE                     = 0x1
+	SPLICE_F_NONBLOCK                 = 0x2
+	S_BLKSIZE                         = 0x200
+	S_IEXEC                           = 0x40
+	S_IFBLK                           = 0x6000
+	S_IFCHR                           = 0x2000
+	S_IFDIR                           = 0x4000
+	S_IFIFO                           = 0x1000
+	S_IFLNK                           = 0xa000
+	S_IFMT                            = 0xf000
+	S_IFREG                           = 0x8000
+	S_IFSOCK                          = 0xc000
+	S_IREAD                           = 0x100
+	S_IRGRP                           = 0x20
+	S_IROTH                           = 0x4
+	S_IRUSR                           = 0x100
+	S_IRWXG                           = 0x38
+	S_IRWXO                           = 0x7
+	S_IRWXU                           = 0x1c0
+	S_ISGID                           = 0x400
+	S_ISUID                           = 0x800
+	S_ISVTX                           = 0x200
+	S_IWGRP                           = 0x10
+	S_IWOTH                           = 0x2
+	S_IWRITE                          = 0x80
+	S_IWUSR                           = 0x80
+	S_IXGRP                           = 0x8
+	S_IXOTH                           = 0x1
+	S_IXUSR                           = 0x40
+	TCP_CC_INFO                       = 0x1a
+	TCP_CM_INQ                        = 0x24
+	TCP_CONGESTION                    = 0xd
+	TCP_COOKIE_IN_ALWAYS              = 0x1
+	TCP_COOKIE_MAX                    = 0x10
+	TCP_COOKIE_MIN                    = 0x8
+	TCP_COOKIE_OUT_NEVER              = 0x2
+	TCP_COOKIE_PAIR_SIZE              = 0x20
+	TCP_COOKIE_TRANSACTIONS           = 0xf
+	TCP_CORK                          = 0x3
+	TCP_DEFER_ACCEPT                  = 0x9
+	TCP_FASTOPEN                      = 0x17
+	TCP_FASTOPEN_CONNECT              = 0x1e
+	TCP_FASTOPEN_KEY                  = 0x21
+	TCP_FASTOPEN_NO_COOKIE            = 0x22
+	TCP_INFO                          = 0xb
+	TCP_INQ                           = 0x24
+	TCP_KEEPCNT                       = 0x6
+	TCP_KEEPIDLE                      = 0x4
+	TCP_KEEPINTVL                     = 0x5
+	TCP_LINGER2                       = 0x8
+	TCP_MAXSEG                        = 0x2
+	TCP_MAXWIN                        = 0xffff
+	TCP_MAX_WINSHIFT                  = 0xe
+	TCP_MD5SIG                        = 0xe
+	TCP_MD5SIG_EXT                    = 0x20
+	TCP_MD5SIG_FLAG_PREFIX            = 0x1
+	TCP_MD5SIG_MAXKEYLEN              = 0x50
+	TCP_MSS                           = 0x200
+	TCP_MSS_DEFAULT                   = 0x218
+	TCP_MSS_DESIRED                   = 0x4c4
+	TCP_NODELAY                       = 0x1
+	TCP_NOTSENT_LOWAT                 = 0x19
+	TCP_QUEUE_SEQ                     = 0x15
+	TCP_QUICKACK                      = 0xc
+	TCP_REPAIR                        = 0x13
+	TCP_REPAIR_OFF                    = 0x0
+	TCP_REPAIR_OFF_NO_WP              = -0x1
+	TCP_REPAIR_ON                     = 0x1
+	TCP_REPAIR_OPTIONS                = 0x16
+	TCP_REPAIR_QUEUE                  = 0x14
+	TCP_REPAIR_WINDOW                 = 0x1d
+	TCP_SAVED_SYN                     = 0x1c
+	TCP_SAVE_SYN                      = 0x1b
+	TCP_SYNCNT                        = 0x7
+	TCP_S_DATA_IN                     = 0x4
+	TCP_S_DATA_OUT                    = 0x8
+	TCP_THIN_DUPACK                   = 0x11
+	TCP_THIN_LINEAR_TIMEOUTS          = 0x10
+	TCP_TIMESTAMP                     = 0x18
+	TCP_TX_DELAY                      = 0x25
+	TCP_ULP                           = 0x1f
+	TCP_USER_TIMEOUT                  = 0x12
+	TCP_WINDOW_CLAMP                  = 0xa
+	TCP_ZEROCOPY_RECEIVE              = 0x23
+	TIOCCBRK                          = 0x5428
+	TIOCCONS                          = 0x541d
+	TIOCEXCL                          = 0x540c
+	TIOCGDEV                          = 0x80045432
+	TIOCGETD                          = 0x5424
+	TIOCGEXCL                         = 0x80045440
+	TIOCGICOUNT                       = 0x545d
+	TIOCGLCKTRMIOS                    = 0x5456
+	TIOCGPGRP                         = 0x540f
+	TIOCGPKT                          = 0x80045438
+	TIOCGPTLCK                        = 0x80045439
+	TIOCGPTN                          = 0x80045430
+	TIOCGPTPEER                       = 0x5441
+	TIOCGRS485                        = 0x542e
+	TIOCGSERIAL                       = 0x541e
+	TIOCGSID                          = 0x5429
+	TIOCGSOFTCAR                      = 0x5419
+	TIOCGWINSZ                        = 0x5413
+	TIOCINQ                           = 0x541b
+	TIOCLINUX                         = 0x541c
+	TIOCMBIC                          = 0x5417
+	TIOCMBIS                          = 0x5416
+	TIOCMGET                          = 0x5415
+	TIOCMIWAIT                        = 0x545c
+	TIOCMSET                          = 0x5418
+	TIOCM_CAR                         = 0x40
+	TIOCM_CD                          = 0x40
+	TIOCM_CTS                         = 0x20
+	TIOCM_DSR                         = 0x100
+	TIOCM_DTR                         = 0x2
+	TIOCM_LE                          = 0x1
+	TIOCM_RI                          = 0x80
+	TIOCM_RNG                         = 0x80
+	TIOCM_RTS                         = 0x4
+	TIOCM_SR                          = 0x10
+	TIOCM_ST                          = 0x8
+	TIOCNOTTY                         = 0x5422
+	TIOCNXCL                          = 0x540d
+	TIOCOUTQ                          = 0x5411
+	TIOCPKT                           = 0x5420
+	TIOCPKT_DATA                      = 0x0
+	TIOCPKT_DOSTOP                    = 0x20
+	TIOCPKT_FLUSHREAD                 = 0x1
+	TIOCPKT_FLUSHWRITE                = 0x2
+	TIOCPKT_IOCTL                     = 0x40
+	TIOCPKT_NOSTOP                    = 0x10
+	TIOCPKT_START                     = 0x8
+	TIOCPKT_STOP                      = 0x4
+	TIOCSBRK                          = 0x5427
+	TIOCSCTTY                         = 0x540e
+	TIOCSERCONFIG                     = 0x5453
+	TIOCSERGETLSR                     = 0x5459
+	TIOCSERGETMULTI                   = 0x545a
+	TIOCSERGSTRUCT                    = 0x5458
+	TIOCSERGWILD                      = 0x5454
+	TIOCSERSETMULTI                   = 0x545b
+	TIOCSERSWILD                      = 0x5455
+	TIOCSER_TEMT                      = 0x1
+	TIOCSETD                          = 0x5423
+	TIOCSIG                           = 0x40045436
+	TIOCSLCKTRMIOS                    = 0x5457
+	TIOCSPGRP                         = 0x5410
+	TIOCSPTLCK                        = 0x40045431
+	TIOCSRS485                        = 0x542f
+	TIOCSSERIAL                       = 0x541f
+	TIOCSSOFTCAR                      = 0x541a
+	TIOCSTI                           = 0x5412
+	TIOCSWINSZ                        = 0x5414
+	TIOCVHANGUP                       = 0x5437
+	WCONTINUED                        = 0x8
+	WEXITED                           = 0x4
+	WNOHANG                           = 0x1
+	WNOWAIT                           = 0x1000000
+	WSTOPPED                          = 0x2
+	WUNTRACED                         = 0x2
+)
+
+// 错误
+const (
+	E2BIG           = Errno(0x7)
+	EACCES          = Errno(0xd)
+	EADDRINUSE      = Errno(0x62)
+	EADDRNOTAVAIL   = Errno(0x63)
+	EADV            = Errno(0x44)
+	EAFNOSUPPORT    = Errno(0x61)
+	EAGAIN          = Errno(0xb)
+	EALREADY        = Errno(0x72)
+	EBADE           = Errno(0x34)
+	EBADF           = Errno(0x9)
+	EBADFD          = Errno(0x4d)
+	EBADMSG         = Errno(0x4a)
+	EBADR           = Errno(0x35)
+	EBADRQC         = Errno(0x38)
+	EBADSLT         = Errno(0x39)
+	EBFONT          = Errno(0x3b)
+	EBUSY           = Errno(0x10)
+	ECANCELED       = Errno(0x7d)
+	ECHILD          = Errno(0xa)
+	ECHRNG          = Errno(0x2c)
+	ECOMM           = Errno(0x46)
+	ECONNABORTED    = Errno(0x67)
+	ECONNREFUSED    = Errno(0x6f)
+	ECONNRESET      = Errno(0x68)
+	EDEADLK         = Errno(0x23)
+	EDEADLOCK       = Errno(0x23)
+	EDESTADDRREQ    = Errno(0x59)
+	EDOM            = Errno(0x21)
+	EDOTDOT         = Errno(0x49)
+	EDQUOT          = Errno(0x7a)
+	EEXIST          = Errno(0x11)
+	EFAULT          = Errno(0xe)
+	EFBIG           = Errno(0x1b)
+	EHOSTDOWN       = Errno(0x70)
+	EHOSTUNREACH    = Errno(0x71)
+	EHWPOISON       = Errno(0x85)
+	EIDRM           = Errno(0x2b)
+	EILSEQ          = Errno(0x54)
+	EINPROGRESS     = Errno(0x73)
+	EINTR           = Errno(0x4)
+	EINVAL          = Errno(0x16)
+	EIO             = Errno(0x5)
+	EISCONN         = Errno(0x6a)
+	EISDIR          = Errno(0x15)
+	EISNAM          = Errno(0x78)
+	EKEYEXPIRED     = Errno(0x7f)
+	EKEYREJECTED    = Errno(0x81)
+	EKEYREVOKED     = Errno(0x80)
+	EL2HLT          = Errno(0x33)
+	EL2NSYNC        = Errno(0x2d)
+	EL3HLT          = Errno(0x2e)
+	EL3RST          = Errno(0x2f)
+	ELIBACC         = Errno(0x4f)
+	ELIBBAD         = Errno(0x50)
+	ELIBEXEC        = Errno(0x53)
+	ELIBMAX         = Errno(0x52)
+	ELIBSCN         = Errno(0x51)
+	ELNRNG          = Errno(0x30)
+	ELOOP           = Errno(0x28)
+	EMEDIUMTYPE     = Errno(0x7c)
+	EMFILE          = Errno(0x18)
+	EMLINK          = Errno(0x1f)
+	EMSGSIZE        = Errno(0x5a)
+	EMULTIHOP       = Errno(0x48)
+	ENAMETOOLONG    = Errno(0x24)
+	ENAVAIL         = Errno(0x77)
+	ENETDOWN        = Errno(0x64)
+	ENETRESET       = Errno(0x66)
+	ENETUNREACH     = Errno(0x65)
+	ENFILE          = Errno(0x17)
+	ENOANO          = Errno(0x37)
+	ENOBUFS         = Errno(0x69)
+	ENOCSI          = Errno(0x32)
+	ENODATA         = Errno(0x3d)
+	ENODEV          = Errno(0x13)
+	ENOENT          = Errno(0x2)
+	ENOEXEC         = Errno(0x8)
+	ENOKEY          = Errno(0x7e)
+	ENOLCK          = Errno(0x25)
+	ENOLINK         = Errno(0x43)
+	ENOMEDIUM       = Errno(0x7b)
+	ENOMEM          = Errno(0xc)
+	ENOMSG          = Errno(0x2a)
+	ENONET          = Errno(0x40)
+	ENOPKG          = Errno(0x41)
+	ENOPROTOOPT     = Errno(0x5c)
+	ENOSPC          = Errno(0x1c)
+	ENOSR           = Errno(0x3f)
+	ENOSTR          = Errno(0x3c)
+	ENOSYS          = Errno(0x26)
+	ENOTBLK         = Errno(0xf)
+	ENOTCONN        = Errno(0x6b)
+	ENOTDIR         = Errno(0x14)
+	ENOTEMPTY       = Errno(0x27)
+	ENOTNAM         = Errno(0x76)
+	ENOTRECOVERABLE = Errno(0x83)
+	ENOTSOCK        = Errno(0x58)
+	ENOTSUP         = Errno(0x5f)
+	ENOTTY          = Errno(0x19)
+	ENOTUNIQ        = Errno(0x4c)
+	ENXIO           = Errno(0x6)
+	EOPNOTSUPP      = Errno(0x5f)
+	EOVERFLOW       = Errno(0x4b)
+	EOWNERDEAD      = Errno(0x82)
+	EPERM           = Errno(0x1)
+	EPFNOSUPPORT    = Errno(0x60)
+	EPIPE           = Errno(0x20)
+	EPROTO          = Errno(0x47)
+	EPROTONOSUPPORT = Errno(0x5d)
+	EPROTOTYPE      = Errno(0x5b)
+	ERANGE          = Errno(0x22)
+	EREMCHG         = Errno(0x4e)
+	EREMOTE         = Errno(0x42)
+	EREMOTEIO       = Errno(0x79)
+	ERESTART        = Errno(0x55)
+	ERFKILL         = Errno(0x84)
+	EROFS           = Errno(0x1e)
+	ESHUTDOWN       = Errno(0x6c)
+	ESOCKTNOSUPPORT = Errno(0x5e)
+	ESPIPE          = Errno(0x1d)
+	ESRCH           = Errno(0x3)
+	ESRMNT          = Errno(0x45)
+	ESTALE          = Errno(0x74)
+	ESTRPIPE        = Errno(0x56)
+	ETIME           = Errno(0x3e)
+	ETIMEDOUT       = Errno(0x6e)
+	ETOOMANYREFS    = Errno(0x6d)
+	ETXTBSY         = Errno(0x1a)
+	EUCLEAN         = Errno(0x75)
+	EUNATCH         = Errno(0x31)
+	EUSERS          = Errno(0x57)
+	EWOULDBLOCK     = Errno(0xb)
+	EXDEV           = Errno(0x12)
+	EXFULL          = Errno(0x36)
+)
+
+// 信号
+const (
+	SIGABRT   = Signal(0x6)
+	SIGALRM   = Signal(0xe)
+	SIGBUS    = Signal(0x7)
+	SIGCHLD   = Signal(0x11)
+	SIGCLD    = Signal(0x11)
+	SIGCONT   = Signal(0x12)
+	SIGFPE    = Signal(0x8)
+	SIGHUP    = Signal(0x1)
+	SIGILL    = Signal(0x4)
+	SIGINT    = Signal(0x2)
+	SIGIO     = Signal(0x1d)
+	SIGIOT    = Signal(0x6)
+	SIGKILL   = Signal(0x9)
+	SIGPIPE   = Signal(0xd)
+	SIGPOLL   = Signal(0x1d)
+	SIGPROF   = Signal(0x1b)
+	SIGPWR    = Signal(0x1e)
+	SIGQUIT   = Signal(0x3)
+	SIGSEGV   = Signal(0xb)
+	SIGSTKFLT = Signal(0x10)
+	SIGSTOP   = Signal(0x13)
+	SIGSYS    = Signal(0x1f)
+	SIGTERM   = Signal(0xf)
+	SIGTRAP   = Signal(0x5)
+	SIGTSTP   = Signal(0x14)
+	SIGTTIN   = Signal(0x15)
+	SIGTTOU   = Signal(0x16)
+	SIGURG    = Signal(0x17)
+	SIGUSR1   = Signal(0xa)
+	SIGUSR2   = Signal(0xc)
+	SIGVTALRM = Signal(0x1a)
+	SIGWINCH  = Signal(0x1c)
+	SIGXCPU   = Signal(0x18)
+	SIGXFSZ   = Signal(0x19)
+)
+
+// 错误表
+var errors = [...]string{
+	1:   "operation not permitted",
+	2:   "no such file or directory",
+	3:   "no such process",
+	4:   "interrupted system call",
+	5:   "input/output error",
+	6:   "no such device or address",
+	7:   "argument list too long",
+	8:   "exec format error",
+	9:   "bad file descriptor",
+	10:  "no child processes",
+	11:  "resource temporarily unavailable",
+	12:  "cannot allocate memory",
+	13:  "permission denied",
+	14:  "bad address",
+	15:  "block device required",
+	16:  "device or resource busy",
+	17:  "file exists",
+	18:  "invalid cross-device link",
+	19:  "no such device",
+	20:  "not a directory",
+	21:  "is a directory",
+	22:  "invalid argument",
+	23:  "too many open files in system",
+	24:  "too many open files",
+	25:  "inappropriate ioctl for device",
+	26:  "text file busy",
+	27:  "file too large",
+	28:  "no space left on device",
+	29:  "illegal seek",
+	30:  "read-only file system",
+	31:  "too many links",
+	32:  "broken pipe",
+	33:  "numerical argument out of domain",
+	34:  "numerical result out of range",
+	35:  "resource deadlock avoided",
+	36:  "file name too long",
+	37:  "no locks available",
+	38:  "function not implemented",
+	39:  "directory not empty",
+	40:  "too many levels of symbolic links",
+	42:  "no message of desired type",
+	43:  "identifier removed",
+	44:  "channel number out of range",
+	45:  "level 2 not synchronized",
+	46:  "level 3 halted",
+	47:  "level 3 reset",
+	48:  "link number out of range",
+	49:  "protocol driver not attached",
+	50:  "no CSI structure available",
+	51:  "level 2 halted",
+	52:  "invalid exchange",
+	53:  "invalid request descriptor",
+	54:  "exchange full",
+	55:  "no anode",
+	56:  "invalid request code",
+	57:  "invalid slot",
+	59:  "bad font file format",
+	60:  "device not a stream",
+	61:  "no data available",
+	62:  "timer expired",
+	63:  "out of streams resources",
+	64:  "machine is not on the network",
+	65:  "package not installed",
+	66:  "object is remote",
+	67:  "link has been severed",
+	68:  "advertise error",
+	69:  "srmount error",
+	70:  "communication error on send",
+	71:  "protocol error",
+	72:  "multihop attempted",
+	73:  "RFS specific error",
+	74:  "bad message",
+	75:  "value too large for defined data type",
+	76:  "name not unique on network",
+	77:  "file descriptor in bad state",
+	78:  "remote address changed",
+	79:  "can not access a needed shared library",
+	80:  "accessing a corrupted shared library",
+	81:  ".lib section in a.out corrupted",
+	82:  "attempting to link in too many shared libraries",
+	83:  "cannot exec a shared library directly",
+	84:  "invalid or incomplete multibyte or wide character",
+	85:  "interrupted system call should be restarted",
+	86:  "streams pipe error",
+	87:  "too many users",
+	88:  "socket operation on non-socket",
+	89:  "destination address required",
+	90:  "message too long",
+	91:  "protocol wrong type for socket",
+	92:  "protocol not available",
+	93:  "protocol not supported",
+	94:  "socket type not supported",
+	95:  "operation not supported",
+	96:  "protocol family not supported",
+	97:  "address family not supported by protocol",
+	98:  "address already in use",
+	99:  "cannot assign requested address",
+	100: "network is down",
+	101: "network is unreachable",
+	102: "network dropped connection on reset",
+	103: "software caused connection abort",
+	104: "connection reset by peer",
+	105: "no buffer space available",
+	106: "transport endpoint is already connected",
+	107: "transport endpoint is not connected",
+	108: "cannot send after transport endpoint shutdown",
+	109: "too many references: cannot splice",
+	110: "connection timed out",
+	111: "connection refused",
+	112: "host is down",
+	113: "no route to host",
+	114: "operation already in progress",
+	115: "operation now in progress",
+	116: "stale file handle",
+	117: "structure needs cleaning",
+	118: "not a XENIX named type file",
+	119: "no XENIX semaphores available",
+	120: "is a named type file",
+	121: "remote I/O error",
+	122: "disk quota exceeded",
+	123: "no medium found",
+	124: "wrong medium type",
+	125: "operation canceled",
+	126: "required key not available",
+	127: "key has expired",
+	128: "key has been revoked",
+	129: "key was rejected by service",
+	130: "owner died",
+	131: "state not recoverable",
+	132: "operation not possible due to RF-kill",
+	133: "memory page has hardware error",
+}
+
+// 信号表
+var signals = [...]string{
+	1:  "hangup",
+	2:  "interrupt",
+	3:  "quit",
+	4:  "illegal instruction",
+	5:  "trace/breakpoint trap",
+	6:  "aborted",
+	7:  "bus error",
+	8:  "floating point exception",
+	9:  "killed",
+	10: "user defined signal 1",
+	11: "segmentation fault",
+	12: "user defined signal 2",
+	13: "broken pipe",
+	14: "alarm clock",
+	15: "terminated",
+	16: "stack fault",
+	17: "child exited",
+	18: "continued",
+	19: "stopped (signal)",
+	20: "stopped",
+	21: "stopped (tty input)",
+	22: "stopped (tty output)",
+	23: "urgent I/O condition",
+	24: "CPU time limit exceeded",
+	25: "file size limit exceeded",
+	26: "virtual timer expired",
+	27: "profiling timer expired",
+	28: "window changed",
+	29: "I/O possible",
+	30: "power failure",
+	31: "bad system call",
+}